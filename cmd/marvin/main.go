@@ -13,9 +13,20 @@ import (
 	"fyne.io/fyne/v2/driver/desktop"
 	"github.com/MordFustang21/marvin-go/internal/search"
 	"github.com/MordFustang21/marvin-go/internal/search/providers/calculator"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/colors"
 	"github.com/MordFustang21/marvin-go/internal/search/providers/commands"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/dataformat"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/datetime"
+	desktopapps "github.com/MordFustang21/marvin-go/internal/search/providers/desktop_apps"
 	encodedecode "github.com/MordFustang21/marvin-go/internal/search/providers/encode_decode"
+	filebrowser "github.com/MordFustang21/marvin-go/internal/search/providers/file_browser"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/generate"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/hashing"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/recent"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/regex"
 	"github.com/MordFustang21/marvin-go/internal/search/providers/spotlight"
+	texttransform "github.com/MordFustang21/marvin-go/internal/search/providers/text_transform"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/timezone"
 	"github.com/MordFustang21/marvin-go/internal/search/providers/web"
 	"github.com/MordFustang21/marvin-go/internal/theme"
 	"github.com/MordFustang21/marvin-go/internal/ui"
@@ -128,12 +139,44 @@ func main() {
 func setupSearchProviders(registry *search.Registry) {
 	// Register spotlight provider with highest priority (lowest number)
 	spotlightProvider := spotlight.NewProvider(1, 20) // Priority 1, max 20 results
+	if err := spotlightProvider.LoadAliases(""); err != nil {
+		slog.Error("Failed to load application aliases", slog.Any("error", err))
+	}
 	registry.RegisterProvider(spotlightProvider)
 
+	// Register freedesktop application provider on Linux, where there are no .app bundles
+	if runtime.GOOS == "linux" {
+		desktopAppsProvider := desktopapps.NewProvider(1, 10) // Same priority as spotlight, max 10 results
+		registry.RegisterProvider(desktopAppsProvider)
+	}
+
+	// Register file browser provider for path queries such as ~/src/
+	fileBrowserProvider := filebrowser.NewProvider(0, 50) // Ahead of spotlight while browsing, max 50 entries
+	registry.RegisterProvider(fileBrowserProvider)
+
+	// Register recent items provider, shown when the window opens and for "recent" queries
+	history, err := recent.NewHistory("")
+	if err != nil {
+		slog.Error("Failed to load launch history", slog.Any("error", err))
+	}
+	recentProvider := recent.NewProvider(0, 10, history, spotlightProvider) // Priority 0, max 10 results
+	registry.RegisterProvider(recentProvider)
+
 	// Register calculator provider with medium priority
 	calculatorProvider := calculator.NewProvider(2)
 	registry.RegisterProvider(calculatorProvider)
 
+	// Register date and time provider alongside the calculator
+	dateTimeProvider := datetime.NewProvider(2)
+	registry.RegisterProvider(dateTimeProvider)
+
+	// Register world clock provider, with the team's pinned zones from ~/.config/marvin/timezones.json
+	timeZoneProvider := timezone.NewProvider(2)
+	if err := timeZoneProvider.LoadPinnedZones(""); err != nil {
+		slog.Error("Failed to load pinned time zones", slog.Any("error", err))
+	}
+	registry.RegisterProvider(timeZoneProvider)
+
 	// Register custom commands provider with medium-high priority
 	commandsProvider := commands.NewProvider(3, "")
 	registry.RegisterProvider(commandsProvider)
@@ -149,4 +192,36 @@ func setupSearchProviders(registry *search.Registry) {
 	} else {
 		registry.RegisterProvider(encodeDecodeProvider)
 	}
+
+	// Register JSON, YAML and TOML provider
+	dataFormatProvider, err := dataformat.NewProvider(5)
+	if err != nil {
+		slog.Error("Failed to create data format provider", slog.Any("error", err))
+	} else {
+		registry.RegisterProvider(dataFormatProvider)
+	}
+
+	// Register hashing provider
+	hashingProvider, err := hashing.NewProvider(5)
+	if err != nil {
+		slog.Error("Failed to create hashing provider", slog.Any("error", err))
+	} else {
+		registry.RegisterProvider(hashingProvider)
+	}
+
+	// Register generators provider for UUIDs, passwords and other random values
+	generateProvider := generate.NewProvider(5)
+	registry.RegisterProvider(generateProvider)
+
+	// Register text transform provider for case changes, sorting, wrapping and escaping
+	textTransformProvider := texttransform.NewProvider(5)
+	registry.RegisterProvider(textTransformProvider)
+
+	// Register regex provider for testing patterns against the clipboard
+	regexProvider := regex.NewProvider(5)
+	registry.RegisterProvider(regexProvider)
+
+	// Register color provider for converting colors and checking their contrast
+	colorsProvider := colors.NewProvider(5)
+	registry.RegisterProvider(colorsProvider)
 }
@@ -0,0 +1,233 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	// maxPreviewBytes limits how much of a text file is read for its preview
+	maxPreviewBytes = 64 * 1024
+	// maxPreviewImageBytes limits the size of images that are previewed
+	maxPreviewImageBytes = 20 * 1024 * 1024
+	// maxPreviewEntries limits how many directory entries are listed in a preview
+	maxPreviewEntries = 100
+)
+
+// Preview describes the content shown in the preview pane for a result.
+// Only one of Text, Markdown or Image is normally set.
+type Preview struct {
+	Title    string         // Heading for the preview
+	Text     string         // Plain text or source code
+	Language string         // Language used to highlight Text, e.g. "go" or "json"
+	Markdown string         // Markdown to render
+	Image    fyne.Resource  // Image to display
+	Metadata []PreviewField // Details shown beneath the content
+}
+
+// PreviewField is a labelled piece of metadata shown in a preview
+type PreviewField struct {
+	Label string
+	Value string
+}
+
+// AddMetadata appends a metadata field to the preview if the value is not empty
+func (p *Preview) AddMetadata(label, value string) {
+	if value == "" {
+		return
+	}
+	p.Metadata = append(p.Metadata, PreviewField{Label: label, Value: value})
+}
+
+// SetMetadata replaces the value of an existing metadata field, or adds it if
+// the preview does not have one with that label yet
+func (p *Preview) SetMetadata(label, value string) {
+	if value == "" {
+		return
+	}
+
+	for i, field := range p.Metadata {
+		if field.Label == label {
+			p.Metadata[i].Value = value
+			return
+		}
+	}
+	p.AddMetadata(label, value)
+}
+
+// languageByExtension maps file extensions to the language used for highlighting
+var languageByExtension = map[string]string{
+	".go":    "go",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".m":     "objc",
+	".java":  "java",
+	".kt":    "kotlin",
+	".swift": "swift",
+	".sh":    "shell",
+	".bash":  "shell",
+	".zsh":   "shell",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".sql":   "sql",
+	".html":  "html",
+	".xml":   "xml",
+	".css":   "css",
+}
+
+// NewFilePreview builds a preview of a file or directory on disk: a listing for
+// directories, the image for pictures, rendered markdown, highlighted text for
+// source and text files, and the size and modification date for everything
+func NewFilePreview(path string) (*Preview, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file info: %w", err)
+	}
+
+	preview := &Preview{Title: filepath.Base(path)}
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch {
+	case info.IsDir() && ext != ".app":
+		preview.Text, err = directoryListing(path)
+		preview.AddMetadata("Kind", "Folder")
+	case isImageExtension(ext) && info.Size() <= maxPreviewImageBytes:
+		preview.Image, err = fyne.LoadResourceFromPath(path)
+		preview.AddMetadata("Kind", strings.ToUpper(strings.TrimPrefix(ext, "."))+" image")
+	case !info.IsDir():
+		var text string
+		var isText bool
+		text, isText, err = readTextPrefix(path)
+		if isText {
+			if ext == ".md" || ext == ".markdown" {
+				preview.Markdown = text
+			} else {
+				preview.Text = text
+				preview.Language = languageByExtension[ext]
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		preview.AddMetadata("Size", FormatSize(info.Size()))
+	}
+	preview.AddMetadata("Modified", info.ModTime().Format("Jan 2, 2006 at 3:04 PM"))
+	preview.AddMetadata("Path", path)
+
+	return preview, nil
+}
+
+// FormatSize formats a byte count for display, e.g. 1.5 MB
+func FormatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d bytes", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
+
+// isImageExtension reports whether the extension is an image format that can be previewed
+func isImageExtension(ext string) bool {
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".svg":
+		return true
+	}
+	return false
+}
+
+// readTextPrefix reads the start of a file and reports whether it looks like text
+func readTextPrefix(path string) (string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, maxPreviewBytes)
+	n, err := file.Read(buf)
+	if err != nil && n == 0 {
+		// Empty files are still text
+		return "", true, nil
+	}
+	buf = buf[:n]
+
+	// Files containing NUL bytes are binary
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return "", false, nil
+	}
+
+	// Trim a rune that was cut in half by the size limit
+	for i := 0; i < utf8.UTFMax && len(buf) > 0 && !utf8.Valid(buf); i++ {
+		buf = buf[:len(buf)-1]
+	}
+
+	if !utf8.Valid(buf) {
+		return "", false, nil
+	}
+
+	return string(buf), true, nil
+}
+
+// directoryListing returns the entries of a directory, one per line, with
+// directories marked by a trailing slash
+func directoryListing(path string) (string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > maxPreviewEntries {
+		more := len(names) - maxPreviewEntries
+		names = append(names[:maxPreviewEntries], fmt.Sprintf("… and %d more", more))
+	}
+
+	if len(names) == 0 {
+		return "Empty folder", nil
+	}
+
+	return strings.Join(names, "\n"), nil
+}
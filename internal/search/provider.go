@@ -1,6 +1,8 @@
 package search
 
 import (
+	"context"
+
 	"fyne.io/fyne/v2"
 )
 
@@ -22,32 +24,85 @@ const (
 
 // SearchResult represents a single search result from any provider
 type SearchResult struct {
-	Title       string       // Display title for the result
-	Description string       // Secondary description text
-	Path        string       // Path or identifier (if applicable)
-	Icon        fyne.Resource // Icon to display with the result
-	Type        ProviderType // Type of the provider that generated this result
-	Action      func()       // Function to execute when the result is selected
+	Title       string                   // Display title for the result
+	Description string                   // Secondary description text
+	Path        string                   // Path or identifier (if applicable)
+	Icon        fyne.Resource            // Icon to display with the result
+	Type        ProviderType             // Type of the provider that generated this result
+	Action      func()                   // Function to execute when the result is selected
+	Completion  string                   // Query to replace the search text with when the result is completed with Tab
+	Preview     func() (*Preview, error) // Builds the content for the preview pane, optional
 }
 
 // Provider defines the interface for search providers
 type Provider interface {
 	// Name returns the provider's name
 	Name() string
-	
+
 	// Type returns the provider type
 	Type() ProviderType
-	
+
 	// Priority returns the provider's priority (lower is higher priority)
 	// Results from higher priority providers will be shown first
 	Priority() int
-	
+
 	// CanHandle returns whether the provider can handle the given query
 	CanHandle(query string) bool
-	
+
 	// Search performs a search with the given query and returns results
 	Search(query string) ([]SearchResult, error)
-	
+
 	// Execute triggers an action for the given result, if applicable
 	Execute(result SearchResult) error
-}
\ No newline at end of file
+}
+
+// StreamingProvider is implemented by providers that can deliver results
+// incrementally while a long-running search is still in progress.
+// The registry prefers SearchStream over Search when it is available.
+type StreamingProvider interface {
+	Provider
+
+	// SearchStream performs a search with the given query, sending batches of
+	// results on the channel as they are found. It returns once the search is
+	// complete or ctx is cancelled, and must not send after returning.
+	SearchStream(ctx context.Context, query string, results chan<- []SearchResult) error
+}
+
+// ParentQueryProvider is implemented by providers with hierarchical queries,
+// such as file paths, that let the user step back up a level with Backspace.
+type ParentQueryProvider interface {
+	Provider
+
+	// ParentQuery returns the query for the level above the given query and
+	// whether the query is one the provider can navigate up from.
+	ParentQuery(query string) (string, bool)
+}
+
+// DefaultResultsProvider is implemented by providers that have results to show
+// before anything has been typed, such as recently opened files.
+// The registry calls DefaultResults instead of Search for an empty query.
+type DefaultResultsProvider interface {
+	Provider
+
+	// DefaultResults returns the results to show for an empty query
+	DefaultResults() ([]SearchResult, error)
+}
+
+// VolatileProvider is implemented by providers whose results change every
+// time they are searched, such as random values. The window searches again
+// when it is reopened with such a query rather than showing old results.
+type VolatileProvider interface {
+	Provider
+
+	// Volatile returns whether the results for the query change between searches
+	Volatile(query string) bool
+}
+
+// LaunchRecorder is implemented by providers that keep track of the results
+// the user launches, e.g. to build a launch history.
+type LaunchRecorder interface {
+	Provider
+
+	// RecordLaunch is called after a result from any provider is launched
+	RecordLaunch(result SearchResult)
+}
@@ -1,27 +1,75 @@
 package calculator
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2/theme"
 	"github.com/MordFustang21/marvin-go/internal/search"
 	"github.com/MordFustang21/marvin-go/internal/util"
-	"github.com/mnogu/go-calculator"
 )
 
+// maxDisplayDigits is the longest whole number shown in full, longer ones
+// are shown in exponent notation but still copied in full
+const maxDisplayDigits = 40
+
 // Provider is a search provider that can handle basic calculations
 type Provider struct {
 	priority int
+	// precision is the number of significant digits results are shown with
+	precision int
+	// format holds the separators results are shown with
+	format numberFormat
+
+	// mu guards the session state below
+	mu sync.Mutex
+	// vars holds the variables the user has assigned this session, and ans
+	// once a result has been used
+	vars map[string]number
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithPrecision sets the number of significant digits results are shown
+// and calculated with, up to 50. The default is 15.
+func WithPrecision(digits int) Option {
+	return func(p *Provider) {
+		p.precision = min(max(digits, 1), maxPrecision)
+	}
+}
+
+// WithLocale sets the locale, such as "de_DE", whose thousands and decimal
+// separators results are shown with. The default is the system locale.
+func WithLocale(locale string) Option {
+	return func(p *Provider) {
+		p.format = localeNumberFormat(locale)
+	}
 }
 
 // NewProvider creates a new calculator provider
-func NewProvider(priority int) *Provider {
-	return &Provider{
-		priority: priority,
+func NewProvider(priority int, opts ...Option) *Provider {
+	p := &Provider{
+		priority:  priority,
+		precision: defaultPrecision,
+		vars:      make(map[string]number),
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.format == (numberFormat{}) {
+		p.format = localeNumberFormat(systemLocale())
+	}
+
+	return p
 }
 
 // Name returns the provider's name
@@ -39,86 +87,217 @@ func (p *Provider) Priority() int {
 	return p.priority
 }
 
+// mathExpressionPattern matches queries made only of digits and operators
+var mathExpressionPattern = regexp.MustCompile(`^[\d\s\+\-\*/\(\)\.\^%!]+$`)
+
 // checkIfMathExpression checks if the query is likely a math expression
 func (p *Provider) checkIfMathExpression(query string) bool {
 	// A simple regex to check for common math operators and digits
 	// This will match expressions like "1 + 2", "3.14 * 2", "5 - 3", etc.
-	re := regexp.MustCompile(`^[\d\s\+\-\*/\(\)\.\^]+$`)
-	return re.MatchString(query)
+	return mathExpressionPattern.MatchString(query)
 }
 
+// datePattern matches ISO dates such as 2026-12-25, which are left to the
+// date provider rather than calculated as subtractions
+var datePattern = regexp.MustCompile(`\b\d{4}-\d{1,2}-\d{1,2}\b`)
+
 // CanHandle returns whether the provider can handle the given query
 func (p *Provider) CanHandle(query string) bool {
-	return p.checkIfMathExpression(query)
+	query = strings.TrimSpace(query)
+	if datePattern.MatchString(query) {
+		return false
+	}
+
+	// Conversions between incompatible units are handled so the error can be shown
+	if _, err := parseConversion(query); err != errNotConversion {
+		return true
+	}
+
+	_, err := p.parseAndCalculate(query)
+	return p.shouldShowError(query, err)
+}
+
+// calculation is an evaluated statement
+type calculation struct {
+	*statement
+	value number
+	// bits is the exact result in programmer mode, wrapped to width
+	bits  uint64
+	width intWidth
 }
 
-// parseAndCalculate parses and evaluates a mathematical expression
-// using a recursive descent parser approach for more complex expressions
-func (p *Provider) parseAndCalculate(expr string) (float64, error) {
-	res, err := calculator.Calculate(expr)
+// parseAndCalculate parses and evaluates an expression or variable
+// assignment using the session's variables. A trailing width such as "u8"
+// switches to programmer mode.
+func (p *Provider) parseAndCalculate(query string) (*calculation, error) {
+	expr, width, hasWidth := parseWidth(query)
+
+	stmt, err := parseStatement(expr, hasWidth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	p.mu.Lock()
+	env := &environment{vars: maps.Clone(p.vars), prec: precisionBits(p.precision)}
+	p.mu.Unlock()
+
+	calc := &calculation{statement: stmt, width: width}
+	if stmt.programmer {
+		calc.bits, err = evalInteger(stmt.expr, env, width)
+		if err != nil {
+			return nil, err
+		}
+		calc.value = width.number(calc.bits)
+		return calc, nil
+	}
+
+	calc.value, err = evaluate(stmt.expr, env)
 	if err != nil {
-		return 0, fmt.Errorf("invalid expression: %s", err.Error())
+		return nil, err
+	}
+
+	return calc, nil
+}
+
+// operatorWords are the words that are operators, as in "10 mod 3"
+var operatorWords = map[string]bool{"mod": true, "of": true, "xor": true}
+
+// shouldShowError reports whether a query that failed to calculate is still
+// meant for the calculator, so the error is shown rather than the query
+// being left to other providers. Words that aren't variables, constants,
+// functions or operators mean the query is probably not maths at all, as in
+// "re /pi/" or "s/e/x/".
+func (p *Provider) shouldShowError(query string, err error) bool {
+	if err == nil || p.checkIfMathExpression(query) {
+		return true
+	}
+
+	expr, _, _ := parseWidth(query)
+	tokens, tokenErr := tokenize(expr)
+	if tokenErr != nil || errors.Is(err, errUnknownName) {
+		return false
+	}
+
+	// Without a number or a known name, such as in "c++", it isn't a calculation
+	hasMaths := false
+	for i, tok := range tokens {
+		switch {
+		case tok.kind == tokenNumber:
+			hasMaths = true
+		case tok.kind != tokenIdent || operatorWords[strings.ToLower(tok.text)]:
+		case i == 0 && len(tokens) > 1 && tokens[1].text == "=":
+			// The variable being assigned, as in "x = 3"
+		case p.isKnownName(tok.text):
+			hasMaths = true
+		default:
+			return false
+		}
+	}
+	return hasMaths
+}
+
+// isKnownName reports whether name is a function, constant or session variable
+func (p *Provider) isKnownName(name string) bool {
+	lower := strings.ToLower(name)
+	if _, ok := functions[lower]; ok {
+		return true
+	}
+	if _, ok := constants[lower]; ok {
+		return true
 	}
 
-	return res, nil
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.vars[name]
+	return ok
+}
+
+// setVariable stores a variable for the rest of the session
+func (p *Provider) setVariable(name string, value number) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.vars[name] = value
 }
 
 // Search performs a calculation for the given query and returns results
 func (p *Provider) Search(query string) ([]search.SearchResult, error) {
 	// Remove any spaces to standardize input
 	query = strings.TrimSpace(query)
-
-	// Check if we can calculate it
-	if !p.CanHandle(query) {
+	if datePattern.MatchString(query) {
 		return nil, nil
 	}
 
+	// Unit conversions such as "5 km in miles"
+	if conv, err := parseConversion(query); err != errNotConversion {
+		if err != nil {
+			return []search.SearchResult{p.errorResult(err, "Could not convert the units")}, nil
+		}
+		return p.conversionResults(conv), nil
+	}
+
 	// Calculate the result
-	result, err := p.parseAndCalculate(query)
+	calc, err := p.parseAndCalculate(query)
 	if err != nil {
+		if !p.shouldShowError(query, err) {
+			return nil, nil
+		}
+
 		// Return the error as a result so the user can see it
+		return []search.SearchResult{p.errorResult(err, "Could not calculate the expression")}, nil
+	}
+
+	result := calc.value
+
+	// Format the result, with the locale's separators for display and
+	// without them for copying
+	resultStr := formatNumber(result, p.precision)
+	displayStr := p.display(result)
+	if calc.programmer {
+		resultStr = calc.width.decimal(calc.bits)
+		displayStr = resultStr
+	}
+
+	// Variable assignments are stored when the result is chosen
+	if calc.assign != "" {
+		name := calc.assign
 		return []search.SearchResult{
 			{
-				Title:       fmt.Sprintf("Error: %s", err.Error()),
-				Description: "Could not calculate the expression",
-				Icon:        theme.ErrorIcon(),
+				Title:       fmt.Sprintf("%s = %s", name, displayStr),
+				Description: fmt.Sprintf("Press Enter to set %s for this session", name),
+				Path:        "calculator:assign",
+				Icon:        theme.ContentAddIcon(),
 				Type:        search.TypeCalculator,
 				Action: func() {
-					// Do nothing on error
+					p.setVariable(name, result)
+					p.setVariable("ans", result)
 				},
 			},
 		}, nil
 	}
 
-	// Format the result
-	var resultStr string
-	if result == float64(int(result)) {
-		// If it's a whole number, format without decimal part
-		resultStr = fmt.Sprintf("%d", int(result))
-	} else {
-		// If the decimal part is small, show more precision
-		absResult := math.Abs(result)
-		if absResult < 0.1 {
-			resultStr = fmt.Sprintf("%.6f", result)
-			// Remove trailing zeros
-			resultStr = strings.TrimRight(strings.TrimRight(resultStr, "0"), ".")
-		} else {
-			resultStr = fmt.Sprintf("%.4f", result)
-			// Remove trailing zeros
-			resultStr = strings.TrimRight(strings.TrimRight(resultStr, "0"), ".")
-		}
+	if calc.programmer {
+		return p.programmerResults(query, calc), nil
 	}
 
 	// Create a search result
-	displayText := fmt.Sprintf("%s = %s", query, resultStr)
+	displayText := fmt.Sprintf("%s = %s", query, displayStr)
 
-	return []search.SearchResult{
+	description := "Press Enter to copy result to clipboard"
+	if len(strings.TrimPrefix(resultStr, "-")) > maxDisplayDigits {
+		description = fmt.Sprintf("Press Enter to copy all %d digits", len(strings.TrimPrefix(resultStr, "-")))
+	}
+
+	results := []search.SearchResult{
 		{
 			Title:       displayText,
-			Description: "Press Enter to copy result to clipboard",
+			Description: description,
+			Path:        "calculator:result",
 			Icon:        theme.ContentAddIcon(),
 			Type:        search.TypeCalculator,
 			Action: func() {
+				// Remember the result for ans
+				p.setVariable("ans", result)
+
 				// Copy the result to clipboard
 				if err := util.CopyToClipboard(resultStr); err != nil {
 					fmt.Printf("Failed to copy to clipboard: %v\n", err)
@@ -127,7 +306,156 @@ func (p *Provider) Search(query string) ([]search.SearchResult, error) {
 				}
 			},
 		},
-	}, nil
+	}
+
+	// Fractions such as 1/3 that can't be shown exactly as decimals can
+	// still be copied exactly
+	if fraction := p.fraction(result); fraction != "" {
+		results = append(results, search.SearchResult{
+			Title:       fraction,
+			Description: "Exact fraction. Press Enter to copy",
+			Path:        "calculator:fraction",
+			Icon:        theme.ContentCopyIcon(),
+			Type:        search.TypeCalculator,
+			Action:      copyAction(fraction),
+		})
+	}
+
+	return results, nil
+}
+
+// display formats a result for showing with the locale's separators. Whole
+// numbers too long to read are shown in exponent notation.
+func (p *Provider) display(value number) string {
+	text := formatNumber(value, p.precision)
+	if len(strings.TrimPrefix(text, "-")) > maxDisplayDigits {
+		text = formatBigFloat(value.float(precisionBits(p.precision)), p.precision)
+	}
+	return p.format.localize(text)
+}
+
+// fraction returns an exact result as a fraction such as 1/3, if it can't
+// be shown exactly as a decimal
+func (p *Provider) fraction(value number) string {
+	if !value.isExact() || value.rat.IsInt() {
+		return ""
+	}
+	if places, ok := terminatingPlaces(value.rat); ok && places <= p.precision {
+		return ""
+	}
+
+	fraction := value.rat.String()
+	if len(fraction) > maxDisplayDigits {
+		return ""
+	}
+	return fraction
+}
+
+// programmerResults creates a result for each of decimal, hexadecimal, binary
+// and octal, each copying the value in that base
+func (p *Provider) programmerResults(query string, calc *calculation) []search.SearchResult {
+	bases := []struct {
+		name, path, text, display string
+	}{
+		{name: "Decimal", path: "calculator:result", text: calc.width.decimal(calc.bits)},
+		{name: "Hexadecimal", path: "calculator:hex", text: "0x" + strings.ToUpper(strconv.FormatUint(calc.bits, 16))},
+		{name: "Binary", path: "calculator:bin", text: "0b" + strconv.FormatUint(calc.bits, 2)},
+		{name: "Octal", path: "calculator:oct", text: "0o" + strconv.FormatUint(calc.bits, 8)},
+	}
+	// Group binary digits into nibbles so long values stay readable
+	bases[2].display = "0b" + groupDigits(strconv.FormatUint(calc.bits, 2), 4)
+
+	results := make([]search.SearchResult, 0, len(bases))
+	for i, base := range bases {
+		title := base.display
+		if title == "" {
+			title = base.text
+		}
+		icon := theme.ContentCopyIcon()
+		if i == 0 {
+			title = fmt.Sprintf("%s = %s", query, base.text)
+			icon = theme.ContentAddIcon()
+		}
+
+		text, value := base.text, calc.value
+		copyText := copyAction(text)
+		results = append(results, search.SearchResult{
+			Title:       title,
+			Description: fmt.Sprintf("%s, %s. Press Enter to copy", base.name, calc.width),
+			Path:        base.path,
+			Icon:        icon,
+			Type:        search.TypeCalculator,
+			Action: func() {
+				// Remember the result for ans
+				p.setVariable("ans", value)
+				copyText()
+			},
+		})
+	}
+
+	return results
+}
+
+// conversionResults creates the results for a unit conversion, one copying the
+// value with its unit and one copying the bare value
+func (p *Provider) conversionResults(conv *conversion) []search.SearchResult {
+	value := formatFloat(conv.result())
+	withUnit := conv.to.format(value)
+
+	return []search.SearchResult{
+		{
+			Title:       fmt.Sprintf("%s = %s", conv.input, withUnit),
+			Description: fmt.Sprintf("Press Enter to copy %s", withUnit),
+			Path:        "calculator:conversion",
+			Icon:        theme.ContentAddIcon(),
+			Type:        search.TypeCalculator,
+			Action:      copyAction(withUnit),
+		},
+		{
+			Title:       value,
+			Description: fmt.Sprintf("Copy the %s without units", conv.to.category),
+			Path:        "calculator:conversion-value",
+			Icon:        theme.ContentCopyIcon(),
+			Type:        search.TypeCalculator,
+			Action:      copyAction(value),
+		},
+	}
+}
+
+// errorResult creates a result showing an error so the user can see it
+func (p *Provider) errorResult(err error, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       fmt.Sprintf("Error: %s", err.Error()),
+		Description: description,
+		Path:        "calculator:error",
+		Icon:        theme.ErrorIcon(),
+		Type:        search.TypeCalculator,
+		Action: func() {
+			// Do nothing on error
+		},
+	}
+}
+
+// copyAction returns an action that copies text to the clipboard
+func copyAction(text string) func() {
+	return func() {
+		if err := util.CopyToClipboard(text); err != nil {
+			slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+		}
+	}
+}
+
+// formatFloat formats a number with up to 10 significant digits and no
+// trailing zeros, switching to exponent notation for very large or small values
+func formatFloat(value float64) string {
+	abs := math.Abs(value)
+	if abs != 0 && (abs >= 1e15 || abs < 1e-6) {
+		return strconv.FormatFloat(value, 'g', 10, 64)
+	}
+
+	// Round away floating point noise such as 0.30000000000000004
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'g', 10, 64), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
 }
 
 // Execute triggers an action for the given result
@@ -0,0 +1,55 @@
+package calculator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCanHandle(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"1 + 2", true},
+		{"sqrt(2)", true},
+		{"2 * pi", true},
+		{"10 mod 3", true},
+		{"15% of 80", true},
+		{"0xff xor 0x0f", true},
+		{"x = 3", true},
+		// Incomplete expressions show their error
+		{"1 +", true},
+		{"sqrt(", true},
+		{"2 * pi +", true},
+		{"1 << 4 u8", true},
+		// Queries for other providers are left alone
+		{"re /pi/", false},
+		{"s/e/x/", false},
+		{"re /\\d+/", false},
+		{"sha256 abc", false},
+		{"c++", false},
+		{"hello world", false},
+		{"pi day", false},
+		{"2026-12-25", false},
+	}
+
+	p := NewProvider(0)
+	for _, tt := range tests {
+		if got := p.CanHandle(tt.query); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCanHandleVariables(t *testing.T) {
+	p := NewProvider(0)
+	// Variables are stored when a result is chosen
+	if p.CanHandle("rate *") {
+		t.Error("CanHandle(rate *) = true before rate is set")
+	}
+
+	p.setVariable("rate", exactInt(big.NewInt(3)))
+	if !p.CanHandle("rate *") {
+		t.Error("CanHandle(rate *) = false after rate is set")
+	}
+}
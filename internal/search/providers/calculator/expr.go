@@ -0,0 +1,720 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a lexical token in an expression
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+// token is a lexical token of an expression
+type token struct {
+	kind  tokenKind
+	text  string
+	value number // Value of a number token
+	pos   int
+}
+
+// operatorAliases maps alternative spellings of operators to the canonical one
+var operatorAliases = map[string]string{
+	"**": "^",
+	"×":  "*",
+	"÷":  "/",
+	"−":  "-",
+}
+
+// tokenize splits an expression into tokens
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '0' && i+2 < len(runes) && strings.ContainsRune("xXbBoO", runes[i+1]) && isBaseDigit(runes[i+2]):
+			// Integer literals with a base prefix, such as 0xFF, 0b1010 or 0o17
+			start := i
+			i += 2
+			for i < len(runes) && (isBaseDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			text := string(runes[start:i])
+			num, ok := new(big.Int).SetString(text, 0)
+			if !ok {
+				return nil, fmt.Errorf("invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: exactInt(num), pos: start})
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			// Exponent notation such as 2e10 or 1.5E-3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+
+			text := string(runes[start:i])
+			num, err := parseNumber(text)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: num, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			text := string(r)
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				if alias, ok := operatorAliases[pair]; ok {
+					tokens = append(tokens, token{kind: tokenOperator, text: alias, pos: i})
+					i += 2
+					continue
+				}
+				if pair == "<<" || pair == ">>" {
+					tokens = append(tokens, token{kind: tokenOperator, text: pair, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if alias, ok := operatorAliases[text]; ok {
+				text = alias
+			}
+			if !strings.Contains("+-*/^%!(),=&|~", text) {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, pos: i})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// isBaseDigit reports whether r can be a digit of a hex, binary or octal literal
+func isBaseDigit(r rune) bool {
+	return unicode.IsDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// node is a node of a parsed expression
+type node interface {
+	eval(env *environment) (number, error)
+}
+
+type (
+	numberNode struct {
+		value number
+		text  string // Source text, used to read programmer mode integers
+	}
+	variableNode struct{ name string }
+	unaryNode    struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	// percentNode is a value written as a percentage, e.g. 15%
+	percentNode struct{ operand node }
+	callNode    struct {
+		name string
+		args []node
+	}
+)
+
+// environment holds the variables an expression is evaluated with
+type environment struct {
+	vars map[string]number
+	// prec is the precision in bits of float calculations
+	prec uint
+}
+
+// errUnknownName is returned for names that are neither a variable, constant nor function
+var errUnknownName = errors.New("unknown name")
+
+// constants are the predefined names, as decimal expansions so they can be
+// read with any precision up to maxPrecision
+var constants = map[string]string{
+	"pi":  "3.14159265358979323846264338327950288419716939937510582097494",
+	"π":   "3.14159265358979323846264338327950288419716939937510582097494",
+	"tau": "6.28318530717958647692528676655900576839433879875021164194989",
+	"e":   "2.71828182845904523536028747135266249775724709369995957496697",
+	"phi": "1.61803398874989484820458683436563811772030917980576286213544",
+	"inf": "+Inf",
+}
+
+// function is a built-in function taking a fixed number of arguments, or
+// any number if arity is -1. Functions with big are calculated with full
+// precision, others with float64.
+type function struct {
+	arity int
+	fn    func(args []float64) (float64, error)
+	big   func(args []number, prec uint) (number, error)
+}
+
+// unary wraps a single argument math function
+func unary(fn func(float64) float64) function {
+	return function{arity: 1, fn: func(args []float64) (float64, error) {
+		return fn(args[0]), nil
+	}}
+}
+
+// unaryBig wraps a single argument function on numbers
+func unaryBig(fn func(number) number) function {
+	return function{arity: 1, big: func(args []number, prec uint) (number, error) {
+		return fn(args[0]), nil
+	}}
+}
+
+// extremum returns a function choosing the argument that compares as want
+// against all others, for min and max
+func extremum(name string, want int) function {
+	return function{arity: -1, big: func(args []number, prec uint) (number, error) {
+		if len(args) == 0 {
+			return number{}, fmt.Errorf("%s needs at least one value", name)
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			if cmp(arg, result, prec) == want {
+				result = arg
+			}
+		}
+		return result, nil
+	}}
+}
+
+// functions are the built-in functions. Trigonometric functions use radians.
+var functions = map[string]function{
+	"sqrt": {arity: 1, big: func(args []number, prec uint) (number, error) {
+		return sqrtOf(args[0], prec)
+	}},
+	"cbrt":  unary(math.Cbrt),
+	"abs":   unaryBig(absOf),
+	"exp":   unary(math.Exp),
+	"ln":    unary(math.Log),
+	"log2":  unary(math.Log2),
+	"log10": unary(math.Log10),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"sinh":  unary(math.Sinh),
+	"cosh":  unary(math.Cosh),
+	"tanh":  unary(math.Tanh),
+	"floor": unaryBig(floor),
+	"ceil":  unaryBig(ceil),
+	"round": unaryBig(round),
+	"trunc": unaryBig(trunc),
+	"deg":   unary(func(x float64) float64 { return x * 180 / math.Pi }),
+	"rad":   unary(func(x float64) float64 { return x * math.Pi / 180 }),
+	"log": {arity: -1, fn: func(args []float64) (float64, error) {
+		switch len(args) {
+		case 1:
+			return math.Log10(args[0]), nil
+		case 2:
+			return math.Log(args[0]) / math.Log(args[1]), nil
+		}
+		return 0, fmt.Errorf("log takes a value and an optional base")
+	}},
+	"atan2": {arity: 2, fn: func(args []float64) (float64, error) { return math.Atan2(args[0], args[1]), nil }},
+	"pow":   {arity: 2, big: func(args []number, prec uint) (number, error) { return pow(args[0], args[1], prec) }},
+	"hypot": {arity: 2, fn: func(args []float64) (float64, error) { return math.Hypot(args[0], args[1]), nil }},
+	"min":   extremum("min", -1),
+	"max":   extremum("max", +1),
+}
+
+// evaluate evaluates an expression. Float operations without a result,
+// such as infinity minus infinity, are reported as errNotANumber, and
+// results too large to show as errTooLarge.
+func evaluate(n node, env *environment) (result number, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(big.ErrNaN); !ok {
+				panic(r)
+			}
+			err = errNotANumber
+		}
+	}()
+
+	result, err = n.eval(env)
+	if err != nil {
+		return number{}, err
+	}
+	return result, checkRange(result)
+}
+
+func (n numberNode) eval(env *environment) (number, error) {
+	return n.value, nil
+}
+
+func (n variableNode) eval(env *environment) (number, error) {
+	if value, ok := env.vars[n.name]; ok {
+		return value, nil
+	}
+	if value, ok := constants[strings.ToLower(n.name)]; ok {
+		return parseConstant(value, env.prec), nil
+	}
+	if n.name == "ans" {
+		return number{}, fmt.Errorf("no previous result for ans")
+	}
+	return number{}, fmt.Errorf("%w %q", errUnknownName, n.name)
+}
+
+func (n unaryNode) eval(env *environment) (number, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return number{}, err
+	}
+
+	switch n.op {
+	case "-":
+		return neg(value), nil
+	case "!":
+		return factorialOf(value, env.prec)
+	case "~":
+		return number{}, fmt.Errorf("~ is only available for whole numbers")
+	}
+	return value, nil
+}
+
+func (n percentNode) eval(env *environment) (number, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return number{}, err
+	}
+	return quo(value, exactInt(big.NewInt(100)), env.prec)
+}
+
+func (n binaryNode) eval(env *environment) (number, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return number{}, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return number{}, err
+	}
+
+	// Adding or subtracting a percentage changes the left side by that
+	// proportion, so 80 + 15% is 92
+	if _, ok := n.right.(percentNode); ok && (n.op == "+" || n.op == "-") {
+		right = mul(right, left, env.prec)
+	}
+
+	switch n.op {
+	case "+":
+		return add(left, right, env.prec), nil
+	case "-":
+		return sub(left, right, env.prec), nil
+	case "*":
+		return mul(left, right, env.prec), nil
+	case "/":
+		return quo(left, right, env.prec)
+	case "%":
+		return mod(left, right, env.prec)
+	case "^":
+		return pow(left, right, env.prec)
+	case "&", "|", "xor", "<<", ">>":
+		return number{}, fmt.Errorf("%s is only available for whole numbers", n.op)
+	}
+
+	return number{}, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n callNode) eval(env *environment) (number, error) {
+	fn, ok := functions[strings.ToLower(n.name)]
+	if !ok {
+		return number{}, fmt.Errorf("%w %q", errUnknownName, n.name)
+	}
+
+	if fn.arity >= 0 && len(n.args) != fn.arity {
+		return number{}, fmt.Errorf("%s takes %d argument(s)", n.name, fn.arity)
+	}
+
+	args := make([]number, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return number{}, err
+		}
+		args[i] = value
+	}
+
+	if fn.big != nil {
+		return fn.big(args, env.prec)
+	}
+
+	floats := make([]float64, len(args))
+	for i, arg := range args {
+		floats[i] = arg.float64()
+	}
+	result, err := fn.fn(floats)
+	if err != nil {
+		return number{}, err
+	}
+	return fromFloat64(result, env.prec)
+}
+
+// factorial returns n!, using the gamma function for non-integers
+func factorial(n float64) (float64, error) {
+	if n < 0 && n == math.Trunc(n) {
+		return 0, fmt.Errorf("factorial of a negative integer")
+	}
+	return math.Gamma(n + 1), nil
+}
+
+// parser is a recursive descent parser for expressions
+type parser struct {
+	tokens []token
+	pos    int
+	// programmer makes ^ mean exclusive or rather than a power
+	programmer bool
+}
+
+// statement is a parsed input line, an expression optionally assigned to a variable
+type statement struct {
+	assign string // Variable name for "x = ...", empty otherwise
+	expr   node
+	// programmer is set for integer expressions using base prefixes or bitwise
+	// operators, which are evaluated with evalInteger
+	programmer bool
+}
+
+// parseStatement parses an expression or a variable assignment such as x = 3.
+// Programmer mode is used if requested or if the expression needs it.
+func parseStatement(input string, programmer bool) (*statement, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, programmer: programmer || isProgrammerExpression(tokens)}
+	stmt := &statement{programmer: p.programmer}
+
+	if len(tokens) > 2 && tokens[0].kind == tokenIdent && tokens[1].text == "=" {
+		name := tokens[0].text
+		if _, ok := constants[strings.ToLower(name)]; ok || name == "ans" {
+			return nil, fmt.Errorf("cannot assign to %s", name)
+		}
+		if _, ok := functions[strings.ToLower(name)]; ok {
+			return nil, fmt.Errorf("cannot assign to %s", name)
+		}
+		stmt.assign = name
+		p.pos = 2
+	}
+
+	stmt.expr, err = p.parseBitwiseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isOperator reports whether the next token is one of the operators
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBitwiseOr parses operands joined by |
+func (p *parser) parseBitwiseOr() (node, error) {
+	return p.parseBinaryLevel(p.parseBitwiseXor, "|")
+}
+
+// parseBitwiseXor parses operands joined by xor, or ^ in programmer mode
+func (p *parser) parseBitwiseXor() (node, error) {
+	left, err := p.parseBitwiseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if !(p.programmer && p.isOperator("^")) && !(tok.kind == tokenIdent && strings.EqualFold(tok.text, "xor")) {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseBitwiseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "xor", left: left, right: right}
+	}
+}
+
+// parseBitwiseAnd parses operands joined by &
+func (p *parser) parseBitwiseAnd() (node, error) {
+	return p.parseBinaryLevel(p.parseShift, "&")
+}
+
+// parseShift parses operands joined by << and >>
+func (p *parser) parseShift() (node, error) {
+	return p.parseBinaryLevel(p.parseAdditive, "<<", ">>")
+}
+
+// parseBinaryLevel parses left associative operands from next joined by any of ops
+func (p *parser) parseBinaryLevel(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(ops...) {
+		op := p.next().text
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAdditive parses terms joined by + and -
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseMultiplicative parses factors joined by *, /, % (modulo), mod, "of"
+// as in 15% of 80, or implicit multiplication as in 2pi or 3(4+1)
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+
+		var op string
+		switch {
+		case p.isOperator("*", "/", "%"):
+			op = p.next().text
+		case tok.kind == tokenIdent && strings.EqualFold(tok.text, "mod"):
+			p.next()
+			op = "%"
+		case tok.kind == tokenIdent && strings.EqualFold(tok.text, "of"):
+			p.next()
+			op = "*"
+		case tok.kind == tokenIdent && strings.EqualFold(tok.text, "xor"):
+			return left, nil
+		case tok.kind == tokenIdent || p.isOperator("("):
+			op = "*"
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseUnary parses a leading +, - or ~
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-", "+", "~") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+
+	return p.parsePower()
+}
+
+// parsePower parses right associative exponentiation, so 2^3^2 is 2^9
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isOperator("^") && !p.programmer {
+		p.next()
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: "^", left: base, right: exponent}, nil
+	}
+
+	return base, nil
+}
+
+// parsePostfix parses factorials and percentages. A % followed by an operand
+// is left for parseMultiplicative to read as modulo.
+func (p *parser) parsePostfix() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isOperator("!"):
+			p.next()
+			operand = unaryNode{op: "!", operand: operand}
+		case p.isOperator("%") && !p.startsOperand(p.pos+1):
+			p.next()
+			operand = percentNode{operand: operand}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+// startsOperand reports whether the token at i can start an operand, which
+// makes a preceding % a modulo rather than a percentage
+func (p *parser) startsOperand(i int) bool {
+	tok := p.tokens[i]
+	switch tok.kind {
+	case tokenNumber:
+		return true
+	case tokenIdent:
+		return !strings.EqualFold(tok.text, "of") && !strings.EqualFold(tok.text, "xor")
+	case tokenOperator:
+		return tok.text == "("
+	}
+	return false
+}
+
+// parsePrimary parses numbers, names, function calls and parenthesized expressions
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return numberNode{value: tok.value, text: tok.text}, nil
+
+	case tokenIdent:
+		if !p.isOperator("(") {
+			return variableNode{name: tok.text}, nil
+		}
+
+		if _, ok := functions[strings.ToLower(tok.text)]; !ok {
+			// A variable followed by parentheses is a multiplication, as in x(2+1)
+			return variableNode{name: tok.text}, nil
+		}
+
+		p.next()
+		args := []node{}
+		if !p.isOperator(")") {
+			for {
+				arg, err := p.parseBitwiseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+
+				if !p.isOperator(",") {
+					break
+				}
+				p.next()
+			}
+		}
+
+		if !p.isOperator(")") {
+			return nil, fmt.Errorf("missing ) after arguments to %s", tok.text)
+		}
+		p.next()
+
+		return callNode{name: tok.text, args: args}, nil
+
+	case tokenOperator:
+		if tok.text == "(" {
+			expr, err := p.parseBitwiseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOperator(")") {
+				return nil, fmt.Errorf("missing )")
+			}
+			p.next()
+			return expr, nil
+		}
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+
+	return nil, fmt.Errorf("unexpected end of expression")
+}
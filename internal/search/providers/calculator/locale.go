@@ -0,0 +1,111 @@
+package calculator
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// numberFormat holds the separators a locale writes numbers with
+type numberFormat struct {
+	group   string // Thousands separator, e.g. "," in 1,234.5
+	decimal string // Decimal separator, e.g. "." in 1,234.5
+}
+
+// defaultNumberFormat is used when the locale is unknown
+var defaultNumberFormat = numberFormat{group: ",", decimal: "."}
+
+// systemLocale returns the user's locale, e.g. "de_DE", from the
+// environment or, on macOS, the system preferences
+func systemLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(name); value != "" && value != "C" && value != "POSIX" {
+			return value
+		}
+	}
+
+	// Apps started from the Dock or Finder don't get LANG
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+		if err == nil {
+			return strings.TrimSpace(string(out))
+		}
+	}
+
+	return ""
+}
+
+// localeNumberFormat returns the separators for a locale such as "de_DE.UTF-8",
+// "fr-CA" or "en_US@rg=dezzzz", falling back to defaultNumberFormat
+func localeNumberFormat(locale string) numberFormat {
+	// Strip the encoding and modifiers, which language tags don't have
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil || locale == "" {
+		return defaultNumberFormat
+	}
+
+	// Format a sample number and read the separators back out of it. Seven
+	// digits are used as some locales don't group four digit numbers, and
+	// the last group is used as some group the others in pairs.
+	sample := message.NewPrinter(tag).Sprintf("%.1f", 1234567.8)
+	four, five := strings.IndexRune(sample, '4'), strings.IndexRune(sample, '5')
+	seven := strings.IndexRune(sample, '7')
+	if four < 0 || five < four || seven < five || !strings.HasSuffix(sample, "8") {
+		// Locales with their own digits are written with the default separators
+		return defaultNumberFormat
+	}
+
+	format := numberFormat{
+		group:   sample[four+1 : five],
+		decimal: sample[seven+1 : len(sample)-1],
+	}
+	if format.decimal == "" {
+		return defaultNumberFormat
+	}
+	return format
+}
+
+// localize rewrites a number formatted by formatNumber with the locale's
+// separators, grouping the whole part in thousands
+func (f numberFormat) localize(text string) string {
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	exponent := ""
+	if i := strings.IndexByte(text, 'e'); i >= 0 {
+		text, exponent = text[:i], text[i:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(text, ".")
+
+	// Numbers such as ∞ have nothing to group
+	if whole == "" || whole[0] < '0' || whole[0] > '9' {
+		return sign + text + exponent
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(f.decimal)
+		b.WriteString(frac)
+	}
+	b.WriteString(exponent)
+
+	return b.String()
+}
@@ -0,0 +1,549 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPrecision is the number of significant digits results are shown with
+	defaultPrecision = 15
+	// maxPrecision limits the precision, as the constants are only stored to 60 digits
+	maxPrecision = 50
+	// maxExactBits limits the size of exact powers and factorials, beyond
+	// which they are calculated as floats
+	maxExactBits = 1 << 16
+	// maxLiteralExponent limits the exponent of number literals read exactly,
+	// so 1e999999999 doesn't have to be expanded
+	maxLiteralExponent = 1000
+	// maxFloatExp limits the binary exponent of float results to about
+	// 10^±315652, as formatting numbers takes longer the larger they are
+	maxFloatExp = 1 << 20
+)
+
+var (
+	// errNotANumber is returned for calculations without a result, such as 0/0 or sqrt(-1)
+	errNotANumber = errors.New("result is not a number")
+	// errTooLarge and errTooSmall are returned for results beyond maxFloatExp
+	errTooLarge = errors.New("number too large")
+	errTooSmall = errors.New("number too small")
+)
+
+// number is a calculator value. It is an exact fraction as long as only
+// exact operations are used, and otherwise an arbitrary precision float.
+type number struct {
+	rat *big.Rat   // Exact value, nil if the value is a float
+	flt *big.Float // Approximate value, used when rat is nil
+}
+
+// precisionBits returns the float precision in bits needed for the given
+// number of significant decimal digits, with guard bits for rounding
+func precisionBits(digits int) uint {
+	return uint(math.Ceil(float64(digits)*math.Log2(10))) + 32
+}
+
+// exact returns an exact number
+func exact(r *big.Rat) number {
+	return number{rat: r}
+}
+
+// exactInt returns an exact integer
+func exactInt(i *big.Int) number {
+	return number{rat: new(big.Rat).SetInt(i)}
+}
+
+// fromFloat64 converts the result of a float64 calculation to a number
+func fromFloat64(v float64, prec uint) (number, error) {
+	if math.IsNaN(v) {
+		return number{}, errNotANumber
+	}
+	return number{flt: new(big.Float).SetPrec(prec).SetFloat64(v)}, nil
+}
+
+// parseNumber parses a decimal literal such as 1_000, 2.5 or 1.5e-3,
+// exactly unless its exponent is very large
+func parseNumber(text string) (number, error) {
+	clean := strings.ReplaceAll(text, "_", "")
+
+	if i := strings.IndexAny(clean, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(clean[i+1:])
+		if err != nil || exp > maxLiteralExponent || exp < -maxLiteralExponent {
+			f, _, err := big.ParseFloat(clean, 10, precisionBits(maxPrecision), big.ToNearestEven)
+			if err != nil {
+				return number{}, fmt.Errorf("invalid number %q", text)
+			}
+			return number{flt: f}, nil
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(clean)
+	if !ok {
+		return number{}, fmt.Errorf("invalid number %q", text)
+	}
+	return exact(r), nil
+}
+
+// parseConstant parses the decimal expansion of a constant with the given precision
+func parseConstant(text string, prec uint) number {
+	f, _, err := big.ParseFloat(text, 10, prec, big.ToNearestEven)
+	if err != nil {
+		panic(fmt.Sprintf("invalid constant %q: %v", text, err))
+	}
+	return number{flt: f}
+}
+
+// isExact reports whether the number is an exact fraction
+func (n number) isExact() bool {
+	return n.rat != nil
+}
+
+// sign returns -1, 0 or +1 depending on the sign of n
+func (n number) sign() int {
+	if n.rat != nil {
+		return n.rat.Sign()
+	}
+	return n.flt.Sign()
+}
+
+// checkRange returns an error if the number is a float too large or too
+// small to be shown
+func checkRange(n number) error {
+	if n.flt == nil || n.flt.IsInf() || n.flt.Sign() == 0 {
+		return nil
+	}
+	return checkExp(float64(n.flt.MantExp(nil)))
+}
+
+// checkExp returns an error if a result with the given binary exponent is
+// too large or too small to be shown
+func checkExp(exp float64) error {
+	switch {
+	case exp > maxFloatExp:
+		return errTooLarge
+	case exp < -maxFloatExp:
+		return errTooSmall
+	}
+	return nil
+}
+
+// log2Abs returns the base 2 logarithm of the magnitude of a non-zero float
+func log2Abs(f *big.Float) float64 {
+	mant := new(big.Float)
+	exp := f.MantExp(mant)
+	m, _ := mant.Float64()
+	return float64(exp) + math.Log2(math.Abs(m))
+}
+
+// isInf reports whether the number is an infinity
+func (n number) isInf() bool {
+	return n.flt != nil && n.flt.IsInf()
+}
+
+// float returns the number as a new float with the given precision
+func (n number) float(prec uint) *big.Float {
+	if n.rat != nil {
+		return new(big.Float).SetPrec(prec).SetRat(n.rat)
+	}
+	return new(big.Float).SetPrec(prec).Set(n.flt)
+}
+
+// float64 returns the nearest float64 to the number
+func (n number) float64() float64 {
+	if n.rat != nil {
+		f, _ := n.rat.Float64()
+		return f
+	}
+	f, _ := n.flt.Float64()
+	return f
+}
+
+// integer returns the number as an integer if it is whole
+func (n number) integer() (*big.Int, bool) {
+	if n.rat != nil {
+		if !n.rat.IsInt() {
+			return nil, false
+		}
+		return new(big.Int).Set(n.rat.Num()), true
+	}
+	if n.flt.IsInf() || !n.flt.IsInt() {
+		return nil, false
+	}
+	i, _ := n.flt.Int(nil)
+	return i, true
+}
+
+// toRat returns the exact value of the number, which for a float is the
+// fraction it stores. It is false for infinities.
+func (n number) toRat() (*big.Rat, bool) {
+	if n.rat != nil {
+		return n.rat, true
+	}
+	if n.flt.IsInf() {
+		return nil, false
+	}
+	r, _ := n.flt.Rat(nil)
+	return r, true
+}
+
+// cmp compares two numbers, returning -1, 0 or +1
+func cmp(a, b number, prec uint) int {
+	if a.rat != nil && b.rat != nil {
+		return a.rat.Cmp(b.rat)
+	}
+	return a.float(prec).Cmp(b.float(prec))
+}
+
+// arith applies an operation exactly if both numbers are exact, and as
+// floats otherwise
+func arith(a, b number, prec uint,
+	ratOp func(z, x, y *big.Rat) *big.Rat,
+	floatOp func(z, x, y *big.Float) *big.Float,
+) number {
+	if a.rat != nil && b.rat != nil {
+		return exact(ratOp(new(big.Rat), a.rat, b.rat))
+	}
+	return number{flt: floatOp(new(big.Float).SetPrec(prec), a.float(prec), b.float(prec))}
+}
+
+func add(a, b number, prec uint) number {
+	return arith(a, b, prec, (*big.Rat).Add, (*big.Float).Add)
+}
+
+func sub(a, b number, prec uint) number {
+	return arith(a, b, prec, (*big.Rat).Sub, (*big.Float).Sub)
+}
+
+func mul(a, b number, prec uint) number {
+	return arith(a, b, prec, (*big.Rat).Mul, (*big.Float).Mul)
+}
+
+func quo(a, b number, prec uint) (number, error) {
+	if b.sign() == 0 {
+		return number{}, fmt.Errorf("division by zero")
+	}
+	return arith(a, b, prec, (*big.Rat).Quo, (*big.Float).Quo), nil
+}
+
+func neg(a number) number {
+	if a.rat != nil {
+		return exact(new(big.Rat).Neg(a.rat))
+	}
+	return number{flt: new(big.Float).Neg(a.flt)}
+}
+
+// mod returns the remainder of a / b with the sign of a, like math.Mod
+func mod(a, b number, prec uint) (number, error) {
+	if b.sign() == 0 {
+		return number{}, fmt.Errorf("division by zero")
+	}
+	if a.isInf() {
+		return number{}, errNotANumber
+	}
+	if b.isInf() {
+		return a, nil
+	}
+
+	q, err := quo(a, b, prec)
+	if err != nil {
+		return number{}, err
+	}
+	return sub(a, mul(b, trunc(q), prec), prec), nil
+}
+
+// pow returns a raised to the power b. Integer powers of exact numbers are
+// exact while the result is reasonably small.
+func pow(a, b number, prec uint) (number, error) {
+	e, ok := b.integer()
+	if !ok || a.isInf() {
+		return fromFloat64(math.Pow(a.float64(), b.float64()), prec)
+	}
+
+	if a.sign() == 0 && e.Sign() < 0 {
+		return number{}, fmt.Errorf("division by zero")
+	}
+
+	if a.rat != nil && e.IsInt64() {
+		bits := int64(a.rat.Num().BitLen() + a.rat.Denom().BitLen())
+		if n := abs64(e.Int64()); n <= maxExactBits && bits*n <= maxExactBits {
+			exp := big.NewInt(n)
+			r := new(big.Rat).SetFrac(
+				new(big.Int).Exp(a.rat.Num(), exp, nil),
+				new(big.Int).Exp(a.rat.Denom(), exp, nil),
+			)
+			if e.Sign() < 0 {
+				r.Inv(r)
+			}
+			return exact(r), nil
+		}
+	}
+
+	if !e.IsInt64() {
+		return fromFloat64(math.Pow(a.float64(), b.float64()), prec)
+	}
+
+	// Check the size of the result before calculating it, so 9^9^9 fails
+	// quickly rather than building a number too large to show
+	base := a.float(prec)
+	if base.Sign() != 0 {
+		if err := checkExp(float64(e.Int64()) * log2Abs(base)); err != nil {
+			return number{}, err
+		}
+	}
+
+	// Exponentiation by squaring
+	n := abs64(e.Int64())
+	result := new(big.Float).SetPrec(prec).SetInt64(1)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+	if e.Sign() < 0 {
+		result.Quo(new(big.Float).SetPrec(prec).SetInt64(1), result)
+	}
+	return number{flt: result}, nil
+}
+
+// factorialOf returns n!, exactly for whole numbers, as a float for whole
+// numbers too large to keep exactly, and using the gamma function otherwise
+func factorialOf(n number, prec uint) (number, error) {
+	i, ok := n.integer()
+	if !ok {
+		v, err := factorial(n.float64())
+		if err != nil {
+			return number{}, err
+		}
+		return fromFloat64(v, prec)
+	}
+
+	if i.Sign() < 0 {
+		return number{}, fmt.Errorf("factorial of a negative integer")
+	}
+	if !i.IsInt64() {
+		return number{}, errTooLarge
+	}
+
+	// log2(n!) < n*log2(n), so this keeps exact factorials to maxExactBits
+	if i.Int64()*int64(i.BitLen()) <= maxExactBits {
+		return exactInt(new(big.Int).MulRange(1, i.Int64())), nil
+	}
+
+	lgamma, _ := math.Lgamma(float64(i.Int64()) + 1)
+	if err := checkExp(lgamma / math.Ln2); err != nil {
+		return number{}, err
+	}
+
+	result := new(big.Float).SetPrec(prec).SetInt64(1)
+	factor := new(big.Float).SetPrec(prec)
+	for k := int64(2); k <= i.Int64(); k++ {
+		result.Mul(result, factor.SetInt64(k))
+	}
+	return number{flt: result}, nil
+}
+
+// sqrtOf returns the square root of n, exactly if n is the square of a fraction
+func sqrtOf(n number, prec uint) (number, error) {
+	if n.sign() < 0 {
+		return number{}, errNotANumber
+	}
+
+	if n.rat != nil {
+		num, den := new(big.Int).Sqrt(n.rat.Num()), new(big.Int).Sqrt(n.rat.Denom())
+		r := new(big.Rat).SetFrac(num, den)
+		if new(big.Rat).Mul(r, r).Cmp(n.rat) == 0 {
+			return exact(r), nil
+		}
+	}
+
+	f := n.float(prec)
+	if f.IsInf() {
+		return number{flt: f}, nil
+	}
+	return number{flt: new(big.Float).SetPrec(prec).Sqrt(f)}, nil
+}
+
+// trunc rounds n towards zero to a whole number
+func trunc(n number) number {
+	r, ok := n.toRat()
+	if !ok {
+		return n
+	}
+	return exactInt(new(big.Int).Quo(r.Num(), r.Denom()))
+}
+
+// floor rounds n down to a whole number
+func floor(n number) number {
+	r, ok := n.toRat()
+	if !ok {
+		return n
+	}
+	// Div rounds towards negative infinity for a positive divisor
+	return exactInt(new(big.Int).Div(r.Num(), r.Denom()))
+}
+
+// ceil rounds n up to a whole number
+func ceil(n number) number {
+	return neg(floor(neg(n)))
+}
+
+// round rounds n to the nearest whole number, with halves away from zero
+func round(n number) number {
+	r, ok := n.toRat()
+	if !ok {
+		return n
+	}
+
+	half := big.NewRat(1, 2)
+	if r.Sign() < 0 {
+		return trunc(exact(new(big.Rat).Sub(r, half)))
+	}
+	return trunc(exact(new(big.Rat).Add(r, half)))
+}
+
+// absOf returns the absolute value of n
+func absOf(n number) number {
+	if n.sign() < 0 {
+		return neg(n)
+	}
+	return n
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// String formats the number with the default precision
+func (n number) String() string {
+	return formatNumber(n, defaultPrecision)
+}
+
+// formatNumber formats a number with up to digits significant digits and no
+// trailing zeros, such as 1234.5, -0.25 or 1.5e+20. Whole exact numbers are
+// written out in full and exact decimals such as 0.125 are never rounded.
+func formatNumber(n number, digits int) string {
+	if n.isInf() {
+		if n.sign() < 0 {
+			return "-∞"
+		}
+		return "∞"
+	}
+
+	if n.rat != nil {
+		if n.rat.IsInt() {
+			return n.rat.Num().String()
+		}
+		if places, ok := terminatingPlaces(n.rat); ok && places <= digits {
+			return strings.TrimRight(n.rat.FloatString(places), "0")
+		}
+	}
+
+	return formatBigFloat(n.float(precisionBits(digits)), digits)
+}
+
+// terminatingPlaces returns the number of decimal places needed to write r
+// exactly, which is possible when its denominator only has factors 2 and 5
+func terminatingPlaces(r *big.Rat) (int, bool) {
+	den := new(big.Int).Set(r.Denom())
+	twos, fives := 0, 0
+	two, five, rem := big.NewInt(2), big.NewInt(5), new(big.Int)
+
+	for {
+		q, m := new(big.Int).QuoRem(den, two, rem)
+		if m.Sign() != 0 {
+			break
+		}
+		den = q
+		twos++
+	}
+	for {
+		q, m := new(big.Int).QuoRem(den, five, rem)
+		if m.Sign() != 0 {
+			break
+		}
+		den = q
+		fives++
+	}
+
+	return max(twos, fives), den.IsInt64() && den.Int64() == 1
+}
+
+// formatBigFloat formats a float with up to digits significant digits and no
+// trailing zeros, switching to exponent notation when the value can't be
+// written with that many digits or is very small
+func formatBigFloat(f *big.Float, digits int) string {
+	if f.Sign() == 0 {
+		return "0"
+	}
+
+	// scientific gives a mantissa with the requested digits, e.g. -1.234500000e+03
+	text := scientific(f, digits-1)
+	sign := ""
+	if text[0] == '-' {
+		sign, text = "-", text[1:]
+	}
+
+	i := strings.IndexByte(text, 'e')
+	mantissa := strings.Replace(text[:i], ".", "", 1)
+	exp, _ := strconv.Atoi(text[i+1:])
+
+	if exp >= digits || exp < -6 {
+		mantissa = strings.TrimRight(mantissa[:1]+"."+mantissa[1:], "0")
+		mantissa = strings.TrimSuffix(mantissa, ".")
+		return fmt.Sprintf("%s%se%+03d", sign, mantissa, exp)
+	}
+
+	var whole, frac string
+	if exp >= 0 {
+		whole, frac = mantissa[:exp+1], mantissa[exp+1:]
+	} else {
+		whole, frac = "0", strings.Repeat("0", -exp-1)+mantissa
+	}
+
+	if frac = strings.TrimRight(frac, "0"); frac != "" {
+		return sign + whole + "." + frac
+	}
+	return sign + whole
+}
+
+// scientific formats a non-zero float in exponent notation with the given
+// number of places after the point. Text converts a float digit by digit,
+// which slows as the exponent grows, so very large and very small floats are
+// first scaled by a power of ten.
+func scientific(f *big.Float, places int) string {
+	if exp := f.MantExp(nil); exp > -64 && exp < 64 {
+		return f.Text('e', places)
+	}
+
+	// Guard bits cover the rounding of the scale's repeated multiplications
+	prec := f.Prec() + 64
+	d := int(math.Floor(log2Abs(f) * math.Log10(2)))
+	scale := new(big.Float).SetPrec(prec).SetInt64(1)
+	ten := new(big.Float).SetPrec(prec).SetInt64(10)
+	for n := abs64(int64(d)); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			scale.Mul(scale, ten)
+		}
+		ten.Mul(ten, ten)
+	}
+
+	scaled := new(big.Float).SetPrec(prec)
+	if d > 0 {
+		scaled.Quo(f, scale)
+	} else {
+		scaled.Mul(f, scale)
+	}
+
+	// The scaled value is close to 1, so its exponent only adjusts d
+	text := scaled.Text('e', places)
+	i := strings.IndexByte(text, 'e')
+	exp, _ := strconv.Atoi(text[i+1:])
+	return fmt.Sprintf("%se%+03d", text[:i], exp+d)
+}
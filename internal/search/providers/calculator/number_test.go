@@ -0,0 +1,158 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFactorialOf(t *testing.T) {
+	prec := precisionBits(defaultPrecision)
+
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "1"},
+		{5, "120"},
+		{20, "2432902008176640000"},
+	}
+	for _, tt := range tests {
+		got, err := factorialOf(exactInt(big.NewInt(tt.n)), prec)
+		if err != nil {
+			t.Fatalf("factorialOf(%d) error = %v", tt.n, err)
+		}
+		if !got.isExact() || got.String() != tt.want {
+			t.Errorf("factorialOf(%d) = %s, want exactly %s", tt.n, got, tt.want)
+		}
+	}
+
+	// Factorials too large to keep exactly are rounded rather than infinite
+	for _, n := range []int64{6000, 60000} {
+		got, err := factorialOf(exactInt(big.NewInt(n)), prec)
+		if err != nil {
+			t.Fatalf("factorialOf(%d) error = %v", n, err)
+		}
+		exact := new(big.Float).SetPrec(prec).SetInt(new(big.Int).MulRange(1, n))
+		if want := formatBigFloat(exact, defaultPrecision); got.String() != want {
+			t.Errorf("factorialOf(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	for _, n := range []number{exactInt(big.NewInt(1_000_000)), exactInt(new(big.Int).Lsh(big.NewInt(1), 70))} {
+		if _, err := factorialOf(n, prec); !errors.Is(err, errTooLarge) {
+			t.Errorf("factorialOf(%s) error = %v, want %v", n, err, errTooLarge)
+		}
+	}
+	if _, err := factorialOf(exactInt(big.NewInt(-3)), prec); err == nil {
+		t.Error("factorialOf(-3) error = nil, want an error")
+	}
+}
+
+func TestPowLimits(t *testing.T) {
+	env := &environment{prec: precisionBits(defaultPrecision)}
+
+	tests := []struct {
+		expr string
+		want string
+		err  error
+	}{
+		{"2^64", "18446744073709551616", nil},
+		{"2^-2", "0.25", nil},
+		{"2^100000", pow2Text(100000), nil},
+		{"2^-100000", pow2Text(-100000), nil},
+		{"2^1000000", "9.9006562292959e+301029", nil},
+		{"0.5^1000000", "1.01003405919803e-301030", nil},
+		{"2^30000000", "", errTooLarge},
+		{"2^100000000", "", errTooLarge},
+		{"9^9^9", "", errTooLarge},
+		{"0.5^30000000", "", errTooSmall},
+		{"2^-30000000", "", errTooSmall},
+		{"1e99999999", "", errTooLarge},
+		{"1e-99999999", "", errTooSmall},
+		{"(2^1000000)^2", "", errTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			stmt, err := parseStatement(tt.expr, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			got, err := evaluate(stmt.expr, env)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("evaluate(%s) error = %v, want %v", tt.expr, err, tt.err)
+				}
+			} else if err != nil {
+				t.Errorf("evaluate(%s) error = %v", tt.expr, err)
+			} else if text := got.String(); text != tt.want {
+				t.Errorf("evaluate(%s) = %s, want %s", tt.expr, text, tt.want)
+			}
+
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("evaluate(%s) took %v", tt.expr, elapsed)
+			}
+		})
+	}
+}
+
+// pow2Text formats 2^exp from its exact value
+func pow2Text(exp int) string {
+	f := new(big.Float).SetMantExp(big.NewFloat(1), exp)
+	text := f.Text('e', defaultPrecision-1)
+	mantissa, e, _ := strings.Cut(text, "e")
+	mantissa = strings.TrimSuffix(strings.TrimRight(mantissa, "0"), ".")
+	return mantissa + "e" + e
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		text   string
+		digits int
+		want   string
+	}{
+		{"0", 15, "0"},
+		{"1234.5", 15, "1234.5"},
+		{"-0.25", 15, "-0.25"},
+		{"0.125", 15, "0.125"},
+		{"1/3", 15, "0.333333333333333"},
+		{"2/3", 3, "0.667"},
+		{"1.5e20", 15, "150000000000000000000"},
+		{"1.5e-7", 15, "0.00000015"},
+	}
+
+	for _, tt := range tests {
+		var n number
+		if r, ok := new(big.Rat).SetString(tt.text); ok {
+			n = exact(r)
+		} else {
+			t.Fatalf("invalid test number %q", tt.text)
+		}
+		if got := formatNumber(n, tt.digits); got != tt.want {
+			t.Errorf("formatNumber(%s, %d) = %s, want %s", tt.text, tt.digits, got, tt.want)
+		}
+	}
+
+	floats := []struct {
+		value float64
+		want  string
+	}{
+		{1.5e20, "1.5e+20"},
+		{1e-7, "1e-07"},
+		{-123.456, "-123.456"},
+	}
+	for _, tt := range floats {
+		n, err := fromFloat64(tt.value, precisionBits(defaultPrecision))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := formatNumber(n, 6); got != tt.want {
+			t.Errorf("formatNumber(%g) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// intWidth is the integer type programmer mode calculates with
+type intWidth struct {
+	bits   uint
+	signed bool
+}
+
+// defaultWidth is used when the query doesn't name a width
+var defaultWidth = intWidth{bits: 64, signed: true}
+
+// widthPattern matches a trailing width option such as "u8", "i32" or "as uint16"
+var widthPattern = regexp.MustCompile(`(?i)\s+(?:(?:as|in|to)\s+)?(u|i|uint|int)(8|16|32|64)$`)
+
+// parseWidth removes a trailing width option from the query. ok is false if
+// the query doesn't have one.
+func parseWidth(query string) (expr string, width intWidth, ok bool) {
+	m := widthPattern.FindStringSubmatchIndex(query)
+	if m == nil {
+		return query, defaultWidth, false
+	}
+
+	bits, _ := strconv.Atoi(query[m[4]:m[5]])
+	signed := !strings.HasPrefix(strings.ToLower(query[m[2]:m[3]]), "u")
+
+	return query[:m[0]], intWidth{bits: uint(bits), signed: signed}, true
+}
+
+// String describes the width, e.g. "32-bit unsigned"
+func (w intWidth) String() string {
+	if w.signed {
+		return fmt.Sprintf("%d-bit signed", w.bits)
+	}
+	return fmt.Sprintf("%d-bit unsigned", w.bits)
+}
+
+// wrap truncates v to the width, as an overflowing integer would be
+func (w intWidth) wrap(v uint64) uint64 {
+	if w.bits >= 64 {
+		return v
+	}
+	return v & (1<<w.bits - 1)
+}
+
+// signedValue sign-extends a wrapped value to an int64
+func (w intWidth) signedValue(v uint64) int64 {
+	shift := 64 - w.bits
+	return int64(v<<shift) >> shift
+}
+
+// number returns a wrapped value as a number, signed or unsigned depending on the width
+func (w intWidth) number(v uint64) number {
+	if w.signed {
+		return exactInt(big.NewInt(w.signedValue(v)))
+	}
+	return exactInt(new(big.Int).SetUint64(v))
+}
+
+// decimal formats a wrapped value in base 10, signed or unsigned depending on the width
+func (w intWidth) decimal(v uint64) string {
+	if w.signed {
+		return strconv.FormatInt(w.signedValue(v), 10)
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// isProgrammerExpression reports whether the tokens use base prefixes or
+// bitwise operators, which switch the calculator to integer arithmetic
+func isProgrammerExpression(tokens []token) bool {
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenNumber:
+			if hasBasePrefix(tok.text) {
+				return true
+			}
+		case tokenOperator:
+			switch tok.text {
+			case "&", "|", "~", "<<", ">>":
+				return true
+			}
+		case tokenIdent:
+			if strings.EqualFold(tok.text, "xor") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasBasePrefix reports whether a number literal starts with 0x, 0b or 0o
+func hasBasePrefix(text string) bool {
+	return len(text) > 2 && text[0] == '0' && strings.ContainsRune("xXbBoO", rune(text[1]))
+}
+
+// evalInteger evaluates an expression with integers of the given width.
+// Operations wrap around on overflow, and division and right shifts are
+// signed or unsigned depending on the width. Anything without an integer
+// meaning, such as functions, is evaluated as a number that must be whole.
+func evalInteger(n node, env *environment, w intWidth) (uint64, error) {
+	switch n := n.(type) {
+	case unaryNode:
+		if n.op == "!" {
+			break
+		}
+
+		v, err := evalInteger(n.operand, env, w)
+		if err != nil {
+			return 0, err
+		}
+
+		switch n.op {
+		case "-":
+			return w.wrap(-v), nil
+		case "~":
+			return w.wrap(^v), nil
+		}
+		return v, nil
+
+	case binaryNode:
+		if n.op == "^" {
+			break
+		}
+
+		left, err := evalInteger(n.left, env, w)
+		if err != nil {
+			return 0, err
+		}
+		right, err := evalInteger(n.right, env, w)
+		if err != nil {
+			return 0, err
+		}
+
+		return integerOp(n.op, left, right, w)
+	}
+
+	value, err := evaluate(n, env)
+	if err != nil {
+		return 0, err
+	}
+	return toInteger(value, w)
+}
+
+// integerOp applies a binary operator to two wrapped values
+func integerOp(op string, left, right uint64, w intWidth) (uint64, error) {
+	switch op {
+	case "+":
+		return w.wrap(left + right), nil
+	case "-":
+		return w.wrap(left - right), nil
+	case "*":
+		return w.wrap(left * right), nil
+	case "/", "%":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		if w.signed {
+			l, r := w.signedValue(left), w.signedValue(right)
+			if op == "/" {
+				return w.wrap(uint64(l / r)), nil
+			}
+			return w.wrap(uint64(l % r)), nil
+		}
+		if op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "&":
+		return left & right, nil
+	case "|":
+		return left | right, nil
+	case "xor":
+		return left ^ right, nil
+	case "<<", ">>":
+		if w.signed && w.signedValue(right) < 0 {
+			return 0, fmt.Errorf("negative shift count")
+		}
+		if right >= uint64(w.bits) {
+			return 0, fmt.Errorf("shift count %d is out of range for %s integers", right, w)
+		}
+		if op == "<<" {
+			return w.wrap(left << right), nil
+		}
+		if w.signed {
+			return w.wrap(uint64(w.signedValue(left) >> right)), nil
+		}
+		return left >> right, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator %s", op)
+}
+
+// toInteger converts a whole number to a wrapped integer
+func toInteger(value number, w intWidth) (uint64, error) {
+	i, ok := value.integer()
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("%s is not a whole number", value)
+	case i.IsInt64():
+		return w.wrap(uint64(i.Int64())), nil
+	case i.IsUint64():
+		return w.wrap(i.Uint64()), nil
+	}
+	return 0, fmt.Errorf("%s does not fit in 64 bits", value)
+}
+
+// groupDigits separates digits into groups of size from the right, e.g. 1111_0000
+func groupDigits(digits string, size int) string {
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%size == 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package calculator
+
+import "testing"
+
+func TestIntegerShift(t *testing.T) {
+	tests := []struct {
+		op          string
+		left, right uint64
+		width       intWidth
+		want        uint64
+	}{
+		{"<<", 1, 4, intWidth{bits: 8}, 16},
+		{"<<", 1, 7, intWidth{bits: 8}, 128},
+		{"<<", 0xff, 4, intWidth{bits: 8}, 0xf0},
+		{"<<", 1, 63, defaultWidth, 1 << 63},
+		{">>", 0x80, 7, intWidth{bits: 8, signed: true}, 0xff},
+		{">>", 0x80, 7, intWidth{bits: 8}, 1},
+	}
+
+	for _, tt := range tests {
+		got, err := integerOp(tt.op, tt.left, tt.right, tt.width)
+		if err != nil || got != tt.want {
+			t.Errorf("%d %s %d as %s = %d, %v, want %d", tt.left, tt.op, tt.right, tt.width, got, err, tt.want)
+		}
+	}
+
+	errors := []struct {
+		op          string
+		left, right uint64
+		width       intWidth
+		want        string
+	}{
+		{"<<", 1, 70, defaultWidth, "shift count 70 is out of range for 64-bit signed integers"},
+		{"<<", 1, 64, defaultWidth, "shift count 64 is out of range for 64-bit signed integers"},
+		{"<<", 1, 8, intWidth{bits: 8}, "shift count 8 is out of range for 8-bit unsigned integers"},
+		{">>", 1, 32, intWidth{bits: 32}, "shift count 32 is out of range for 32-bit unsigned integers"},
+		{"<<", 1, 0xff, intWidth{bits: 8, signed: true}, "negative shift count"},
+	}
+
+	for _, tt := range errors {
+		if _, err := integerOp(tt.op, tt.left, tt.right, tt.width); err == nil || err.Error() != tt.want {
+			t.Errorf("%d %s %d as %s error = %v, want %q", tt.left, tt.op, tt.right, tt.width, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errNotConversion is returned for queries that aren't unit conversions
+var errNotConversion = errors.New("not a unit conversion")
+
+// unitSpace temporarily replaces the spaces in multi-word unit names so they
+// are read as a single token
+const unitSpace = "\u00a0"
+
+// unitCategory groups units that can be converted into each other
+type unitCategory string
+
+const (
+	categoryLength      unitCategory = "length"
+	categoryMass        unitCategory = "mass"
+	categoryVolume      unitCategory = "volume"
+	categoryTemperature unitCategory = "temperature"
+	categoryArea        unitCategory = "area"
+	categorySpeed       unitCategory = "speed"
+	categoryData        unitCategory = "data size"
+	categoryTime        unitCategory = "time"
+	categoryAngle       unitCategory = "angle"
+)
+
+// unit is a unit of measure. A value is converted to the category's base unit
+// with value*factor + offset; only temperatures have an offset.
+type unit struct {
+	symbol   string // Shown in results, e.g. "km"
+	category unitCategory
+	factor   float64
+	offset   float64
+}
+
+// toBase converts a value in this unit to the category's base unit
+func (u *unit) toBase(value float64) float64 {
+	return value*u.factor + u.offset
+}
+
+// fromBase converts a value in the category's base unit to this unit
+func (u *unit) fromBase(value float64) float64 {
+	return (value - u.offset) / u.factor
+}
+
+// format appends the unit's symbol to a formatted value, e.g. 5 km or 90°
+func (u *unit) format(value string) string {
+	if strings.HasPrefix(u.symbol, "°") {
+		return value + u.symbol
+	}
+	return value + " " + u.symbol
+}
+
+// unitDefinition lists a unit's symbol and the other names it may be written as
+type unitDefinition struct {
+	names    []string // The first name is the symbol shown in results
+	category unitCategory
+	factor   float64
+	offset   float64
+}
+
+// unitDefinitions are the supported units. Base units are metre, kilogram,
+// litre, kelvin, square metre, metre per second, byte, second and radian.
+// Where lowercase names collide, the unit listed first wins.
+var unitDefinitions = []unitDefinition{
+	// Length
+	{names: []string{"m", "meter", "meters", "metre", "metres"}, category: categoryLength, factor: 1},
+	{names: []string{"km", "kilometer", "kilometers", "kilometre", "kilometres"}, category: categoryLength, factor: 1000},
+	{names: []string{"cm", "centimeter", "centimeters", "centimetre", "centimetres"}, category: categoryLength, factor: 0.01},
+	{names: []string{"mm", "millimeter", "millimeters", "millimetre", "millimetres"}, category: categoryLength, factor: 0.001},
+	{names: []string{"µm", "um", "micrometer", "micrometers", "micron", "microns"}, category: categoryLength, factor: 1e-6},
+	{names: []string{"nm", "nanometer", "nanometers"}, category: categoryLength, factor: 1e-9},
+	{names: []string{"mi", "mile", "miles"}, category: categoryLength, factor: 1609.344},
+	{names: []string{"yd", "yard", "yards"}, category: categoryLength, factor: 0.9144},
+	{names: []string{"ft", "foot", "feet", "'"}, category: categoryLength, factor: 0.3048},
+	{names: []string{"in", "inch", "inches", "\""}, category: categoryLength, factor: 0.0254},
+	{names: []string{"nmi", "nautical mile", "nautical miles"}, category: categoryLength, factor: 1852},
+	{names: []string{"au", "astronomical unit", "astronomical units"}, category: categoryLength, factor: 149597870700},
+	{names: []string{"ly", "light year", "light years", "lightyear", "lightyears"}, category: categoryLength, factor: 9460730472580800},
+
+	// Mass
+	{names: []string{"kg", "kilogram", "kilograms", "kilo", "kilos"}, category: categoryMass, factor: 1},
+	{names: []string{"g", "gram", "grams"}, category: categoryMass, factor: 0.001},
+	{names: []string{"mg", "milligram", "milligrams"}, category: categoryMass, factor: 1e-6},
+	{names: []string{"µg", "ug", "microgram", "micrograms"}, category: categoryMass, factor: 1e-9},
+	{names: []string{"t", "tonne", "tonnes", "metric ton", "metric tons"}, category: categoryMass, factor: 1000},
+	{names: []string{"lb", "lbs", "pound", "pounds"}, category: categoryMass, factor: 0.45359237},
+	{names: []string{"oz", "ounce", "ounces"}, category: categoryMass, factor: 0.028349523125},
+	{names: []string{"st", "stone", "stones"}, category: categoryMass, factor: 6.35029318},
+	{names: []string{"ton", "tons", "short ton", "short tons"}, category: categoryMass, factor: 907.18474},
+
+	// Volume
+	{names: []string{"L", "l", "liter", "liters", "litre", "litres"}, category: categoryVolume, factor: 1},
+	{names: []string{"mL", "ml", "milliliter", "milliliters", "millilitre", "millilitres"}, category: categoryVolume, factor: 0.001},
+	{names: []string{"cL", "cl", "centiliter", "centiliters"}, category: categoryVolume, factor: 0.01},
+	{names: []string{"dL", "dl", "deciliter", "deciliters"}, category: categoryVolume, factor: 0.1},
+	{names: []string{"m³", "m3", "cubic meter", "cubic meters", "cubic metre", "cubic metres"}, category: categoryVolume, factor: 1000},
+	{names: []string{"cm³", "cm3", "cc", "cubic centimeter", "cubic centimeters"}, category: categoryVolume, factor: 0.001},
+	{names: []string{"ft³", "ft3", "cubic foot", "cubic feet"}, category: categoryVolume, factor: 28.316846592},
+	{names: []string{"in³", "in3", "cubic inch", "cubic inches"}, category: categoryVolume, factor: 0.016387064},
+	{names: []string{"gal", "gallon", "gallons"}, category: categoryVolume, factor: 3.785411784},
+	{names: []string{"qt", "quart", "quarts"}, category: categoryVolume, factor: 0.946352946},
+	{names: []string{"pt", "pint", "pints"}, category: categoryVolume, factor: 0.473176473},
+	{names: []string{"cup", "cups"}, category: categoryVolume, factor: 0.2365882365},
+	{names: []string{"fl oz", "floz", "fluid ounce", "fluid ounces"}, category: categoryVolume, factor: 0.0295735295625},
+	{names: []string{"tbsp", "tablespoon", "tablespoons"}, category: categoryVolume, factor: 0.01478676478125},
+	{names: []string{"tsp", "teaspoon", "teaspoons"}, category: categoryVolume, factor: 0.00492892159375},
+
+	// Temperature
+	{names: []string{"K", "k", "kelvin", "kelvins"}, category: categoryTemperature, factor: 1},
+	{names: []string{"°C", "C", "c", "celsius", "centigrade", "degc"}, category: categoryTemperature, factor: 1, offset: 273.15},
+	{names: []string{"°F", "F", "f", "fahrenheit", "degf"}, category: categoryTemperature, factor: 5.0 / 9.0, offset: 273.15 - 32*5.0/9.0},
+
+	// Area
+	{names: []string{"m²", "m2", "sq m", "square meter", "square meters", "square metre", "square metres"}, category: categoryArea, factor: 1},
+	{names: []string{"km²", "km2", "sq km", "square kilometer", "square kilometers"}, category: categoryArea, factor: 1e6},
+	{names: []string{"cm²", "cm2", "sq cm", "square centimeter", "square centimeters"}, category: categoryArea, factor: 1e-4},
+	{names: []string{"mm²", "mm2", "sq mm", "square millimeter", "square millimeters"}, category: categoryArea, factor: 1e-6},
+	{names: []string{"ft²", "ft2", "sq ft", "sqft", "square foot", "square feet"}, category: categoryArea, factor: 0.09290304},
+	{names: []string{"in²", "in2", "sq in", "square inch", "square inches"}, category: categoryArea, factor: 0.00064516},
+	{names: []string{"yd²", "yd2", "sq yd", "square yard", "square yards"}, category: categoryArea, factor: 0.83612736},
+	{names: []string{"mi²", "mi2", "sq mi", "square mile", "square miles"}, category: categoryArea, factor: 2589988.110336},
+	{names: []string{"ha", "hectare", "hectares"}, category: categoryArea, factor: 1e4},
+	{names: []string{"ac", "acre", "acres"}, category: categoryArea, factor: 4046.8564224},
+
+	// Speed
+	{names: []string{"m/s", "mps", "meters per second", "metres per second"}, category: categorySpeed, factor: 1},
+	{names: []string{"km/h", "kph", "kmh", "kmph", "kilometers per hour", "kilometres per hour"}, category: categorySpeed, factor: 1000.0 / 3600},
+	{names: []string{"mph", "mi/h", "miles per hour"}, category: categorySpeed, factor: 1609.344 / 3600},
+	{names: []string{"ft/s", "fps", "feet per second"}, category: categorySpeed, factor: 0.3048},
+	{names: []string{"kn", "kt", "knot", "knots"}, category: categorySpeed, factor: 1852.0 / 3600},
+
+	// Data size, decimal (KB) and binary (KiB) prefixes
+	{names: []string{"B", "byte", "bytes"}, category: categoryData, factor: 1},
+	{names: []string{"bit", "bits", "b"}, category: categoryData, factor: 0.125},
+	{names: []string{"KB", "kilobyte", "kilobytes"}, category: categoryData, factor: 1e3},
+	{names: []string{"MB", "megabyte", "megabytes"}, category: categoryData, factor: 1e6},
+	{names: []string{"GB", "gigabyte", "gigabytes"}, category: categoryData, factor: 1e9},
+	{names: []string{"TB", "terabyte", "terabytes"}, category: categoryData, factor: 1e12},
+	{names: []string{"PB", "petabyte", "petabytes"}, category: categoryData, factor: 1e15},
+	{names: []string{"KiB", "kibibyte", "kibibytes"}, category: categoryData, factor: 1 << 10},
+	{names: []string{"MiB", "mebibyte", "mebibytes"}, category: categoryData, factor: 1 << 20},
+	{names: []string{"GiB", "gibibyte", "gibibytes"}, category: categoryData, factor: 1 << 30},
+	{names: []string{"TiB", "tebibyte", "tebibytes"}, category: categoryData, factor: 1 << 40},
+	{names: []string{"PiB", "pebibyte", "pebibytes"}, category: categoryData, factor: 1 << 50},
+	{names: []string{"kbit", "Kbit", "kilobit", "kilobits"}, category: categoryData, factor: 125},
+	{names: []string{"Mbit", "Mb", "megabit", "megabits"}, category: categoryData, factor: 125e3},
+	{names: []string{"Gbit", "Gb", "gigabit", "gigabits"}, category: categoryData, factor: 125e6},
+
+	// Time
+	{names: []string{"s", "sec", "secs", "second", "seconds"}, category: categoryTime, factor: 1},
+	{names: []string{"ms", "millisecond", "milliseconds"}, category: categoryTime, factor: 1e-3},
+	{names: []string{"µs", "us", "microsecond", "microseconds"}, category: categoryTime, factor: 1e-6},
+	{names: []string{"ns", "nanosecond", "nanoseconds"}, category: categoryTime, factor: 1e-9},
+	{names: []string{"min", "mins", "minute", "minutes"}, category: categoryTime, factor: 60},
+	{names: []string{"h", "hr", "hrs", "hour", "hours"}, category: categoryTime, factor: 3600},
+	{names: []string{"d", "day", "days"}, category: categoryTime, factor: 86400},
+	{names: []string{"wk", "week", "weeks"}, category: categoryTime, factor: 604800},
+	{names: []string{"mo", "month", "months"}, category: categoryTime, factor: 2629746},
+	{names: []string{"yr", "year", "years"}, category: categoryTime, factor: 31556952},
+
+	// Angle
+	{names: []string{"rad", "radian", "radians"}, category: categoryAngle, factor: 1},
+	{names: []string{"°", "deg", "degree", "degrees"}, category: categoryAngle, factor: math.Pi / 180},
+	{names: []string{"grad", "gradian", "gradians", "gon"}, category: categoryAngle, factor: math.Pi / 200},
+	{names: []string{"turn", "turns", "rev", "revolution", "revolutions"}, category: categoryAngle, factor: 2 * math.Pi},
+	{names: []string{"arcmin", "arcminute", "arcminutes"}, category: categoryAngle, factor: math.Pi / 10800},
+	{names: []string{"arcsec", "arcsecond", "arcseconds"}, category: categoryAngle, factor: math.Pi / 648000},
+}
+
+var (
+	// unitsByName maps every exact unit name to its unit
+	unitsByName = map[string]*unit{}
+	// unitsByLowerName maps lowercase unit names to units, for case-insensitive matching
+	unitsByLowerName = map[string]*unit{}
+	// multiWordUnits are the unit names containing spaces, longest first
+	multiWordUnits = []string{}
+)
+
+func init() {
+	for _, def := range unitDefinitions {
+		u := &unit{symbol: def.names[0], category: def.category, factor: def.factor, offset: def.offset}
+		for _, name := range def.names {
+			if _, ok := unitsByName[name]; !ok {
+				unitsByName[name] = u
+			}
+			lower := strings.ToLower(name)
+			if _, ok := unitsByLowerName[lower]; !ok {
+				unitsByLowerName[lower] = u
+			}
+			if strings.Contains(name, " ") {
+				multiWordUnits = append(multiWordUnits, lower)
+			}
+		}
+	}
+
+	// Match longer names first so "square feet" isn't read as "square" "feet"
+	sort.Slice(multiWordUnits, func(i, j int) bool {
+		return len(multiWordUnits[i]) > len(multiWordUnits[j])
+	})
+}
+
+// lookupUnit finds a unit by name, preferring an exact match so that e.g.
+// "Mb" (megabit) and "MB" (megabyte) stay distinct
+func lookupUnit(name string) (*unit, bool) {
+	name = strings.ReplaceAll(name, unitSpace, " ")
+	if u, ok := unitsByName[name]; ok {
+		return u, true
+	}
+	u, ok := unitsByLowerName[strings.ToLower(name)]
+	return u, ok
+}
+
+var (
+	// conversionSeparator separates the quantity from the target unit
+	conversionSeparator = regexp.MustCompile(`(?i)\s+(?:in|to|as|into)\s+`)
+	// quantityPattern matches one number followed by a unit, e.g. 3ft or 4.5 km/h.
+	// Units stop at the next digit, except for a trailing 2 or 3 as in m2.
+	quantityPattern = regexp.MustCompile(`^\s*([-+]?(?:\d[\d,_]*\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*([^\s\d.,+-][^\s\d]*[23]?)`)
+)
+
+// conversion is a parsed unit conversion query
+type conversion struct {
+	value float64 // Value in the source unit's base unit
+	from  []*unit // Source units, more than one for compound values like 3ft 4in
+	to    *unit
+	input string // Normalized source quantity for display
+}
+
+// parseConversion parses queries such as "5 km in miles", "72F to C" and "3ft 4in to cm"
+func parseConversion(query string) (*conversion, error) {
+	query = joinMultiWordUnits(strings.TrimSpace(query))
+
+	// Try each separator in turn, since "in" may also be a unit as in "5 in in cm"
+	var err error = errNotConversion
+	for start := 0; start < len(query); {
+		loc := conversionSeparator.FindStringIndex(query[start:])
+		if loc == nil {
+			break
+		}
+		loc[0], loc[1] = loc[0]+start, loc[1]+start
+		start = loc[0] + 1
+
+		to, ok := lookupUnit(strings.TrimSpace(query[loc[1]:]))
+		if !ok {
+			continue
+		}
+
+		var conv *conversion
+		conv, err = parseQuantity(query[:loc[0]], to)
+		if err == nil {
+			return conv, nil
+		}
+	}
+
+	return nil, err
+}
+
+// parseQuantity parses a possibly compound quantity such as "3ft 4in" for conversion to the target unit
+func parseQuantity(source string, to *unit) (*conversion, error) {
+	conv := &conversion{to: to}
+	parts := []string{}
+
+	rest := source
+	for strings.TrimSpace(rest) != "" {
+		m := quantityPattern.FindStringSubmatchIndex(rest)
+		if m == nil {
+			return nil, errNotConversion
+		}
+
+		numberText := strings.NewReplacer(",", "", "_", "").Replace(rest[m[2]:m[3]])
+		number, err := strconv.ParseFloat(numberText, 64)
+		if err != nil {
+			return nil, errNotConversion
+		}
+
+		from, ok := lookupUnit(rest[m[4]:m[5]])
+		if !ok {
+			return nil, errNotConversion
+		}
+
+		if from.category != to.category {
+			return nil, fmt.Errorf("cannot convert %s to %s", from.category, to.category)
+		}
+		if from.category == categoryTemperature && len(conv.from) > 0 {
+			return nil, fmt.Errorf("temperatures cannot be added together")
+		}
+
+		conv.value += from.toBase(number)
+		conv.from = append(conv.from, from)
+		parts = append(parts, from.format(formatFloat(number)))
+
+		rest = rest[m[1]:]
+	}
+
+	if len(conv.from) == 0 {
+		return nil, errNotConversion
+	}
+
+	conv.input = strings.Join(parts, " ")
+	return conv, nil
+}
+
+// result returns the converted value in the target unit
+func (c *conversion) result() float64 {
+	return c.to.fromBase(c.value)
+}
+
+// joinMultiWordUnits joins the words of multi-word unit names, such as
+// "fl oz" and "square feet", with unitSpace so they stay one token
+func joinMultiWordUnits(text string) string {
+	lower := strings.ToLower(text)
+	for _, name := range multiWordUnits {
+		joined := strings.ReplaceAll(name, " ", unitSpace)
+		for {
+			idx := strings.Index(lower, name)
+			if idx < 0 {
+				break
+			}
+			text = text[:idx] + joined + text[idx+len(name):]
+			lower = lower[:idx] + joined + lower[idx+len(name):]
+		}
+	}
+	return text
+}
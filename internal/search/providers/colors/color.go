@@ -0,0 +1,397 @@
+package colors
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// rgba is a color with each channel from 0 to 1
+type rgba struct {
+	r, g, b, a float64
+}
+
+// fromHex makes an opaque color from a 0xRRGGBB value
+func fromHex(v uint32) rgba {
+	return rgba{
+		r: float64(v>>16&0xff) / 255,
+		g: float64(v>>8&0xff) / 255,
+		b: float64(v&0xff) / 255,
+		a: 1,
+	}
+}
+
+// channel returns a 0 to 1 value as a byte, rounded
+func channel(v float64) uint8 {
+	return uint8(math.Round(clamp(v) * 255))
+}
+
+// clamp limits v to 0 to 1
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// bytes returns the color's channels as bytes
+func (c rgba) bytes() (uint8, uint8, uint8, uint8) {
+	return channel(c.r), channel(c.g), channel(c.b), channel(c.a)
+}
+
+// opaque reports whether the color has no transparency
+func (c rgba) opaque() bool {
+	return channel(c.a) == 255
+}
+
+// over blends the color over an opaque background
+func (c rgba) over(background rgba) rgba {
+	return rgba{
+		r: c.r*c.a + background.r*(1-c.a),
+		g: c.g*c.a + background.g*(1-c.a),
+		b: c.b*c.a + background.b*(1-c.a),
+		a: 1,
+	}
+}
+
+// hex writes the color as #rrggbb, or #rrggbbaa if it is transparent
+func (c rgba) hex() string {
+	r, g, b, a := c.bytes()
+	if c.opaque() {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, a)
+}
+
+// rgb writes the color as a CSS rgb() or rgba() function
+func (c rgba) rgb() string {
+	r, g, b, _ := c.bytes()
+	if c.opaque() {
+		return fmt.Sprintf("rgb(%d, %d, %d)", r, g, b)
+	}
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, formatFloat(c.a, 2))
+}
+
+// hsl writes the color as a CSS hsl() or hsla() function
+func (c rgba) hsl() string {
+	h, s, l := c.toHSL()
+	text := fmt.Sprintf("%s, %s%%, %s%%", formatFloat(h, 0), formatFloat(s*100, 0), formatFloat(l*100, 0))
+	if c.opaque() {
+		return "hsl(" + text + ")"
+	}
+	return fmt.Sprintf("hsla(%s, %s)", text, formatFloat(c.a, 2))
+}
+
+// nsColor writes the color as a Swift NSColor initializer
+func (c rgba) nsColor() string {
+	return fmt.Sprintf("NSColor(red: %s, green: %s, blue: %s, alpha: %s)",
+		formatFloat(c.r, 3), formatFloat(c.g, 3), formatFloat(c.b, 3), formatFloat(c.a, 3))
+}
+
+// formatFloat writes v with at most the given decimals, without trailing zeros
+func formatFloat(v float64, decimals int) string {
+	text := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		return "0"
+	}
+	return text
+}
+
+// toHSL returns the color's hue in degrees and its saturation and lightness
+// from 0 to 1
+func (c rgba) toHSL() (float64, float64, float64) {
+	r, g, b := clamp(c.r), clamp(c.g), clamp(c.b)
+	high, low := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (high + low) / 2
+	if high == low {
+		return 0, 0, l
+	}
+
+	d := high - low
+	s := d / (1 - math.Abs(2*l-1))
+
+	var h float64
+	switch high {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// fromHSL makes a color from a hue in degrees and saturation and lightness
+// from 0 to 1
+func fromHSL(h, s, l, a float64) rgba {
+	h = math.Mod(math.Mod(h, 360)+360, 360)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return rgba{r: r + m, g: g + m, b: b + m, a: a}
+}
+
+// luminance returns the color's relative luminance as WCAG defines it
+func (c rgba) luminance() float64 {
+	linear := func(v float64) float64 {
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(clamp(c.r)) + 0.7152*linear(clamp(c.g)) + 0.0722*linear(clamp(c.b))
+}
+
+// contrast returns the WCAG contrast ratio of the color on an opaque
+// background, from 1 to 21
+func (c rgba) contrast(background rgba) float64 {
+	l1, l2 := c.over(background).luminance(), background.luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// name returns the CSS name of the color, or the nearest one and false if
+// no name is exactly the color
+func (c rgba) name() (string, bool) {
+	r, g, b, _ := c.bytes()
+
+	names := make([]string, 0, len(cssNames))
+	for name := range cssNames {
+		names = append(names, name)
+	}
+	// Sorted so colors with two names, such as gray and grey, always get the same one
+	sort.Strings(names)
+
+	best, bestDistance := "", math.MaxInt
+	for _, name := range names {
+		v := cssNames[name]
+		dr, dg, db := int(v>>16&0xff)-int(r), int(v>>8&0xff)-int(g), int(v&0xff)-int(b)
+		if distance := dr*dr + dg*dg + db*db; distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best, bestDistance == 0 && c.opaque()
+}
+
+var (
+	hexPattern  = regexp.MustCompile(`^#?([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	funcPattern = regexp.MustCompile(`(?i)^(rgba?|hsla?)\s*\((.*)\)$`)
+	// labelledPattern matches the labelled channels of NSColor, UIColor and
+	// SwiftUI Color initializers and of [NSColor colorWithRed:…] messages,
+	// where the labels are only the end of a word
+	labelledPattern = regexp.MustCompile(`(?i)(red|green|blue|alpha|opacity)\s*:\s*([0-9]*\.?[0-9]+)`)
+)
+
+// parseColor reads a color in hex, rgb(), hsl(), a CSS name or NSColor style
+// floats, returning the color and the name of the format it was in
+func parseColor(text string) (rgba, string, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), ";"))
+
+	if v, ok := cssNames[strings.ToLower(text)]; ok {
+		return fromHex(v), "CSS name", nil
+	}
+	if m := hexPattern.FindStringSubmatch(text); m != nil {
+		return parseHex(m[1]), "hex", nil
+	}
+	if m := funcPattern.FindStringSubmatch(text); m != nil {
+		fn := strings.ToLower(m[1])
+		var c rgba
+		var err error
+		if strings.HasPrefix(fn, "rgb") {
+			c, err = parseRGB(m[2])
+		} else {
+			c, err = parseHSL(m[2])
+		}
+		if err != nil {
+			return rgba{}, "", fmt.Errorf("invalid %s(): %w", fn, err)
+		}
+		return c, fn + "()", nil
+	}
+	if c, ok, err := parseFloats(text); ok {
+		return c, "NSColor", err
+	}
+
+	return rgba{}, "", fmt.Errorf("use hex such as #3366cc, rgb(), hsl(), a CSS name or floats such as NSColor(red: 0.2, green: 0.4, blue: 0.8, alpha: 1)")
+}
+
+// parseHex reads 3, 4, 6 or 8 hex digits
+func parseHex(digits string) rgba {
+	if len(digits) <= 4 {
+		var b strings.Builder
+		for _, d := range digits {
+			b.WriteRune(d)
+			b.WriteRune(d)
+		}
+		digits = b.String()
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+
+	v, _ := strconv.ParseUint(digits, 16, 32)
+	c := fromHex(uint32(v >> 8))
+	c.a = float64(v&0xff) / 255
+	return c
+}
+
+// splitArgs splits the arguments of a CSS color function, which may be
+// separated by commas or spaces, with a / before the alpha
+func splitArgs(args string) []string {
+	return strings.FieldsFunc(args, func(r rune) bool {
+		return r == ',' || r == '/' || unicode.IsSpace(r)
+	})
+}
+
+// parseRGB reads the arguments of rgb() or rgba()
+func parseRGB(args string) (rgba, error) {
+	parts := splitArgs(args)
+	if len(parts) != 3 && len(parts) != 4 {
+		return rgba{}, fmt.Errorf("expected red, green, blue and optionally alpha")
+	}
+
+	var channels [3]float64
+	for i := range channels {
+		v, err := parseNumber(parts[i], 255)
+		if err != nil {
+			return rgba{}, err
+		}
+		channels[i] = v
+	}
+	c := rgba{r: channels[0], g: channels[1], b: channels[2], a: 1}
+
+	if len(parts) == 4 {
+		a, err := parseNumber(parts[3], 1)
+		if err != nil {
+			return rgba{}, err
+		}
+		c.a = a
+	}
+	return c, nil
+}
+
+// parseHSL reads the arguments of hsl() or hsla()
+func parseHSL(args string) (rgba, error) {
+	parts := splitArgs(args)
+	if len(parts) != 3 && len(parts) != 4 {
+		return rgba{}, fmt.Errorf("expected hue, saturation, lightness and optionally alpha")
+	}
+
+	h, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(parts[0]), "deg"), 64)
+	if err != nil {
+		return rgba{}, fmt.Errorf("invalid hue %q", parts[0])
+	}
+	// Saturation and lightness are percentages, with or without the %
+	s, err := parseNumber(strings.TrimSuffix(parts[1], "%")+"%", 1)
+	if err != nil {
+		return rgba{}, err
+	}
+	l, err := parseNumber(strings.TrimSuffix(parts[2], "%")+"%", 1)
+	if err != nil {
+		return rgba{}, err
+	}
+
+	a := 1.0
+	if len(parts) == 4 {
+		if a, err = parseNumber(parts[3], 1); err != nil {
+			return rgba{}, err
+		}
+	}
+	return fromHSL(h, s, l, a), nil
+}
+
+// parseNumber reads a number from 0 to limit, or a percentage, as 0 to 1
+func parseNumber(text string, limit float64) (float64, error) {
+	if percent, ok := strings.CutSuffix(text, "%"); ok {
+		v, err := strconv.ParseFloat(percent, 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, fmt.Errorf("invalid percentage %q", text)
+		}
+		return v / 100, nil
+	}
+
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || v < 0 || v > limit {
+		return 0, fmt.Errorf("invalid value %q, expected 0 to %s", text, formatFloat(limit, 0))
+	}
+	return v / limit, nil
+}
+
+// parseFloats reads NSColor style channels from 0 to 1, either labelled as
+// in NSColor(red: 0.2, green: 0.4, blue: 0.8, alpha: 1) or as three or four
+// bare numbers such as 0.2 0.4 0.8. It reports whether text looked like
+// floats at all.
+func parseFloats(text string) (rgba, bool, error) {
+	values := map[string]float64{}
+	for _, m := range labelledPattern.FindAllStringSubmatch(text, -1) {
+		label := strings.ToLower(m[1])
+		if label == "opacity" {
+			label = "alpha"
+		}
+		v, _ := strconv.ParseFloat(m[2], 64)
+		values[label] = v
+	}
+
+	if len(values) == 0 {
+		parts := splitArgs(text)
+		if len(parts) != 3 && len(parts) != 4 {
+			return rgba{}, false, nil
+		}
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return rgba{}, false, nil
+			}
+			values[[]string{"red", "green", "blue", "alpha"}[i]] = v
+		}
+	}
+
+	c := rgba{a: 1}
+	for label, v := range values {
+		if v < 0 || v > 1 {
+			return rgba{}, true, fmt.Errorf("%s is %s, NSColor channels are from 0 to 1", label, formatFloat(v, 3))
+		}
+		switch label {
+		case "red":
+			c.r = v
+		case "green":
+			c.g = v
+		case "blue":
+			c.b = v
+		case "alpha":
+			c.a = v
+		}
+	}
+	for _, label := range []string{"red", "green", "blue"} {
+		if _, ok := values[label]; !ok {
+			return rgba{}, true, fmt.Errorf("missing %s", label)
+		}
+	}
+	return c, true, nil
+}
@@ -0,0 +1,215 @@
+package colors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+var _ search.Provider = (*Provider)(nil)
+
+var (
+	white = rgba{r: 1, g: 1, b: 1, a: 1}
+	black = rgba{a: 1}
+)
+
+// keywords start color queries that aren't recognisable as colors by
+// themselves, such as CSS names
+var keywords = []string{"color", "colour"}
+
+// prefixes start queries that are colors without a keyword
+var prefixes = []string{"rgb(", "rgba(", "hsl(", "hsla(", "nscolor", "[nscolor", "uicolor", "[uicolor"}
+
+// Provider is a search provider for colors. It converts colors typed as
+// hex, rgb(), hsl(), CSS names or NSColor style floats between those
+// formats, and shows their contrast against white and black, each result
+// with a swatch of the color.
+type Provider struct {
+	priority int
+}
+
+// NewProvider creates a new color provider
+func NewProvider(priority int) *Provider {
+	return &Provider{
+		priority: priority,
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Colors"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// splitQuery returns the color text of a query: the text after a keyword,
+// or the whole query if it is a hex color or starts like rgb() or NSColor
+func splitQuery(query string) (string, bool) {
+	query = strings.TrimSpace(query)
+
+	keyword, rest, _ := strings.Cut(query, " ")
+	for _, k := range keywords {
+		if strings.EqualFold(keyword, k) {
+			return strings.TrimSpace(rest), true
+		}
+	}
+
+	if strings.HasPrefix(query, "#") {
+		return query, hexPattern.MatchString(query)
+	}
+	lower := strings.ToLower(query)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return query, true
+		}
+	}
+	return "", false
+}
+
+// CanHandle returns whether the query is a color or starts with "color"
+func (p *Provider) CanHandle(query string) bool {
+	_, ok := splitQuery(query)
+	return ok
+}
+
+// Search converts the color in the query, or in the clipboard after a bare
+// keyword
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	text, ok := splitQuery(query)
+	if !ok {
+		return nil, nil
+	}
+
+	source := "query"
+	if text == "" {
+		clipText, err := util.GetFromClipboard()
+		if err != nil {
+			return []search.SearchResult{errorResult("Can't read the clipboard", err.Error())}, nil
+		}
+		source, text = "clipboard", strings.TrimSpace(clipText)
+		if text == "" {
+			return []search.SearchResult{errorResult(
+				"Type a color",
+				"e.g. color #3366cc, rgb(51, 102, 204), hsl(220, 60%, 50%), rebeccapurple or NSColor(red: 0.2, green: 0.4, blue: 0.8, alpha: 1)",
+			)}, nil
+		}
+	}
+
+	c, format, err := parseColor(text)
+	if err != nil {
+		return []search.SearchResult{errorResult("Not a color", fmt.Sprintf("%s in %s", err.Error(), source))}, nil
+	}
+	slog.Debug("Color search", slog.String("format", format), slog.String("color", c.hex()))
+
+	icon := swatch(c)
+	from := fmt.Sprintf("From %s in %s. Press Enter to copy", format, source)
+
+	results := []search.SearchResult{
+		copyResult(c.hex(), "Hex. "+from, c.hex(), "colors:hex", icon),
+		copyResult(c.rgb(), "CSS rgb(). "+from, c.rgb(), "colors:rgb", icon),
+		copyResult(c.hsl(), "CSS hsl(). "+from, c.hsl(), "colors:hsl", icon),
+		copyResult(c.nsColor(), "NSColor with channels from 0 to 1. "+from, c.nsColor(), "colors:nscolor", icon),
+	}
+
+	name, exact := c.name()
+	if exact {
+		results = append(results, copyResult("CSS name: "+name, "Named color. "+from, name, "colors:name", icon))
+	} else {
+		nearest := fromHex(cssNames[name])
+		results = append(results, copyResult(
+			fmt.Sprintf("Nearest CSS name: %s (%s)", name, nearest.hex()),
+			"The named color closest to "+c.hex()+". Press Enter to copy the name",
+			name,
+			"colors:name",
+			swatch(nearest),
+		))
+	}
+
+	results = append(results,
+		contrastResult(c, white, "white"),
+		contrastResult(c, black, "black"),
+	)
+	return results, nil
+}
+
+// contrastResult creates a result for the WCAG contrast ratio of the color
+// on a background, and the levels it passes for text
+func contrastResult(c, background rgba, backgroundName string) search.SearchResult {
+	ratio := c.contrast(background)
+	ratioText := fmt.Sprintf("%s:1", formatFloat(ratio, 2))
+
+	return copyResult(
+		fmt.Sprintf("Contrast on %s: %s, %s", backgroundName, ratioText, contrastLevel(ratio)),
+		fmt.Sprintf("For text in this color on %s, or %s text on it. AAA needs 7:1, AA 4.5:1 and AA for large text 3:1. Press Enter to copy the ratio", backgroundName, backgroundName),
+		ratioText,
+		"colors:contrast:"+backgroundName,
+		contrastSwatch(c, background),
+	)
+}
+
+// contrastLevel returns the highest WCAG level a contrast ratio passes for text
+func contrastLevel(ratio float64) string {
+	switch {
+	case ratio >= 7:
+		return "passes AAA"
+	case ratio >= 4.5:
+		return "passes AA"
+	case ratio >= 3:
+		return "passes AA for large text only"
+	default:
+		return "fails AA"
+	}
+}
+
+// copyResult creates a result that copies text to the clipboard when chosen
+func copyResult(title, description, text, path string, icon fyne.Resource) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        path,
+		Icon:        icon,
+		Action: func() {
+			if err := util.CopyToClipboard(text); err != nil {
+				slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// errorResult creates a result explaining why the color can't be read
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "colors:error",
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeSystem {
+		return errors.New("not a color result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
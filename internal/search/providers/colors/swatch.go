@@ -0,0 +1,68 @@
+package colors
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/ui/icons"
+)
+
+const (
+	// swatchSize is the width and height of swatch icons in pixels
+	swatchSize = 64
+	// checkerSize is the size of the squares shown behind transparent colors
+	checkerSize = 8
+)
+
+// swatch creates an icon filled with a color. Transparent colors are drawn
+// over a checkerboard so their transparency shows.
+func swatch(c rgba) fyne.Resource {
+	img := image.NewNRGBA(image.Rect(0, 0, swatchSize, swatchSize))
+	for y := 0; y < swatchSize; y++ {
+		for x := 0; x < swatchSize; x++ {
+			background := rgba{r: 1, g: 1, b: 1, a: 1}
+			if (x/checkerSize+y/checkerSize)%2 == 1 {
+				background = rgba{r: 0.8, g: 0.8, b: 0.8, a: 1}
+			}
+			img.Set(x, y, toNRGBA(c.over(background)))
+		}
+	}
+	return resource(img, "swatch-"+c.hex())
+}
+
+// contrastSwatch creates an icon showing a color on a background, as text
+// in the color would look
+func contrastSwatch(c, background rgba) fyne.Resource {
+	img := image.NewNRGBA(image.Rect(0, 0, swatchSize, swatchSize))
+	inner := image.Rect(swatchSize/4, swatchSize/4, swatchSize*3/4, swatchSize*3/4)
+	for y := 0; y < swatchSize; y++ {
+		for x := 0; x < swatchSize; x++ {
+			if image.Pt(x, y).In(inner) {
+				img.Set(x, y, toNRGBA(c.over(background)))
+			} else {
+				img.Set(x, y, toNRGBA(background))
+			}
+		}
+	}
+	return resource(img, fmt.Sprintf("swatch-%s-on-%s", c.hex(), background.hex()))
+}
+
+// toNRGBA converts a color to the image package's
+func toNRGBA(c rgba) color.NRGBA {
+	r, g, b, a := c.bytes()
+	return color.NRGBA{R: r, G: g, B: b, A: a}
+}
+
+// resource encodes a swatch image, falling back to the palette icon
+func resource(img image.Image, name string) fyne.Resource {
+	res, err := icons.CreateResourceFromImage(img, name+".png")
+	if err != nil {
+		slog.Error("Failed to create color swatch", slog.String("name", name), slog.Any("error", err))
+		return theme.ColorPaletteIcon()
+	}
+	return res
+}
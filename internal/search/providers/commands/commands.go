@@ -14,6 +14,7 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
 	"github.com/lithammer/fuzzysearch/fuzzy"
 )
 
@@ -286,16 +287,14 @@ func (p *Provider) executeShellCommand(command string) {
 
 // openURL opens a URL in the default browser
 func (p *Provider) openURL(url string) {
-	cmd := exec.Command("open", url)
-	if err := cmd.Run(); err != nil {
+	if err := opener.Open(url); err != nil {
 		slog.Error("failed to open URL", slog.String("url", url), slog.Any("error", err))
 	}
 }
 
 // openApplication opens an application
 func (p *Provider) openApplication(path string) {
-	cmd := exec.Command("open", path)
-	if err := cmd.Run(); err != nil {
+	if err := opener.Open(path); err != nil {
 		slog.Error("failed to open application", slog.String("path", path), slog.Any("error", err))
 	}
 }
@@ -0,0 +1,253 @@
+package dataformat
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"golang.design/x/clipboard"
+)
+
+var _ search.Provider = (*Provider)(nil)
+
+// maxPreviewLength is the number of characters of output shown in results
+const maxPreviewLength = 60
+
+// Provider is a search provider for JSON, YAML and TOML. It pretty prints,
+// minifies, validates, sorts and converts between them the text after the
+// keyword, as in "json {"a": 1}", or the clipboard if there is none, and
+// picks values out with paths such as "json .items[0].name".
+type Provider struct {
+	priority int
+}
+
+// NewProvider creates a new JSON, YAML and TOML provider
+func NewProvider(priority int) (*Provider, error) {
+	err := clipboard.Init()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+
+	return &Provider{
+		priority: priority,
+	}, nil
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "JSON, YAML & TOML"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// splitQuery splits a query into the format its keyword names and the text
+// after the keyword
+func splitQuery(query string) (*format, string, bool) {
+	keyword, text, _ := strings.Cut(strings.TrimLeft(query, " \t"), " ")
+	f, ok := formatKeywords[strings.ToLower(keyword)]
+	return f, strings.TrimSpace(text), ok
+}
+
+// CanHandle returns whether the query starts with json, yaml, yml or toml
+func (p *Provider) CanHandle(query string) bool {
+	_, _, ok := splitQuery(query)
+	return ok
+}
+
+// Search formats and converts the text after the keyword or the clipboard
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	preferred, text, ok := splitQuery(query)
+	if !ok {
+		return nil, nil
+	}
+
+	var steps []step
+	if isPathStart(text) {
+		var err error
+		steps, text, err = parsePath(text)
+		if err != nil {
+			return []search.SearchResult{errorResult("Invalid path", err.Error())}, nil
+		}
+	}
+
+	source := "query"
+	if text == "" {
+		source = "clipboard"
+		text = strings.TrimSpace(string(clipboard.Read(clipboard.FmtText)))
+	}
+	slog.Debug("Data format provider search",
+		slog.String("format", preferred.name),
+		slog.String("source", source),
+		slog.Int("inputLength", len(text)))
+
+	if text == "" {
+		return []search.SearchResult{errorResult(
+			"Nothing to format",
+			fmt.Sprintf("Type %s after the keyword or copy some to the clipboard", preferred.name),
+		)}, nil
+	}
+
+	f, v, err := detectFormat(text, preferred)
+	if err != nil {
+		return []search.SearchResult{errorResult("Invalid "+preferred.name, fmt.Sprintf("%s in %s", err.Error(), source))}, nil
+	}
+
+	if steps != nil {
+		return pathResults(v, steps, source), nil
+	}
+	return formatResults(text, f, v, source), nil
+}
+
+// formatResults creates a result for each way the input can be reformatted
+// or converted
+func formatResults(text string, f *format, v any, source string) []search.SearchResult {
+	valid := fmt.Sprintf("Valid %s %s from %s", f.name, describe(v), source)
+
+	var results []search.SearchResult
+	add := func(title, kind, path string, output string, err error) {
+		if err != nil {
+			slog.Debug("Data format conversion failed", slog.String("result", title), slog.Any("error", err))
+			results = append(results, search.SearchResult{
+				Title:       fmt.Sprintf("Can't write as %s", kind),
+				Description: err.Error(),
+				Type:        search.TypeSystem,
+				Path:        path,
+				Icon:        theme.ErrorIcon(),
+			})
+			return
+		}
+		results = append(results, result(fmt.Sprintf("%s: %s", title, preview(output)), valid+". Press Enter to copy", output, path))
+	}
+
+	pretty, err := f.pretty(text)
+	add("Pretty "+f.name, f.name, "dataformat:pretty", pretty, err)
+
+	if f == jsonFormat {
+		minified, err := minifyJSON(text)
+		add("Minified JSON", "JSON", "dataformat:minify", minified, err)
+	} else {
+		minified, err := encodeJSON(v, "")
+		add("Minified JSON", "JSON", "dataformat:minify", minified, err)
+	}
+
+	// TOML output is always sorted, so pretty printing it already sorts it
+	if f != tomlFormat {
+		sorted, err := f.marshal(v)
+		add(f.name+" with sorted keys", f.name, "dataformat:sorted", sorted, err)
+	}
+
+	for _, target := range []*format{jsonFormat, yamlFormat, tomlFormat} {
+		if target == f {
+			continue
+		}
+		converted, err := target.marshal(v)
+		add("As "+target.name, target.name, "dataformat:"+strings.ToLower(target.name), converted, err)
+	}
+
+	return results
+}
+
+// pathResults creates a result for each value a path selects
+func pathResults(v any, steps []step, source string) []search.SearchResult {
+	path := formatPath(steps)
+
+	values, err := evaluate(v, steps)
+	if err != nil {
+		return []search.SearchResult{errorResult("No value at "+path, fmt.Sprintf("%s in %s", err.Error(), source))}
+	}
+	if len(values) == 0 {
+		return []search.SearchResult{errorResult("No value at "+path, "The path selects nothing in "+source)}
+	}
+
+	var results []search.SearchResult
+	lines := make([]string, 0, len(values))
+	for i, value := range values {
+		text, err := valueText(value)
+		if err != nil {
+			return []search.SearchResult{errorResult("Can't show the value at "+path, err.Error())}
+		}
+		lines = append(lines, text)
+
+		results = append(results, result(
+			fmt.Sprintf("%s = %s", path, preview(text)),
+			fmt.Sprintf("%s from %s. Press Enter to copy", describe(value), source),
+			text,
+			fmt.Sprintf("dataformat:path:%d", i),
+		))
+	}
+
+	// A path that selects several values can also copy them all, one per line
+	if len(values) > 1 {
+		all := strings.Join(lines, "\n")
+		results = append([]search.SearchResult{result(
+			fmt.Sprintf("%s = %d values", path, len(values)),
+			fmt.Sprintf("All values from %s, one per line. Press Enter to copy", source),
+			all,
+			"dataformat:path:all",
+		)}, results...)
+	}
+
+	return results
+}
+
+// valueText writes a selected value for copying: strings as they are, as
+// jq -r does, and anything else as JSON
+func valueText(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return marshalJSON(v)
+}
+
+// result creates a result that copies text to the clipboard when chosen
+func result(title, description, text, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        path,
+		Icon:        theme.DocumentIcon(),
+		Action: func() {
+			clipboard.Write(clipboard.FmtText, []byte(text))
+		},
+	}
+}
+
+// errorResult creates a result explaining why the input can't be used
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "dataformat:error",
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// preview shortens text to fit on one line of a result
+func preview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > maxPreviewLength {
+		text = string([]rune(text)[:maxPreviewLength]) + "…"
+	}
+	return text
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
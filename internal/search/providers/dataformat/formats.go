@@ -0,0 +1,323 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// format is a structured text format that can be parsed and written
+type format struct {
+	name string
+	// parse decodes text, with errors giving where in the text they are
+	parse func(text string) (any, error)
+	// pretty reformats text, keeping the order of its keys where the format allows
+	pretty func(text string) (string, error)
+	// marshal writes a value with its keys sorted
+	marshal func(v any) (string, error)
+}
+
+var (
+	jsonFormat = &format{name: "JSON", parse: parseJSON, pretty: prettyJSON, marshal: marshalJSON}
+	yamlFormat = &format{name: "YAML", parse: parseYAML, pretty: prettyYAML, marshal: marshalYAML}
+	tomlFormat = &format{name: "TOML", parse: parseTOML, pretty: prettyTOML, marshal: marshalTOML}
+)
+
+// formats are the formats in the order input is tried as them. YAML is last
+// as JSON is also YAML and most text is a YAML string.
+var formats = []*format{jsonFormat, tomlFormat, yamlFormat}
+
+// formatKeywords maps each keyword to the format it names
+var formatKeywords = map[string]*format{
+	"json": jsonFormat,
+	"yaml": yamlFormat,
+	"yml":  yamlFormat,
+	"toml": tomlFormat,
+}
+
+// detectFormat parses text, preferring the given format. It returns the
+// error from the preferred format if text isn't in any of them.
+func detectFormat(text string, preferred *format) (*format, any, error) {
+	v, preferredErr := preferred.parse(text)
+	if preferredErr == nil {
+		return preferred, v, nil
+	}
+
+	// Text that looks like JSON isn't read as YAML unless asked for, so that
+	// mistakes in it are reported rather than hidden
+	looksLikeJSON := strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")
+
+	for _, f := range formats {
+		if f == preferred || (f == yamlFormat && looksLikeJSON) {
+			continue
+		}
+		if v, err := f.parse(text); err == nil {
+			return f, v, nil
+		}
+	}
+
+	return nil, nil, preferredErr
+}
+
+// parseJSON parses JSON, keeping numbers as written
+func parseJSON(text string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, jsonError(text, err)
+	}
+	if decoder.More() {
+		return nil, jsonError(text, fmt.Errorf("unexpected text after the value"))
+	}
+	return normalize(v), nil
+}
+
+// jsonError adds the line and column of a JSON error
+func jsonError(text string, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	offset := int64(-1)
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		offset = int64(len(text))
+		err = fmt.Errorf("unexpected end of input")
+	}
+
+	if offset < 0 {
+		return err
+	}
+	line, column := position(text, int(offset))
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+// position returns the line and column of a byte offset, both counted from 1
+func position(text string, offset int) (int, int) {
+	offset = min(offset, len(text))
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+	return line, column
+}
+
+// prettyJSON indents JSON, keeping its keys in order
+func prettyJSON(text string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(text)), "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// minifyJSON removes the whitespace from JSON, keeping its keys in order
+func minifyJSON(text string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(text)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// marshalJSON writes a value as indented JSON. Characters such as < and &
+// are kept as they are rather than escaped.
+func marshalJSON(v any) (string, error) {
+	return encodeJSON(v, "  ")
+}
+
+// encodeJSON writes a value as JSON, indented if indent isn't empty
+func encodeJSON(v any, indent string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// parseYAML parses a YAML document. Only mappings and sequences count as
+// YAML, as any other text would be read as a string.
+func parseYAML(text string) (any, error) {
+	var v any
+	if err := yaml.Unmarshal([]byte(text), &v); err != nil {
+		return nil, err
+	}
+
+	switch v.(type) {
+	case map[string]any, map[any]any, []any:
+		return normalize(v), nil
+	}
+	return nil, fmt.Errorf("yaml: not a mapping or sequence")
+}
+
+// prettyYAML reindents YAML, keeping its keys in order and its comments
+func prettyYAML(text string) (string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+		return "", err
+	}
+	return encodeYAML(&node)
+}
+
+// marshalYAML writes a value as YAML
+func marshalYAML(v any) (string, error) {
+	return encodeYAML(v)
+}
+
+// encodeYAML writes a value or node as YAML indented by two spaces
+func encodeYAML(v any) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// parseTOML parses a TOML document
+func parseTOML(text string) (any, error) {
+	var v map[string]any
+	if _, err := toml.Decode(text, &v); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			line, column := position(text, parseErr.Position.Start)
+			return nil, fmt.Errorf("line %d, column %d: %s", line, column, tomlMessage(parseErr))
+		}
+		return nil, err
+	}
+	return normalize(v), nil
+}
+
+// tomlMessage returns a TOML parse error's message without the line it's
+// on, which only Error includes for some errors
+func tomlMessage(err toml.ParseError) string {
+	if err.Message != "" {
+		return err.Message
+	}
+
+	prefix := fmt.Sprintf("toml: line %d: ", err.Position.Line)
+	if err.LastKey != "" {
+		prefix = fmt.Sprintf("toml: line %d (last key %q): ", err.Position.Line, err.LastKey)
+	}
+	return strings.TrimPrefix(err.Error(), prefix)
+}
+
+// prettyTOML rewrites TOML. Its keys come out sorted, as the order isn't kept
+// when it's parsed.
+func prettyTOML(text string) (string, error) {
+	v, err := parseTOML(text)
+	if err != nil {
+		return "", err
+	}
+	return marshalTOML(v)
+}
+
+// marshalTOML writes a value as TOML, which must be a table at the top level
+// and can't contain nulls
+func marshalTOML(v any) (string, error) {
+	if _, ok := v.(map[string]any); !ok {
+		return "", fmt.Errorf("TOML documents must be a table at the top level")
+	}
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = "  "
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// normalize converts decoded values to the types every format can write:
+// maps with string keys, []any, strings, booleans, nil, int64, float64 and
+// the times TOML has
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalize(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+		return items
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return f
+		}
+		return string(v)
+	case int:
+		return int64(v)
+	case uint64:
+		if v <= 1<<63-1 {
+			return int64(v)
+		}
+		return float64(v)
+	}
+	return v
+}
+
+// describe summarises a value, e.g. "object with 3 keys"
+func describe(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return plural(len(v), "object with %d key")
+	case []any:
+		return plural(len(v), "array of %d item")
+	case string:
+		return "string"
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "date and time"
+	default:
+		return "number"
+	}
+}
+
+// plural formats a count into a phrase, adding an s unless it is one
+func plural(n int, phrase string) string {
+	text := fmt.Sprintf(phrase, n)
+	if n != 1 {
+		text += "s"
+	}
+	return text
+}
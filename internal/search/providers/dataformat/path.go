@@ -0,0 +1,238 @@
+package dataformat
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// step is one part of a path: a key, an index, or every item
+type step struct {
+	key   string
+	index int
+	// isIndex is set for array indexes such as [0] or [-1]
+	isIndex bool
+	// all is set for [] and [*], which select every item of an array or object
+	all bool
+}
+
+// String writes the step as it appears in a path
+func (s step) String() string {
+	switch {
+	case s.all:
+		return "[]"
+	case s.isIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	case isIdentifier(s.key):
+		return "." + s.key
+	default:
+		return "[" + strconv.Quote(s.key) + "]"
+	}
+}
+
+// isPathStart reports whether text starts with a path rather than input
+func isPathStart(text string) bool {
+	return strings.HasPrefix(text, ".") || strings.HasPrefix(text, "$")
+}
+
+// parsePath parses a jq or JSONPath style path such as .items[0].name,
+// $.items[*].name or .["key with spaces"]. It stops at whitespace outside
+// quotes, returning the text after the path.
+func parsePath(text string) ([]step, string, error) {
+	var steps []step
+	i := 0
+	if strings.HasPrefix(text, "$") {
+		i++
+	}
+
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			break
+		}
+
+		switch {
+		case text[i] == '.' && i+1 < len(text) && text[i+1] == '*':
+			steps = append(steps, step{all: true})
+			i += 2
+		case text[i] == '.':
+			i++
+			if i < len(text) && text[i] == '"' {
+				key, n, err := readQuoted(text[i:])
+				if err != nil {
+					return nil, "", err
+				}
+				steps = append(steps, step{key: key})
+				i += n
+				continue
+			}
+
+			start := i
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !isIdentifierRune(r) {
+					break
+				}
+				i += size
+			}
+			if i > start {
+				steps = append(steps, step{key: text[start:i]})
+			}
+		case text[i] == '[':
+			end := strings.IndexByte(text[i:], ']')
+			inner := ""
+			if end > 0 {
+				inner = strings.TrimSpace(text[i+1 : i+end])
+			}
+
+			switch {
+			case strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, "'"):
+				key, n, err := readQuoted(text[i+1:])
+				if err != nil {
+					return nil, "", err
+				}
+				if i+1+n >= len(text) || text[i+1+n] != ']' {
+					return nil, "", fmt.Errorf("expected ] after %s", text[i:i+1+n])
+				}
+				steps = append(steps, step{key: key})
+				i += n + 2
+			case end < 0:
+				return nil, "", fmt.Errorf("missing ] in path")
+			case inner == "" || inner == "*":
+				steps = append(steps, step{all: true})
+				i += end + 1
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, "", fmt.Errorf("invalid index %q", inner)
+				}
+				steps = append(steps, step{index: index, isIndex: true})
+				i += end + 1
+			}
+		default:
+			if r == utf8.RuneError && size == 1 {
+				return nil, "", fmt.Errorf("invalid UTF-8 in path")
+			}
+			return nil, "", fmt.Errorf("unexpected %q in path", r)
+		}
+	}
+
+	return steps, strings.TrimSpace(text[i:]), nil
+}
+
+// readQuoted reads a single or double quoted key at the start of text,
+// returning it and the length of the quoted text
+func readQuoted(text string) (string, int, error) {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case quote:
+			if quote == '\'' {
+				return text[1:i], i + 1, nil
+			}
+			key, err := strconv.Unquote(text[:i+1])
+			return key, i + 1, err
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted key")
+}
+
+// isIdentifierRune reports whether r can be in a key written after a dot
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isIdentifier reports whether a key can be written after a dot
+func isIdentifier(key string) bool {
+	return key != "" && strings.IndexFunc(key, func(r rune) bool { return !isIdentifierRune(r) }) < 0
+}
+
+// evaluate returns the values a path selects. A path with [] can select
+// more than one value.
+func evaluate(v any, steps []step) ([]any, error) {
+	values := []any{v}
+
+	for i, s := range steps {
+		var next []any
+		for _, value := range values {
+			selected, err := s.apply(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatPath(steps[:i+1]), err)
+			}
+			next = append(next, selected...)
+		}
+		values = next
+	}
+
+	return values, nil
+}
+
+// apply selects the values a step picks from v
+func (s step) apply(v any) ([]any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		switch {
+		case s.all:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			items := make([]any, len(keys))
+			for i, k := range keys {
+				items[i] = v[k]
+			}
+			return items, nil
+		case s.isIndex:
+			return nil, fmt.Errorf("can't index an object")
+		}
+
+		item, ok := v[s.key]
+		if !ok {
+			return nil, fmt.Errorf("no key %q", s.key)
+		}
+		return []any{item}, nil
+	case []any:
+		switch {
+		case s.all:
+			return v, nil
+		case !s.isIndex:
+			return nil, fmt.Errorf("can't get key %q of an array", s.key)
+		}
+
+		index := s.index
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return nil, fmt.Errorf("index %d out of range for %s", s.index, describe(v))
+		}
+		return []any{v[index]}, nil
+	}
+
+	return nil, fmt.Errorf("can't look inside a %s", describe(v))
+}
+
+// formatPath writes steps as a path, e.g. .items[0].name
+func formatPath(steps []step) string {
+	if len(steps) == 0 {
+		return "."
+	}
+
+	var b strings.Builder
+	for _, s := range steps {
+		b.WriteString(s.String())
+	}
+
+	// jq paths always start with a dot, as in .[0]
+	if path := b.String(); !strings.HasPrefix(path, ".") {
+		return "." + path
+	}
+	return b.String()
+}
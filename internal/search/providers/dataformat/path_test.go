@@ -0,0 +1,109 @@
+package dataformat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  []step
+		rest  string
+		round string
+	}{
+		{".", nil, "", "."},
+		{".name", []step{{key: "name"}}, "", ".name"},
+		{".items[0].name", []step{{key: "items"}, {index: 0, isIndex: true}, {key: "name"}}, "", ".items[0].name"},
+		{"$.items[*].id", []step{{key: "items"}, {all: true}, {key: "id"}}, "", ".items[].id"},
+		{".items[]", []step{{key: "items"}, {all: true}}, "", ".items[]"},
+		{".*", []step{{all: true}}, "", ".[]"},
+		{".[-1]", []step{{index: -1, isIndex: true}}, "", ".[-1]"},
+		{`.["key with spaces"]`, []step{{key: "key with spaces"}}, "", `.["key with spaces"]`},
+		{`.['single']`, []step{{key: "single"}}, "", ".single"},
+		{`."quoted.key"`, []step{{key: "quoted.key"}}, "", `.["quoted.key"]`},
+		{".snake_case-key", []step{{key: "snake_case-key"}}, "", ".snake_case-key"},
+		{".café", []step{{key: "café"}}, "", ".café"},
+		{".Åse.名前", []step{{key: "Åse"}, {key: "名前"}}, "", ".Åse.名前"},
+		{".name {\"name\": 1}", []step{{key: "name"}}, `{"name": 1}`, ".name"},
+		{".café rest", []step{{key: "café"}}, "rest", ".café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			steps, rest, err := parsePath(tt.text)
+			if err != nil {
+				t.Fatalf("parsePath(%q) error = %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(steps, tt.want) || rest != tt.rest {
+				t.Errorf("parsePath(%q) = %+v, %q, want %+v, %q", tt.text, steps, rest, tt.want, tt.rest)
+			}
+			if got := formatPath(steps); got != tt.round {
+				t.Errorf("formatPath(%q) = %q, want %q", tt.text, got, tt.round)
+			}
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{".items[0", "missing ] in path"},
+		{".items[x]", `invalid index "x"`},
+		{`.["key"x]`, `expected ] after ["key"`},
+		{`."open`, "unterminated quoted key"},
+		{".a!b", `unexpected '!' in path`},
+		{".a©", `unexpected '©' in path`},
+		{".a\xff", "invalid UTF-8 in path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			_, _, err := parsePath(tt.text)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parsePath(%q) error = %v, want %q", tt.text, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatePath(t *testing.T) {
+	v := map[string]any{
+		"café":  "latte",
+		"items": []any{map[string]any{"id": 1.0}, map[string]any{"id": 2.0}},
+	}
+
+	tests := []struct {
+		path string
+		want []any
+		err  string
+	}{
+		{".café", []any{"latte"}, ""},
+		{".items[].id", []any{1.0, 2.0}, ""},
+		{".items[-1].id", []any{2.0}, ""},
+		{".items[2]", nil, ".items[2]: index 2 out of range for array of 2 items"},
+		{".items.id", nil, `.items.id: can't get key "id" of an array`},
+		{".Åse", nil, `.Åse: no key "Åse"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			steps, _, err := parsePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := evaluate(v, steps)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("evaluate(%s) error = %v, want %q", tt.path, err, tt.err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluate(%s) = %v, %v, want %v", tt.path, got, err, tt.want)
+			}
+		})
+	}
+}
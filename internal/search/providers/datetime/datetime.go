@@ -0,0 +1,326 @@
+package datetime
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+// Provider is a search provider for dates, times and Unix timestamps. It
+// converts them between formats and does date arithmetic such as
+// "today + 45 days" or "2026-12-25 - now".
+type Provider struct {
+	priority int
+	location *time.Location
+	// zones are extra time zones results are shown in
+	zones []*time.Location
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithLocation sets the time zone dates without one are read in and results
+// are shown in. The default is the local time zone.
+func WithLocation(location *time.Location) Option {
+	return func(p *Provider) {
+		p.location = location
+	}
+}
+
+// WithZones adds time zones, such as "America/New_York", that results are
+// also shown in. Unknown zones are logged and skipped.
+func WithZones(names ...string) Option {
+	return func(p *Provider) {
+		for _, name := range names {
+			zone, err := time.LoadLocation(name)
+			if err != nil {
+				slog.Error("Failed to load time zone", slog.String("zone", name), slog.Any("error", err))
+				continue
+			}
+			p.zones = append(p.zones, zone)
+		}
+	}
+}
+
+// WithClock replaces the clock "now" is read from
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		p.now = now
+	}
+}
+
+// NewProvider creates a new date and time provider
+func NewProvider(priority int, opts ...Option) *Provider {
+	p := &Provider{
+		priority: priority,
+		location: time.Local,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Date & Time"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeCalculator
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// parser returns a parser for the current time
+func (p *Provider) parser() parser {
+	return parser{now: p.now().In(p.location), location: p.location}
+}
+
+// CanHandle returns whether the query involves a date or time
+func (p *Provider) CanHandle(query string) bool {
+	_, err := p.parser().parseExpression(query)
+	return err != errNotDate
+}
+
+// Search converts or calculates the date expression in the query
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	query = strings.TrimSpace(query)
+
+	parser := p.parser()
+	v, err := parser.parseExpression(query)
+	if err == errNotDate {
+		return nil, nil
+	}
+	if err != nil {
+		return []search.SearchResult{
+			{
+				Title:       fmt.Sprintf("Error: %s", err.Error()),
+				Description: "Could not calculate the date",
+				Path:        "datetime:error",
+				Icon:        theme.ErrorIcon(),
+				Type:        search.TypeCalculator,
+			},
+		}, nil
+	}
+
+	if v.isTime {
+		return p.timeResults(query, v, parser.now), nil
+	}
+	return p.durationResults(query, v.diff), nil
+}
+
+// timeResults creates a result for each format and time zone a time is shown in
+func (p *Provider) timeResults(query string, v value, now time.Time) []search.SearchResult {
+	t := v.time.In(p.location)
+
+	local := t.Format("Mon, 2 Jan 2006 15:04:05 MST")
+	if v.dateOnly {
+		local = t.Format("Monday, 2 January 2006")
+	}
+
+	results := []search.SearchResult{
+		p.result(fmt.Sprintf("%s = %s", query, local), local, "Local time", "datetime:local"),
+	}
+
+	if v.dateOnly {
+		date := t.Format(time.DateOnly)
+		results = append(results, p.result(date, date, "ISO 8601 date", "datetime:date"))
+	}
+
+	rfc3339 := t.Format(time.RFC3339)
+	utc := t.UTC().Format(time.RFC3339)
+	results = append(results, p.result(rfc3339, rfc3339, "RFC 3339", "datetime:rfc3339"))
+	if utc != rfc3339 {
+		results = append(results, p.result(utc, utc, "RFC 3339 in UTC", "datetime:utc"))
+	}
+
+	unix := strconv.FormatInt(t.Unix(), 10)
+	millis := strconv.FormatInt(t.UnixMilli(), 10)
+	results = append(results,
+		p.result(unix, unix, "Unix timestamp in seconds", "datetime:unix"),
+		p.result(millis, millis, "Unix timestamp in milliseconds", "datetime:unix-ms"),
+	)
+
+	for _, zone := range p.zones {
+		inZone := t.In(zone)
+		text := inZone.Format("Mon, 2 Jan 2006 15:04 MST")
+		results = append(results, p.result(text, text, fmt.Sprintf("%s (UTC%s)", zone, inZone.Format("-07:00")), "datetime:zone:"+zone.String()))
+	}
+
+	relative := formatRelative(difference(t, now))
+	if v.dateOnly {
+		relative = formatRelativeDays(t, now)
+	}
+	results = append(results, p.result(relative, relative, "Relative to now", "datetime:relative"))
+
+	return results
+}
+
+// durationResults creates results for the difference between two times,
+// in words and as totals of days, hours and seconds
+func (p *Provider) durationResults(query string, d span) []search.SearchResult {
+	words := formatDuration(d)
+	days := formatTotal(float64(d.days)+d.clock.Hours()/24, "day")
+	hours := formatTotal(float64(d.days)*24+d.clock.Hours(), "hour")
+	seconds := formatTotal(float64(d.days)*86400+d.clock.Seconds(), "second")
+
+	return []search.SearchResult{
+		p.result(fmt.Sprintf("%s = %s", query, words), words, "Difference", "datetime:duration"),
+		p.result(days, strings.Fields(days)[0], "Total days", "datetime:days"),
+		p.result(hours, strings.Fields(hours)[0], "Total hours", "datetime:hours"),
+		p.result(seconds, strings.Fields(seconds)[0], "Total seconds", "datetime:seconds"),
+	}
+}
+
+// result creates a result that copies text when chosen
+func (p *Provider) result(title, text, kind, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: kind + ". Press Enter to copy",
+		Path:        path,
+		Icon:        theme.HistoryIcon(),
+		Type:        search.TypeCalculator,
+		Action: func() {
+			if err := util.CopyToClipboard(text); err != nil {
+				slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeCalculator {
+		return fmt.Errorf("not a date result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+
+	return nil
+}
+
+// plural formats a count with its unit, e.g. "1 day" or "3 days"
+func plural(n int64, unit string) string {
+	if n == 1 || n == -1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// formatDuration writes a difference in words, e.g. "68 days, 3 hours, 4 minutes"
+func formatDuration(s span) string {
+	s = s.normalize()
+	sign := ""
+	if s.days < 0 || s.clock < 0 {
+		sign, s = "-", s.neg()
+	}
+
+	parts := []string{}
+	if s.days > 0 {
+		parts = append(parts, plural(int64(s.days), "day"))
+	}
+
+	d := s.clock
+	for _, unit := range []struct {
+		name   string
+		length time.Duration
+	}{
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	} {
+		if n := d / unit.length; n > 0 {
+			parts = append(parts, plural(int64(n), unit.name))
+			d -= n * unit.length
+		}
+	}
+
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
+	return sign + strings.Join(parts, ", ")
+}
+
+// formatTotal formats a total with up to two decimals, e.g. "68.13 days".
+// The unit is plural unless the total rounds to exactly one.
+func formatTotal(total float64, unit string) string {
+	rounded := math.Round(total*100) / 100
+	text := strconv.FormatFloat(rounded, 'f', -1, 64)
+	if rounded == 1 || rounded == -1 {
+		return text + " " + unit
+	}
+	return text + " " + unit + "s"
+}
+
+// formatRelative describes an offset from now, e.g. "in 5 minutes" or "3 days ago"
+func formatRelative(s span) string {
+	s = s.normalize()
+	negative := s.days < 0 || s.clock < 0
+	if negative {
+		s = s.neg()
+	}
+	days, clock := int64(s.days), s.clock
+	var text string
+
+	switch {
+	case days == 0 && clock < time.Minute:
+		return "now"
+	case days == 0 && clock < time.Hour:
+		text = plural(int64(clock/time.Minute), "minute")
+	case days == 0:
+		text = plural(int64(clock/time.Hour), "hour")
+	case days < 30:
+		text = plural(days, "day")
+	case days < 365:
+		text = plural(days/30, "month")
+	default:
+		text = plural(days/365, "year")
+	}
+
+	if negative {
+		return text + " ago"
+	}
+	return "in " + text
+}
+
+// formatRelativeDays describes a day relative to today, e.g. "tomorrow" or "in 45 days"
+func formatRelativeDays(day, now time.Time) string {
+	// Count calendar days in UTC, as local days aren't all 24 hours long
+	// across DST changes
+	y1, m1, d1 := day.Date()
+	y2, m2, d2 := now.Date()
+	days := int64(difference(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC), time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)).days)
+
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	case -1:
+		return "yesterday"
+	}
+
+	if days < 0 {
+		return plural(-days, "day") + " ago"
+	}
+	return "in " + plural(days, "day")
+}
@@ -0,0 +1,158 @@
+package datetime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTotal(t *testing.T) {
+	tests := []struct {
+		total float64
+		unit  string
+		want  string
+	}{
+		{1, "day", "1 day"},
+		{-1, "day", "-1 day"},
+		{0, "hour", "0 hours"},
+		{2, "hour", "2 hours"},
+		{1.5, "day", "1.5 days"},
+		{1.004, "day", "1 day"},
+		{0.999, "day", "1 day"},
+		{68.126, "day", "68.13 days"},
+		{-3600, "second", "-3600 seconds"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTotal(tt.total, tt.unit); got != tt.want {
+			t.Errorf("formatTotal(%v, %q) = %q, want %q", tt.total, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    span
+		want string
+	}{
+		{span{}, "0 seconds"},
+		{span{clock: time.Second}, "1 second"},
+		{span{clock: 90 * time.Minute}, "1 hour, 30 minutes"},
+		{span{clock: 68*24*time.Hour + 3*time.Hour + 4*time.Minute}, "68 days, 3 hours, 4 minutes"},
+		{span{days: 68, clock: 3*time.Hour + 4*time.Minute}, "68 days, 3 hours, 4 minutes"},
+		{span{clock: -(24*time.Hour + time.Second)}, "-1 day, 1 second"},
+		{span{days: -1, clock: -time.Second}, "-1 day, 1 second"},
+		{span{days: 730485}, "730485 days"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	tests := []struct {
+		d    span
+		want string
+	}{
+		{span{clock: 30 * time.Second}, "now"},
+		{span{clock: 5 * time.Minute}, "in 5 minutes"},
+		{span{clock: -time.Hour}, "1 hour ago"},
+		{span{clock: 3 * 24 * time.Hour}, "in 3 days"},
+		{span{days: -60}, "2 months ago"},
+		{span{days: 800}, "in 2 years"},
+		{span{days: 365 * 3000}, "in 3000 years"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRelative(tt.d); got != tt.want {
+			t.Errorf("formatRelative(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestSearchDuration(t *testing.T) {
+	p := NewProvider(0, WithLocation(time.UTC), WithClock(func() time.Time { return testNow }))
+
+	results, err := p.Search("tomorrow - today")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"datetime:duration": "tomorrow - today = 1 day",
+		"datetime:days":     "1 day",
+		"datetime:hours":    "24 hours",
+		"datetime:seconds":  "86400 seconds",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Search returned %d results, want %d", len(results), len(want))
+	}
+	for _, r := range results {
+		if r.Title != want[r.Path] {
+			t.Errorf("result %s = %q, want %q", r.Path, r.Title, want[r.Path])
+		}
+	}
+}
+
+func TestSearchLongDuration(t *testing.T) {
+	p := NewProvider(0, WithLocation(time.UTC), WithClock(func() time.Time { return testNow }))
+
+	results, err := p.Search("3000-01-01 - 1000-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"datetime:duration": "3000-01-01 - 1000-01-01 = 730485 days",
+		"datetime:days":     "730485 days",
+		"datetime:hours":    "17531640 hours",
+		"datetime:seconds":  "63113904000 seconds",
+	}
+	for _, r := range results {
+		if r.Title != want[r.Path] {
+			t.Errorf("result %s = %q, want %q", r.Path, r.Title, want[r.Path])
+		}
+	}
+}
+
+func TestSearchTime(t *testing.T) {
+	p := NewProvider(0, WithLocation(time.UTC), WithClock(func() time.Time { return testNow }))
+
+	results, err := p.Search("@1700000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titles := map[string]string{}
+	for _, r := range results {
+		titles[r.Path] = r.Title
+	}
+	want := map[string]string{
+		"datetime:local":    "@1700000000 = Tue, 14 Nov 2023 22:13:20 UTC",
+		"datetime:rfc3339":  "2023-11-14T22:13:20Z",
+		"datetime:unix":     "1700000000",
+		"datetime:unix-ms":  "1700000000000",
+		"datetime:relative": "2 years ago",
+	}
+	for path, title := range want {
+		if titles[path] != title {
+			t.Errorf("result %s = %q, want %q", path, titles[path], title)
+		}
+	}
+
+	results, err = p.Search("9999-12-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Path == "datetime:relative" && r.Title != "in 2912369 days" {
+			t.Errorf("relative result = %q, want %q", r.Title, "in 2912369 days")
+		}
+	}
+
+	if !p.CanHandle("today + 1 week") || p.CanHandle("5 - 3") {
+		t.Error("CanHandle should accept date arithmetic and leave plain numbers to the calculator")
+	}
+}
@@ -0,0 +1,461 @@
+package datetime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNotDate is returned for queries that don't involve a date or time
+var errNotDate = errors.New("not a date expression")
+
+// span is an amount of time to add to a date. Years, months and days are
+// calendar units, so a month from 31 January is 3 March as with time.AddDate.
+type span struct {
+	years, months, days int
+	clock               time.Duration
+}
+
+// neg returns the span pointing the other way
+func (s span) neg() span {
+	return span{years: -s.years, months: -s.months, days: -s.days, clock: -s.clock}
+}
+
+// plus returns the sum of two spans
+func (s span) plus(o span) span {
+	return span{years: s.years + o.years, months: s.months + o.months, days: s.days + o.days, clock: s.clock + o.clock}
+}
+
+// addTo returns t moved by the span
+func (s span) addTo(t time.Time) time.Time {
+	return t.AddDate(s.years, s.months, s.days).Add(s.clock)
+}
+
+// dayLength is the length of the days differences are counted in
+const dayLength = 24 * time.Hour
+
+// difference returns the exact time from b to a as days of 24 hours and the
+// time left over, which has the same sign. The days are counted from Unix
+// seconds, as a time.Duration only reaches about 292 years.
+func difference(a, b time.Time) span {
+	seconds := a.Unix() - b.Unix()
+	days := seconds / 86400
+	rest := time.Duration(seconds%86400)*time.Second + time.Duration(a.Nanosecond()-b.Nanosecond())
+
+	switch {
+	case days > 0 && rest < 0:
+		days, rest = days-1, rest+dayLength
+	case days < 0 && rest > 0:
+		days, rest = days+1, rest-dayLength
+	}
+	return span{days: int(days), clock: rest}
+}
+
+// normalize moves whole days out of the span's clock time, so it holds less
+// than a day with the same sign as the days
+func (s span) normalize() span {
+	s.days += int(s.clock / dayLength)
+	s.clock %= dayLength
+
+	switch {
+	case s.days > 0 && s.clock < 0:
+		s.days, s.clock = s.days-1, s.clock+dayLength
+	case s.days < 0 && s.clock > 0:
+		s.days, s.clock = s.days+1, s.clock-dayLength
+	}
+	return s
+}
+
+// minYear and maxYear are the range of years dates can be in, which is what
+// RFC 3339 can write
+const (
+	minYear = 1
+	maxYear = 9999
+)
+
+// errOutOfRange is returned for dates outside minYear to maxYear
+var errOutOfRange = fmt.Errorf("dates must be between the years %d and %d", minYear, maxYear)
+
+// checkRange returns errOutOfRange if a value is a date outside the supported years
+func checkRange(v value) error {
+	if v.isTime && (v.time.Year() < minYear || v.time.Year() > maxYear) {
+		return errOutOfRange
+	}
+	return nil
+}
+
+// value is the result of a date expression, either a point in time or the
+// difference between two
+type value struct {
+	time time.Time
+	// isTime is set when the value is a point in time rather than a difference
+	isTime bool
+	// dateOnly is set for days without a time of day, such as "today" or 2026-12-25
+	dateOnly bool
+	// diff is the difference between two times, or a span on its own
+	diff span
+}
+
+// parser parses date expressions relative to a fixed time and location
+type parser struct {
+	now      time.Time
+	location *time.Location
+}
+
+// operatorPattern matches + and - between terms. Spaces are required as
+// dates such as 2026-12-25 contain dashes.
+var operatorPattern = regexp.MustCompile(`\s+([+-])\s+`)
+
+// parseExpression parses expressions such as "now", "1700000000",
+// "today + 45 days", "3 days ago" or "2026-12-25 - now". It returns
+// errNotDate when no part of the query is a date or time.
+func (p parser) parseExpression(query string) (value, error) {
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+
+	// "3 days ago" and "in 3 days" are relative to now
+	if rest, ok := strings.CutSuffix(lower, " ago"); ok {
+		if s, err := parseSpan(rest); err == nil {
+			v := value{time: s.neg().addTo(p.now), isTime: true}
+			return v, checkRange(v)
+		}
+	}
+	if rest, ok := strings.CutPrefix(lower, "in "); ok {
+		if s, err := parseSpan(rest); err == nil {
+			v := value{time: s.addTo(p.now), isTime: true}
+			return v, checkRange(v)
+		}
+	}
+
+	terms := []string{}
+	ops := []string{}
+	last := 0
+	for _, m := range operatorPattern.FindAllStringSubmatchIndex(query, -1) {
+		terms = append(terms, query[last:m[0]])
+		ops = append(ops, query[m[2]:m[3]])
+		last = m[1]
+	}
+	terms = append(terms, query[last:])
+
+	// Only expressions with a point in time are handled, so "5 - 3" is left
+	// to the calculator
+	values := make([]*value, len(terms))
+	hasTime, onlyNumbers := false, true
+	for i, term := range terms {
+		if v, ok := p.parseTime(term); ok {
+			values[i] = &v
+			hasTime = true
+			onlyNumbers = onlyNumbers && isBareNumber(term)
+		}
+	}
+	if !hasTime {
+		return value{}, errNotDate
+	}
+
+	// A number that could be a timestamp is only a date when the terms
+	// around it are spans, so "123456789 + 1" is left to the calculator
+	if onlyNumbers {
+		for i, term := range terms {
+			if values[i] == nil {
+				if _, err := parseSpan(term); err != nil {
+					return value{}, errNotDate
+				}
+			}
+		}
+	}
+
+	result, err := p.parseTerm(terms[0], values[0])
+	if err != nil {
+		return value{}, err
+	}
+	if err := checkRange(result); err != nil {
+		return value{}, err
+	}
+
+	for i, op := range ops {
+		operand, err := p.parseTerm(terms[i+1], values[i+1])
+		if err != nil {
+			return value{}, err
+		}
+
+		result, err = combine(result, op, operand)
+		if err != nil {
+			return value{}, err
+		}
+		if err := checkRange(result); err != nil {
+			return value{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// parseTerm returns the already parsed time for a term, or parses it as a span
+func (p parser) parseTerm(term string, parsed *value) (value, error) {
+	if parsed != nil {
+		return *parsed, nil
+	}
+
+	s, err := parseSpan(term)
+	if err != nil {
+		return value{}, err
+	}
+	return value{diff: s}, nil
+}
+
+// combine applies + or - to two values
+func combine(left value, op string, right value) (value, error) {
+	if op == "-" {
+		switch {
+		case left.isTime && right.isTime:
+			// The exact difference, as calendar units vary in length
+			return value{diff: difference(left.time, right.time)}, nil
+		case right.isTime:
+			return value{}, fmt.Errorf("cannot subtract a date from a duration")
+		}
+		right.diff = right.diff.neg()
+	}
+
+	switch {
+	case left.isTime && right.isTime:
+		return value{}, fmt.Errorf("cannot add two dates")
+	case left.isTime:
+		left.time = right.diff.addTo(left.time)
+		left.dateOnly = left.dateOnly && right.diff.clock == 0
+		return left, nil
+	case right.isTime:
+		right.time = left.diff.addTo(right.time)
+		right.dateOnly = right.dateOnly && left.diff.clock == 0
+		return right, nil
+	}
+
+	return value{diff: left.diff.plus(right.diff)}, nil
+}
+
+// timeLayouts are the formats dates and times are parsed with, in the order tried
+var timeLayouts = []struct {
+	layout   string
+	dateOnly bool
+}{
+	{layout: time.RFC3339Nano},
+	{layout: "2006-01-02T15:04:05"},
+	{layout: "2006-01-02T15:04"},
+	{layout: "2006-01-02 15:04:05Z07:00"},
+	{layout: "2006-01-02 15:04:05"},
+	{layout: "2006-01-02 15:04"},
+	{layout: "2006-01-02", dateOnly: true},
+	{layout: "2006/01/02 15:04:05"},
+	{layout: "2006/01/02 15:04"},
+	{layout: "2006/01/02", dateOnly: true},
+	{layout: "2 Jan 2006 15:04"},
+	{layout: "2 Jan 2006", dateOnly: true},
+	{layout: "2 January 2006", dateOnly: true},
+	{layout: "Jan 2 2006 15:04"},
+	{layout: "Jan 2 2006", dateOnly: true},
+	{layout: "Jan 2, 2006", dateOnly: true},
+	{layout: "January 2 2006", dateOnly: true},
+	{layout: "January 2, 2006", dateOnly: true},
+	{layout: time.RFC1123},
+	{layout: time.RFC1123Z},
+	{layout: time.RFC850},
+	{layout: time.RFC822},
+	{layout: time.RFC822Z},
+	{layout: time.UnixDate},
+	{layout: time.RubyDate},
+	{layout: time.ANSIC},
+}
+
+// bareNumberPattern matches numbers without an @ or a unit, which are
+// timestamps only in the context of a date expression
+var bareNumberPattern = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+
+// isBareNumber reports whether a term is a plain number
+func isBareNumber(term string) bool {
+	return bareNumberPattern.MatchString(strings.TrimSpace(term))
+}
+
+// epochPattern matches Unix timestamps, optionally with a fraction or an @ prefix
+var epochPattern = regexp.MustCompile(`^(@?)(\d+)(?:\.(\d{1,9}))?$`)
+
+// parseTime parses a keyword such as "now", a Unix timestamp or a date in
+// one of timeLayouts
+func (p parser) parseTime(term string) (value, bool) {
+	term = strings.TrimSpace(term)
+	today := startOfDay(p.now)
+
+	switch strings.ToLower(term) {
+	case "now":
+		return value{time: p.now, isTime: true}, true
+	case "today":
+		return value{time: today, isTime: true, dateOnly: true}, true
+	case "tomorrow":
+		return value{time: today.AddDate(0, 0, 1), isTime: true, dateOnly: true}, true
+	case "yesterday":
+		return value{time: today.AddDate(0, 0, -1), isTime: true, dateOnly: true}, true
+	}
+
+	if t, ok := p.parseEpoch(term); ok {
+		return value{time: t, isTime: true}, true
+	}
+
+	for _, l := range timeLayouts {
+		if t, err := time.ParseInLocation(l.layout, term, p.location); err == nil {
+			return value{time: t, isTime: true, dateOnly: l.dateOnly}, true
+		}
+	}
+
+	return value{}, false
+}
+
+// parseEpoch parses a Unix timestamp. The unit is chosen from the number of
+// digits, so 1700000000 is in seconds and 1700000000000 in milliseconds.
+// With an @ prefix, as used by date(1), it is always in seconds.
+func (p parser) parseEpoch(term string) (time.Time, bool) {
+	m := epochPattern.FindStringSubmatch(term)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	whole, frac := m[2], m[3]
+
+	// The number of digits in the whole part gives the unit's nanoseconds
+	var unit int64
+	switch {
+	case m[1] == "@" || (len(whole) >= 9 && len(whole) <= 11):
+		unit = int64(time.Second)
+	case len(whole) >= 12 && len(whole) <= 14:
+		unit = int64(time.Millisecond)
+	case len(whole) >= 15 && len(whole) <= 17:
+		unit = int64(time.Microsecond)
+	case len(whole) >= 18 && len(whole) <= 19 && frac == "":
+		unit = 1
+	default:
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Split into seconds and nanoseconds, so times after 2262 don't overflow
+	perSecond := int64(time.Second) / unit
+	nanos := n % perSecond * unit
+	if frac != "" {
+		f, _ := strconv.ParseFloat("0."+frac, 64)
+		nanos += int64(f * float64(unit))
+	}
+
+	return time.Unix(n/perSecond, nanos).In(p.location), true
+}
+
+// spanPattern matches one amount and unit of a span, such as "45 days" or "2h"
+var spanPattern = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*([a-zµ]+)\s*,?`)
+
+// spanUnits maps unit names to the unit they stand for
+var spanUnits = map[string]string{
+	"y": "year", "yr": "year", "yrs": "year", "year": "year", "years": "year",
+	"mo": "month", "mos": "month", "mon": "month", "month": "month", "months": "month",
+	"w": "week", "wk": "week", "wks": "week", "week": "week", "weeks": "week",
+	"d": "day", "day": "day", "days": "day",
+	"h": "hour", "hr": "hour", "hrs": "hour", "hour": "hour", "hours": "hour",
+	"m": "minute", "min": "minute", "mins": "minute", "minute": "minute", "minutes": "minute",
+	"s": "second", "sec": "second", "secs": "second", "second": "second", "seconds": "second",
+	"ms": "millisecond", "millisecond": "millisecond", "milliseconds": "millisecond",
+	"us": "microsecond", "µs": "microsecond", "microsecond": "microsecond", "microseconds": "microsecond",
+	"ns": "nanosecond", "nanosecond": "nanosecond", "nanoseconds": "nanosecond",
+}
+
+// clockUnits are the lengths of units that don't depend on the calendar
+var clockUnits = map[string]time.Duration{
+	"hour":        time.Hour,
+	"minute":      time.Minute,
+	"second":      time.Second,
+	"millisecond": time.Millisecond,
+	"microsecond": time.Microsecond,
+	"nanosecond":  time.Nanosecond,
+}
+
+// maxSpanAmount is the largest amount of a unit in a span. Larger spans
+// would take dates far past maxYear, and could overflow.
+const maxSpanAmount = 1e9
+
+// parseSpan parses amounts of time such as "45 days", "1 year 2 months" or "1h30m"
+func parseSpan(text string) (span, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return span{}, fmt.Errorf("missing duration")
+	}
+
+	var s span
+	for rest := text; strings.TrimSpace(rest) != ""; {
+		m := spanPattern.FindStringSubmatch(rest)
+		if m == nil {
+			return span{}, fmt.Errorf("unknown date or duration %q", text)
+		}
+		rest = rest[len(m[0]):]
+
+		unit, ok := spanUnits[m[2]]
+		if !ok {
+			return span{}, fmt.Errorf("unknown unit %q", m[2])
+		}
+
+		amount, _ := strconv.ParseFloat(m[1], 64)
+		if amount > maxSpanAmount {
+			return span{}, fmt.Errorf("%s is too many %ss", m[1], unit)
+		}
+		whole := amount == float64(int(amount))
+
+		switch unit {
+		case "year", "month":
+			if !whole {
+				return span{}, fmt.Errorf("%ss must be whole numbers", unit)
+			}
+			if unit == "year" {
+				s.years += int(amount)
+			} else {
+				s.months += int(amount)
+			}
+		case "week", "day":
+			days := amount
+			if unit == "week" {
+				days *= 7
+			}
+			if days == float64(int(days)) {
+				s.days += int(days)
+			} else {
+				// Fractions of days are counted in hours
+				if err := s.addClock(days * float64(dayLength)); err != nil {
+					return span{}, err
+				}
+			}
+		default:
+			if err := s.addClock(amount * float64(clockUnits[unit])); err != nil {
+				return span{}, err
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// addClock adds nanoseconds to the span's clock time, which is exact but
+// only reaches about 292 years
+func (s *span) addClock(nanos float64) error {
+	total := float64(s.clock) + nanos
+	if math.Abs(total) >= math.MaxInt64 {
+		return fmt.Errorf("durations in hours or less can be up to %d hours, use days or years", int64(math.MaxInt64/int64(time.Hour)))
+	}
+	s.clock = time.Duration(total)
+	return nil
+}
+
+// startOfDay returns midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,241 @@
+package datetime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testNow is the time "now" is read as in tests
+var testNow = time.Date(2026, time.March, 15, 10, 30, 0, 0, time.UTC)
+
+func testParser() parser {
+	return parser{now: testNow, location: time.UTC}
+}
+
+func TestParseTimeFormats(t *testing.T) {
+	tests := []struct {
+		term     string
+		want     time.Time
+		dateOnly bool
+	}{
+		{"now", testNow, false},
+		{"Today", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), true},
+		{"tomorrow", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), true},
+		{"yesterday", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), true},
+		{"2026-12-25T08:00:00.5+02:00", time.Date(2026, 12, 25, 6, 0, 0, 5e8, time.UTC), false},
+		{"2026-12-25T08:00:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026-12-25T08:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026-12-25 08:00:00+01:00", time.Date(2026, 12, 25, 7, 0, 0, 0, time.UTC), false},
+		{"2026-12-25 08:00:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026-12-25 08:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026-12-25", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"2026/12/25 08:00:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026/12/25 08:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"2026/12/25", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"25 Dec 2026 08:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"25 Dec 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"25 December 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"Dec 25 2026 08:00", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"Dec 25 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"Dec 25, 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"December 25 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"December 25, 2026", time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{"Fri, 25 Dec 2026 08:00:00 UTC", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"Fri, 25 Dec 2026 08:00:00 +0100", time.Date(2026, 12, 25, 7, 0, 0, 0, time.UTC), false},
+		{"Friday, 25-Dec-26 08:00:00 UTC", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"25 Dec 26 08:00 UTC", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"25 Dec 26 08:00 +0100", time.Date(2026, 12, 25, 7, 0, 0, 0, time.UTC), false},
+		{"Fri Dec 25 08:00:00 UTC 2026", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+		{"Fri Dec 25 08:00:00 +0100 2026", time.Date(2026, 12, 25, 7, 0, 0, 0, time.UTC), false},
+		{"Fri Dec 25 08:00:00 2026", time.Date(2026, 12, 25, 8, 0, 0, 0, time.UTC), false},
+	}
+
+	p := testParser()
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			v, ok := p.parseTime(tt.term)
+			if !ok {
+				t.Fatalf("parseTime(%q) failed", tt.term)
+			}
+			if !v.time.Equal(tt.want) || v.dateOnly != tt.dateOnly || !v.isTime {
+				t.Errorf("parseTime(%q) = %v (date only %v), want %v (date only %v)", tt.term, v.time, v.dateOnly, tt.want, tt.dateOnly)
+			}
+		})
+	}
+
+	for _, term := range []string{"", "soon", "2026-13-01", "25/12/2026", "12345"} {
+		if v, ok := p.parseTime(term); ok {
+			t.Errorf("parseTime(%q) = %v, want no time", term, v.time)
+		}
+	}
+}
+
+func TestParseEpoch(t *testing.T) {
+	tests := []struct {
+		term string
+		want time.Time
+		ok   bool
+	}{
+		// Seconds have 9 to 11 digits
+		{"100000000", time.Unix(100000000, 0), true},
+		{"1700000000", time.Unix(1700000000, 0), true},
+		{"1700000000.25", time.Unix(1700000000, 25e7), true},
+		{"99999999999", time.Unix(99999999999, 0), true},
+		// Milliseconds have 12 to 14 digits
+		{"100000000000", time.UnixMilli(100000000000), true},
+		{"1700000000123", time.UnixMilli(1700000000123), true},
+		{"1700000000123.5", time.Unix(0, 1700000000123500000), true},
+		// Microseconds have 15 to 17 digits
+		{"1700000000123456", time.UnixMicro(1700000000123456), true},
+		// Nanoseconds have 18 or 19 digits, without a fraction
+		{"1700000000123456789", time.Unix(0, 1700000000123456789), true},
+		{"1700000000123456789.5", time.Time{}, false},
+		// An @ prefix is always seconds
+		{"@0", time.Unix(0, 0), true},
+		{"@1700000000", time.Unix(1700000000, 0), true},
+		// Too short, too long or out of range
+		{"12345678", time.Time{}, false},
+		{"12345678901234567890", time.Time{}, false},
+		{"9300000000000000000", time.Time{}, false},
+		{"@99999999999", time.Unix(99999999999, 0), true},
+		{"@9999999999999999999", time.Time{}, false},
+		{"-1700000000", time.Time{}, false},
+	}
+
+	p := testParser()
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			got, ok := p.parseEpoch(tt.term)
+			if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+				t.Errorf("parseEpoch(%q) = %v, %v, want %v, %v", tt.term, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		query    string
+		want     time.Time
+		diff     span
+		dateOnly bool
+	}{
+		{query: "today + 45 days", want: time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "45 days + today", want: time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "today - 2 weeks", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "today + 36h", want: time.Date(2026, 3, 16, 12, 0, 0, 0, time.UTC)},
+		{query: "now + 1h30m", want: time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{query: "now + 1.5 days", want: time.Date(2026, 3, 16, 22, 30, 0, 0, time.UTC)},
+		{query: "2026-01-31 + 1 month", want: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "2026-01-31 + 1 year 1 month", want: time.Date(2027, 3, 3, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "today + 1 day + 2 days - 1 day", want: time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{query: "3 days ago", want: time.Date(2026, 3, 12, 10, 30, 0, 0, time.UTC)},
+		{query: "in 2 hours", want: time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)},
+		{query: "now + 3000 years", want: time.Date(5026, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{query: "2026-12-25 - today", diff: span{days: 285}},
+		{query: "today - 2026-12-25", diff: span{days: -285}},
+		{query: "now - today", diff: span{clock: 10*time.Hour + 30*time.Minute}},
+		{query: "today - now", diff: span{clock: -10*time.Hour - 30*time.Minute}},
+		{query: "now - 2026-03-14", diff: span{days: 1, clock: 10*time.Hour + 30*time.Minute}},
+		{query: "2026-03-14 - now", diff: span{days: -1, clock: -10*time.Hour - 30*time.Minute}},
+		{query: "1700000000 - @1699999000", diff: span{clock: 1000 * time.Second}},
+		{query: "1700000000 - 1699999000", diff: span{clock: 1000 * time.Second}},
+		{query: "1700000000 + 1 day", want: time.Unix(1700000000+86400, 0)},
+		{query: "@1700000000 + 1h", want: time.Unix(1700003600, 0)},
+		{query: "3000-01-01 - 1000-01-01", diff: span{days: 730485}},
+		{query: "0001-01-01 - 9999-12-31", diff: span{days: -3652058}},
+	}
+
+	p := testParser()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			v, err := p.parseExpression(tt.query)
+			if err != nil {
+				t.Fatalf("parseExpression(%q) error = %v", tt.query, err)
+			}
+
+			if tt.want.IsZero() {
+				if v.isTime || v.diff != tt.diff {
+					t.Errorf("parseExpression(%q) = %+v, want a difference of %v", tt.query, v, tt.diff)
+				}
+				return
+			}
+			if !v.isTime || !v.time.Equal(tt.want) || v.dateOnly != tt.dateOnly {
+				t.Errorf("parseExpression(%q) = %v (date only %v), want %v (date only %v)", tt.query, v.time, v.dateOnly, tt.want, tt.dateOnly)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"5 - 3", errNotDate.Error()},
+		{"123456789 + 1", errNotDate.Error()},
+		{"1700000000 * 2 - 5", errNotDate.Error()},
+		{"2 - 1700000000", errNotDate.Error()},
+		{"@123456789 + 1", `unknown date or duration "1"`},
+		{"45 days", errNotDate.Error()},
+		{"hello world", errNotDate.Error()},
+		{"today + tomorrow", "cannot add two dates"},
+		{"3 days - today", "cannot subtract a date from a duration"},
+		{"today + 1.5 months", "months must be whole numbers"},
+		{"today + 2 fortnights", `unknown unit "fortnights"`},
+		{"today + soon", `unknown date or duration "soon"`},
+		{"now + 99999999999 years", `99999999999 is too many years`},
+		{"now + 8000 years", errOutOfRange.Error()},
+		{"today - 2027 years", errOutOfRange.Error()},
+		{"in 9000 years", errOutOfRange.Error()},
+		{"now + 3000000 hours", "durations in hours or less can be up to 2562047 hours, use days or years"},
+	}
+
+	p := testParser()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := p.parseExpression(tt.query)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parseExpression(%q) error = %v, want %q", tt.query, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := p.parseExpression("tomorrow"); errors.Is(err, errNotDate) {
+		t.Error("parseExpression(tomorrow) = errNotDate, want a date")
+	}
+}
+
+func TestParseSpan(t *testing.T) {
+	tests := []struct {
+		text string
+		want span
+	}{
+		{"45 days", span{days: 45}},
+		{"1 year 2 months", span{years: 1, months: 2}},
+		{"1y, 2mo, 3w", span{years: 1, months: 2, days: 21}},
+		{"1h30m", span{clock: 90 * time.Minute}},
+		{"0.5 days", span{clock: 12 * time.Hour}},
+		{"1.5 weeks", span{clock: 252 * time.Hour}},
+		{"250ms 10µs 5ns", span{clock: 250*time.Millisecond + 10*time.Microsecond + 5}},
+		{"2 Hours", span{clock: 2 * time.Hour}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSpan(tt.text)
+		if err != nil {
+			t.Errorf("parseSpan(%q) error = %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSpan(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "days", "1 fortnight", "1.5 years"} {
+		if _, err := parseSpan(text); err == nil {
+			t.Errorf("parseSpan(%q) error = nil, want an error", text)
+		}
+	}
+}
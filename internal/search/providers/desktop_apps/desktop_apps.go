@@ -0,0 +1,337 @@
+package desktopapps
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// Match scores, higher is better
+const (
+	scoreExact         = 1000
+	scorePrefix        = 900
+	scoreWordPrefix    = 800
+	scoreKeywordPrefix = 700
+	scoreGenericPrefix = 650
+	scoreSubstring     = 600
+	scoreFuzzy         = 100
+)
+
+// Provider is a search provider for applications installed through
+// freedesktop .desktop files, as used on Linux desktops
+type Provider struct {
+	priority   int
+	maxResults int
+	// apps is the index of applications, published once it is fully built
+	apps atomic.Pointer[[]*DesktopEntry]
+	// ready is closed once apps has been published
+	ready     chan struct{}
+	readyOnce sync.Once
+	// icons resolves icon names from the current icon theme, set up on the first index
+	icons     *iconResolver
+	iconsOnce sync.Once
+	// iconCache holds loaded icon resources by path
+	iconCache sync.Map
+}
+
+// NewProvider creates a new desktop application provider and indexes the
+// applications in the background
+func NewProvider(priority, maxResults int) *Provider {
+	if maxResults <= 0 {
+		maxResults = 10 // Default max results if invalid value provided
+	}
+
+	provider := &Provider{
+		priority:   priority,
+		maxResults: maxResults,
+		ready:      make(chan struct{}),
+	}
+
+	go provider.Reindex()
+
+	return provider
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Applications"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeApp
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// Ready returns a channel that is closed once the applications have been indexed
+func (p *Provider) Ready() <-chan struct{} {
+	return p.ready
+}
+
+// CanHandle returns whether the provider can handle the query
+func (p *Provider) CanHandle(query string) bool {
+	return strings.TrimSpace(query) != ""
+}
+
+// Search returns the applications matching the query, best match first
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	apps := p.apps.Load()
+	if apps == nil {
+		return []search.SearchResult{}, nil
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	type scoredEntry struct {
+		entry *DesktopEntry
+		score int
+	}
+
+	matches := []scoredEntry{}
+	for _, entry := range *apps {
+		if score := scoreEntry(entry, query); score > 0 {
+			matches = append(matches, scoredEntry{entry: entry, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.Name < matches[j].entry.Name
+	})
+
+	results := []search.SearchResult{}
+	for _, match := range matches {
+		if len(results) >= p.maxResults {
+			break
+		}
+		results = append(results, p.createResult(match.entry))
+	}
+
+	return results, nil
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeApp {
+		return fmt.Errorf("not an application result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+
+	return nil
+}
+
+// Reindex rescans the XDG data directories for applications
+func (p *Provider) Reindex() {
+	dataDirs := xdgDataDirs()
+
+	p.iconsOnce.Do(func() {
+		homeDir, _ := os.UserHomeDir()
+
+		baseDirs := []string{filepath.Join(homeDir, ".icons")}
+		for _, dir := range dataDirs {
+			baseDirs = append(baseDirs, filepath.Join(dir, "icons"))
+		}
+
+		p.icons = newIconResolver(currentIconTheme(), baseDirs, []string{"/usr/share/pixmaps"})
+	})
+
+	appDirs := []string{}
+	for _, dir := range dataDirs {
+		appDirs = append(appDirs, filepath.Join(dir, "applications"))
+	}
+
+	apps := indexApplications(appDirs, LocaleCandidates(currentLocale()), desktopNames())
+	p.apps.Store(&apps)
+	p.readyOnce.Do(func() { close(p.ready) })
+
+	slog.Debug("Indexed desktop applications", slog.Int("numApps", len(apps)))
+}
+
+// createResult creates a search result for an application
+func (p *Provider) createResult(entry *DesktopEntry) search.SearchResult {
+	description := entry.Comment
+	if description == "" {
+		description = entry.GenericName
+	}
+	if description == "" {
+		description = "Application"
+	}
+
+	return search.SearchResult{
+		Title:       entry.Name,
+		Description: description,
+		Path:        entry.Path,
+		Icon:        p.iconFor(entry),
+		Type:        search.TypeApp,
+		Action: func() {
+			if err := Launch(entry); err != nil {
+				slog.Error("Failed to launch application", slog.String("id", entry.ID), slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// iconFor loads the entry's icon from the icon theme, falling back to a generic icon
+func (p *Provider) iconFor(entry *DesktopEntry) fyne.Resource {
+	path := p.icons.Lookup(entry.Icon)
+	if path == "" {
+		return theme.ComputerIcon()
+	}
+
+	if cached, ok := p.iconCache.Load(path); ok {
+		return cached.(fyne.Resource)
+	}
+
+	resource, err := fyne.LoadResourceFromPath(path)
+	if err != nil {
+		slog.Debug("Failed to load application icon", slog.String("path", path), slog.Any("error", err))
+		return theme.ComputerIcon()
+	}
+
+	p.iconCache.Store(path, resource)
+	return resource
+}
+
+// indexApplications parses the .desktop files in appDirs. When the same
+// desktop file ID appears in more than one directory the first one wins, so
+// user entries in $XDG_DATA_HOME override or hide the system ones.
+func indexApplications(appDirs, locales, desktops []string) []*DesktopEntry {
+	seen := map[string]bool{}
+	apps := []*DesktopEntry{}
+
+	for _, appDir := range appDirs {
+		filepath.WalkDir(appDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+
+			// The desktop file ID is the path below the applications directory with / replaced by -
+			rel, err := filepath.Rel(appDir, path)
+			if err != nil {
+				return nil
+			}
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			entry, err := ParseDesktopEntryFile(path, locales)
+			if err != nil {
+				slog.Debug("Skipping desktop entry", slog.String("path", path), slog.Any("error", err))
+				return nil
+			}
+			entry.ID = id
+
+			if entry.ShouldShow(desktops) && tryExecFound(entry.TryExec) {
+				apps = append(apps, entry)
+			}
+
+			return nil
+		})
+	}
+
+	return apps
+}
+
+// scoreEntry scores how well an application matches the lowercase query, 0 if it doesn't
+func scoreEntry(entry *DesktopEntry, query string) int {
+	name := strings.ToLower(entry.Name)
+
+	switch {
+	case name == query:
+		return scoreExact
+	case strings.HasPrefix(name, query):
+		return scorePrefix - len(name)
+	}
+
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, query) {
+			return scoreWordPrefix
+		}
+	}
+
+	for _, keyword := range entry.Keywords {
+		if strings.HasPrefix(strings.ToLower(keyword), query) {
+			return scoreKeywordPrefix
+		}
+	}
+
+	generic := strings.ToLower(entry.GenericName)
+	for _, word := range strings.Fields(generic) {
+		if strings.HasPrefix(word, query) {
+			return scoreGenericPrefix
+		}
+	}
+
+	if strings.Contains(name, query) {
+		return scoreSubstring
+	}
+
+	// Desktop file IDs often hold the program name, e.g. org.gnome.Nautilus
+	if strings.Contains(strings.ToLower(strings.TrimSuffix(entry.ID, ".desktop")), query) {
+		return scoreSubstring - 50
+	}
+
+	if rank := fuzzy.RankMatch(query, name); rank >= 0 {
+		return scoreFuzzy + max(0, 50-rank)
+	}
+
+	return 0
+}
+
+// xdgDataDirs returns $XDG_DATA_HOME followed by $XDG_DATA_DIRS, using the
+// defaults from the base directory spec when they are unset
+func xdgDataDirs() []string {
+	dirs := []string{}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(homeDir, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		dirs = append(dirs, dataHome)
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// desktopNames returns the desktops listed in $XDG_CURRENT_DESKTOP, e.g. GNOME
+func desktopNames() []string {
+	return strings.FieldsFunc(os.Getenv("XDG_CURRENT_DESKTOP"), func(r rune) bool {
+		return r == ':'
+	})
+}
@@ -0,0 +1,293 @@
+package desktopapps
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DesktopEntry is an application described by a freedesktop .desktop file.
+// Localized keys have already been resolved for the locale it was parsed with.
+type DesktopEntry struct {
+	ID          string // Desktop file ID, e.g. org.gnome.Nautilus.desktop
+	Path        string // Location of the .desktop file
+	Type        string
+	Name        string
+	GenericName string
+	Comment     string
+	Keywords    []string
+	Exec        string
+	TryExec     string
+	Icon        string
+	WorkDir     string // The Path key, the working directory to run the program in
+	Terminal    bool
+	NoDisplay   bool
+	Hidden      bool
+	OnlyShowIn  []string
+	NotShowIn   []string
+}
+
+// desktopEntryGroup is the group holding the entry's keys
+const desktopEntryGroup = "Desktop Entry"
+
+// ParseDesktopEntryFile parses the .desktop file at path
+func ParseDesktopEntryFile(path string, locales []string) (*DesktopEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open desktop entry: %w", err)
+	}
+	defer file.Close()
+
+	entry, err := ParseDesktopEntry(file, locales)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	entry.Path = path
+
+	return entry, nil
+}
+
+// ParseDesktopEntry parses the [Desktop Entry] group of a .desktop file.
+// Localized values are picked using locales, most preferred first, in the
+// form returned by LocaleCandidates.
+func ParseDesktopEntry(r io.Reader, locales []string) (*DesktopEntry, error) {
+	groups, err := parseKeyFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := groups[desktopEntryGroup]
+	if !ok {
+		return nil, fmt.Errorf("missing [%s] group", desktopEntryGroup)
+	}
+
+	localized := func(key string) string {
+		for _, locale := range locales {
+			if value, ok := values[key+"["+locale+"]"]; ok {
+				return unescapeValue(value)
+			}
+		}
+		return unescapeValue(values[key])
+	}
+
+	entry := &DesktopEntry{
+		Type:        values["Type"],
+		Name:        localized("Name"),
+		GenericName: localized("GenericName"),
+		Comment:     localized("Comment"),
+		Keywords:    splitList(localizedRaw(values, "Keywords", locales)),
+		Exec:        unescapeValue(values["Exec"]),
+		TryExec:     unescapeValue(values["TryExec"]),
+		Icon:        localized("Icon"),
+		WorkDir:     unescapeValue(values["Path"]),
+		Terminal:    values["Terminal"] == "true",
+		NoDisplay:   values["NoDisplay"] == "true",
+		Hidden:      values["Hidden"] == "true",
+		OnlyShowIn:  splitList(values["OnlyShowIn"]),
+		NotShowIn:   splitList(values["NotShowIn"]),
+	}
+
+	if entry.Type == "" {
+		return nil, fmt.Errorf("missing Type key")
+	}
+	if entry.Name == "" {
+		return nil, fmt.Errorf("missing Name key")
+	}
+
+	return entry, nil
+}
+
+// ShouldShow reports whether the entry should be listed as an application on
+// the given desktops, the values of $XDG_CURRENT_DESKTOP
+func (e *DesktopEntry) ShouldShow(desktops []string) bool {
+	if e.Type != "Application" || e.NoDisplay || e.Hidden || e.Exec == "" {
+		return false
+	}
+
+	if len(e.OnlyShowIn) > 0 && !containsAny(e.OnlyShowIn, desktops) {
+		return false
+	}
+
+	return !containsAny(e.NotShowIn, desktops)
+}
+
+// parseKeyFile parses the groups of a freedesktop key file into raw,
+// still escaped, values keyed by group name and then key
+func parseKeyFile(r io.Reader) (map[string]map[string]string, error) {
+	groups := map[string]map[string]string{}
+	var current map[string]string
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: malformed group header", lineNumber)
+			}
+
+			name := line[1 : len(line)-1]
+			// The first occurrence of a group wins if it is repeated
+			if _, ok := groups[name]; ok {
+				current = nil
+				continue
+			}
+
+			current = map[string]string{}
+			groups[name] = current
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value", lineNumber)
+		}
+
+		if current == nil {
+			// Keys before the first group, or in a repeated group, are ignored
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if _, exists := current[key]; !exists {
+			current[key] = strings.TrimSpace(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// localizedRaw returns the raw value of the best localized variant of a key
+func localizedRaw(values map[string]string, key string, locales []string) string {
+	for _, locale := range locales {
+		if value, ok := values[key+"["+locale+"]"]; ok {
+			return value
+		}
+	}
+	return values[key]
+}
+
+// unescapeValue resolves the \s, \n, \t, \r and \\ escapes of a string value
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			// Unknown escapes, such as \; in lists, are kept for the caller
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+// splitList splits a semicolon separated list value, honouring \; escapes
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	items := []string{}
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ';':
+			current.WriteByte(';')
+			i++
+		case value[i] == ';':
+			if item := unescapeValue(current.String()); item != "" {
+				items = append(items, item)
+			}
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+
+	if item := unescapeValue(current.String()); item != "" {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// LocaleCandidates returns the locale suffixes to try for localized keys, in
+// the order the desktop entry spec prefers them. A locale such as
+// sr_YU.UTF-8@Latn gives sr_YU@Latn, sr_YU, sr@Latn and sr.
+func LocaleCandidates(locale string) []string {
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	lang, modifier, _ := strings.Cut(locale, "@")
+	lang, _, _ = strings.Cut(lang, ".") // Drop the encoding
+	lang, country, _ := strings.Cut(lang, "_")
+
+	candidates := []string{}
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+
+	return append(candidates, lang)
+}
+
+// currentLocale returns the locale used for messages from the environment
+func currentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// containsAny reports whether any of the values are in list
+func containsAny(list, values []string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package desktopapps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const filesEntry = `# Comments and blank lines are skipped
+Ignored=before any group
+
+[Desktop Entry]
+Type=Application
+Name=Files
+Name[de]=Dateien
+Name[sr@Latn]=Datoteke
+GenericName=File Manager
+Comment=Access and organize files
+Comment[de]=Zugriff auf Dateien
+Keywords=folder;manager;explore;
+Keywords[de]=Ordner;Verwaltung;
+Exec=nautilus --new-window %U
+TryExec=nautilus
+Icon=org.gnome.Nautilus
+Path=/home/user\sfiles
+Terminal=false
+OnlyShowIn=GNOME;Unity;
+Name=Duplicate keys keep the first value
+
+[Desktop Action new-window]
+Name=New Window
+Exec=nautilus --new-window
+
+[Desktop Entry]
+Name=Repeated groups are ignored
+`
+
+func TestParseDesktopEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		locales []string
+		want    DesktopEntry
+	}{
+		{
+			name: "default locale",
+			want: DesktopEntry{
+				Type:        "Application",
+				Name:        "Files",
+				GenericName: "File Manager",
+				Comment:     "Access and organize files",
+				Keywords:    []string{"folder", "manager", "explore"},
+				Exec:        "nautilus --new-window %U",
+				TryExec:     "nautilus",
+				Icon:        "org.gnome.Nautilus",
+				WorkDir:     "/home/user files",
+				OnlyShowIn:  []string{"GNOME", "Unity"},
+			},
+		},
+		{
+			name:    "localized",
+			locales: LocaleCandidates("de_DE.UTF-8"),
+			want: DesktopEntry{
+				Type:        "Application",
+				Name:        "Dateien",
+				GenericName: "File Manager",
+				Comment:     "Zugriff auf Dateien",
+				Keywords:    []string{"Ordner", "Verwaltung"},
+				Exec:        "nautilus --new-window %U",
+				TryExec:     "nautilus",
+				Icon:        "org.gnome.Nautilus",
+				WorkDir:     "/home/user files",
+				OnlyShowIn:  []string{"GNOME", "Unity"},
+			},
+		},
+		{
+			name:    "locale with modifier",
+			locales: LocaleCandidates("sr_YU.UTF-8@Latn"),
+			want: DesktopEntry{
+				Type:        "Application",
+				Name:        "Datoteke",
+				GenericName: "File Manager",
+				Comment:     "Access and organize files",
+				Keywords:    []string{"folder", "manager", "explore"},
+				Exec:        "nautilus --new-window %U",
+				TryExec:     "nautilus",
+				Icon:        "org.gnome.Nautilus",
+				WorkDir:     "/home/user files",
+				OnlyShowIn:  []string{"GNOME", "Unity"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseDesktopEntry(strings.NewReader(filesEntry), tt.locales)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*entry, tt.want) {
+				t.Errorf("ParseDesktopEntry() = %+v, want %+v", *entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDesktopEntryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing group", "[Other]\nName=x\n", "missing [Desktop Entry] group"},
+		{"missing type", "[Desktop Entry]\nName=x\n", "missing Type key"},
+		{"missing name", "[Desktop Entry]\nType=Application\n", "missing Name key"},
+		{"malformed header", "[Desktop Entry\nType=Application\n", "line 1: malformed group header"},
+		{"not a key", "[Desktop Entry]\nType=Application\nName\n", "line 3: expected key=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDesktopEntry(strings.NewReader(tt.content), nil)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ParseDesktopEntry() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDesktopEntryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "org.gnome.Nautilus.desktop")
+	if err := os.WriteFile(path, []byte(filesEntry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := ParseDesktopEntryFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Path != path {
+		t.Errorf("Path = %q, want %q", entry.Path, path)
+	}
+
+	if _, err := ParseDesktopEntryFile(filepath.Join(t.TempDir(), "missing.desktop"), nil); err == nil {
+		t.Error("ParseDesktopEntryFile(missing) error = nil, want an error")
+	}
+}
+
+func TestShouldShow(t *testing.T) {
+	app := DesktopEntry{Type: "Application", Name: "App", Exec: "app"}
+
+	tests := []struct {
+		name     string
+		modify   func(e *DesktopEntry)
+		desktops []string
+		want     bool
+	}{
+		{"application", func(e *DesktopEntry) {}, nil, true},
+		{"link", func(e *DesktopEntry) { e.Type = "Link" }, nil, false},
+		{"no display", func(e *DesktopEntry) { e.NoDisplay = true }, nil, false},
+		{"hidden", func(e *DesktopEntry) { e.Hidden = true }, nil, false},
+		{"no exec", func(e *DesktopEntry) { e.Exec = "" }, nil, false},
+		{"only shown in this desktop", func(e *DesktopEntry) { e.OnlyShowIn = []string{"GNOME"} }, []string{"ubuntu", "gnome"}, true},
+		{"only shown in another desktop", func(e *DesktopEntry) { e.OnlyShowIn = []string{"KDE"} }, []string{"GNOME"}, false},
+		{"not shown in this desktop", func(e *DesktopEntry) { e.NotShowIn = []string{"GNOME"} }, []string{"GNOME"}, false},
+		{"not shown in another desktop", func(e *DesktopEntry) { e.NotShowIn = []string{"KDE"} }, []string{"GNOME"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := app
+			tt.modify(&entry)
+			if got := entry.ShouldShow(tt.desktops); got != tt.want {
+				t.Errorf("ShouldShow(%q) = %v, want %v", tt.desktops, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeValue(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"plain", "plain"},
+		{`a\sb`, "a b"},
+		{`line\nbreak\ttab\rreturn`, "line\nbreak\ttab\rreturn"},
+		{`back\\slash`, `back\slash`},
+		{`list\;item`, `list\;item`},
+		{`trailing\`, `trailing\`},
+	}
+
+	for _, tt := range tests {
+		if got := unescapeValue(tt.value); got != tt.want {
+			t.Errorf("unescapeValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"a;b;c", []string{"a", "b", "c"}},
+		{"a;b;", []string{"a", "b"}},
+		{"a;;b", []string{"a", "b"}},
+		{`semi\;colon;next`, []string{"semi;colon", "next"}},
+		{`with\sspace`, []string{"with space"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleCandidates(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{"", nil},
+		{"C", nil},
+		{"POSIX", nil},
+		{"de", []string{"de"}},
+		{"de_DE.UTF-8", []string{"de_DE", "de"}},
+		{"sr@Latn", []string{"sr@Latn", "sr"}},
+		{"sr_YU.UTF-8@Latn", []string{"sr_YU@Latn", "sr_YU", "sr@Latn", "sr"}},
+	}
+
+	for _, tt := range tests {
+		if got := LocaleCandidates(tt.locale); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LocaleCandidates(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package desktopapps
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExpandExec turns the entry's Exec value into the program and arguments to
+// run, expanding field codes such as %f, %U and %i. files holds the files or
+// URLs the application is opened with, which may be empty.
+func ExpandExec(entry *DesktopEntry, files []string) ([]string, error) {
+	args, err := splitExec(entry.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := []string{}
+	for _, arg := range args {
+		switch arg {
+		// Field codes that expand to a list must stand alone as an argument
+		case "%F", "%U":
+			expanded = append(expanded, files...)
+			continue
+		case "%i":
+			if entry.Icon != "" {
+				expanded = append(expanded, "--icon", entry.Icon)
+			}
+			continue
+		}
+
+		value, err := expandFieldCodes(arg, entry, files)
+		if err != nil {
+			return nil, err
+		}
+
+		// An argument that was only a single file code is dropped when there are no files
+		if value == "" && (arg == "%f" || arg == "%u") {
+			continue
+		}
+		expanded = append(expanded, value)
+	}
+
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("empty Exec key")
+	}
+
+	return expanded, nil
+}
+
+// expandFieldCodes expands the field codes within a single argument
+func expandFieldCodes(arg string, entry *DesktopEntry, files []string) (string, error) {
+	if !strings.Contains(arg, "%") {
+		return arg, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' {
+			b.WriteByte(arg[i])
+			continue
+		}
+
+		if i+1 == len(arg) {
+			return "", fmt.Errorf("incomplete field code in %q", arg)
+		}
+
+		i++
+		switch arg[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'f', 'u':
+			if len(files) > 0 {
+				b.WriteString(files[0])
+			}
+		case 'c':
+			b.WriteString(entry.Name)
+		case 'k':
+			b.WriteString(entry.Path)
+		case 'd', 'D', 'n', 'N', 'v', 'm':
+			// Deprecated field codes are removed
+		case 'F', 'U', 'i':
+			return "", fmt.Errorf("field code %%%c must be a separate argument", arg[i])
+		default:
+			return "", fmt.Errorf("unknown field code %%%c", arg[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// splitExec splits an Exec value into arguments. Arguments containing
+// reserved characters are enclosed in double quotes, inside which ", `, $
+// and \ are escaped with a backslash.
+func splitExec(value string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inArg, inQuotes := false, false
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case inQuotes && c == '\\':
+			if i+1 == len(value) {
+				return nil, fmt.Errorf("unterminated escape in Exec key")
+			}
+			i++
+			current.WriteByte(value[i])
+		case inQuotes && c == '"':
+			inQuotes = false
+		case inQuotes:
+			current.WriteByte(c)
+		case c == '"':
+			inQuotes, inArg = true, true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in Exec key")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// Launch starts the application without waiting for it to exit, opening the
+// given files or URLs. Terminal applications are run in a terminal emulator.
+func Launch(entry *DesktopEntry, files ...string) error {
+	args, err := ExpandExec(entry, files)
+	if err != nil {
+		return fmt.Errorf("failed to expand Exec for %s: %w", entry.ID, err)
+	}
+
+	if entry.Terminal {
+		args = append([]string{terminalEmulator(), "-e"}, args...)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = entry.WorkDir
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to launch %s: %w", entry.ID, err)
+	}
+
+	// Reap the process when it exits so it doesn't linger as a zombie
+	go cmd.Wait()
+
+	return nil
+}
+
+// terminalEmulator returns the terminal used to run terminal applications
+func terminalEmulator() string {
+	if terminal := os.Getenv("TERMINAL"); terminal != "" {
+		return terminal
+	}
+	return "x-terminal-emulator"
+}
+
+// tryExecFound reports whether the program named by TryExec is installed.
+// Entries without TryExec are always considered installed.
+func tryExecFound(tryExec string) bool {
+	if tryExec == "" {
+		return true
+	}
+
+	_, err := exec.LookPath(tryExec)
+	return err == nil
+}
@@ -0,0 +1,93 @@
+package desktopapps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandExec(t *testing.T) {
+	entry := &DesktopEntry{
+		Name: "Text Editor",
+		Path: "/usr/share/applications/editor.desktop",
+		Icon: "accessories-text-editor",
+	}
+
+	tests := []struct {
+		name  string
+		exec  string
+		files []string
+		want  []string
+	}{
+		{"no field codes", "editor --new", nil, []string{"editor", "--new"}},
+		{"file", "editor %f", []string{"/tmp/a.txt", "/tmp/b.txt"}, []string{"editor", "/tmp/a.txt"}},
+		{"file without files", "editor %f", nil, []string{"editor"}},
+		{"url without files", "editor %u --wait", nil, []string{"editor", "--wait"}},
+		{"file list", "editor %F", []string{"/tmp/a.txt", "/tmp/b.txt"}, []string{"editor", "/tmp/a.txt", "/tmp/b.txt"}},
+		{"url list without files", "editor %U", nil, []string{"editor"}},
+		{"icon", "editor %i", nil, []string{"editor", "--icon", "accessories-text-editor"}},
+		{"name and location", "editor --class=%c --desktop=%k", nil, []string{"editor", "--class=Text Editor", "--desktop=/usr/share/applications/editor.desktop"}},
+		{"file inside an argument", "editor --open=%f", []string{"/tmp/a.txt"}, []string{"editor", "--open=/tmp/a.txt"}},
+		{"literal percent", "editor --zoom=100%%", nil, []string{"editor", "--zoom=100%"}},
+		{"deprecated codes removed", "editor --title=%c%d%D%n%N%v%m", nil, []string{"editor", "--title=Text Editor"}},
+		{"quoted arguments", `"/opt/My Editor/editor" "say \"hi\"" "\$HOME" "a\\b"`, nil, []string{"/opt/My Editor/editor", `say "hi"`, "$HOME", `a\b`}},
+		{"extra whitespace", "  editor \t --new  ", nil, []string{"editor", "--new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := *entry
+			e.Exec = tt.exec
+			got, err := ExpandExec(&e, tt.files)
+			if err != nil {
+				t.Fatalf("ExpandExec(%q) error = %v", tt.exec, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandExec(%q) = %q, want %q", tt.exec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandExecIconWithoutIcon(t *testing.T) {
+	got, err := ExpandExec(&DesktopEntry{Exec: "editor %i"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"editor"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandExec() = %q, want %q", got, want)
+	}
+}
+
+func TestExpandExecErrors(t *testing.T) {
+	tests := []struct {
+		exec string
+		want string
+	}{
+		{"", "empty Exec key"},
+		{"%f", "empty Exec key"},
+		{"editor %", `incomplete field code in "%"`},
+		{"editor --files=%F", "field code %F must be a separate argument"},
+		{"editor --icon=%i", "field code %i must be a separate argument"},
+		{"editor %x", "unknown field code %x"},
+		{`editor "unterminated`, "unterminated quote in Exec key"},
+		{`editor "escape\`, "unterminated escape in Exec key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exec, func(t *testing.T) {
+			_, err := ExpandExec(&DesktopEntry{Exec: tt.exec}, nil)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ExpandExec(%q) error = %v, want %q", tt.exec, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTryExecFound(t *testing.T) {
+	if !tryExecFound("") {
+		t.Error("tryExecFound(\"\") = false, want true")
+	}
+	if tryExecFound("marvin-test-program-that-does-not-exist") {
+		t.Error("tryExecFound(missing) = true, want false")
+	}
+}
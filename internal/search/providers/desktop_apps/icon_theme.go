@@ -0,0 +1,253 @@
+package desktopapps
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// preferredIconSize is the icon size looked for first, in pixels
+	preferredIconSize = 48
+	// fallbackIconTheme is the theme every icon theme falls back to
+	fallbackIconTheme = "hicolor"
+)
+
+// iconExtensions are the icon formats that can be displayed, in order of preference
+var iconExtensions = []string{".png", ".svg"}
+
+// iconDir is a directory of an icon theme holding icons of one size
+type iconDir struct {
+	path     string
+	size     int
+	minSize  int
+	maxSize  int
+	scalable bool
+}
+
+// distance returns how far the directory's icons are from the wanted size
+func (d iconDir) distance(size int) int {
+	if d.scalable && size >= d.minSize && size <= d.maxSize {
+		return 0
+	}
+	if d.size > size {
+		return d.size - size
+	}
+	return size - d.size
+}
+
+// iconResolver finds icon files by name following the freedesktop icon theme
+// spec: the current theme, the themes it inherits from, hicolor, then pixmaps
+type iconResolver struct {
+	baseDirs   []string
+	pixmapDirs []string
+	themeDirs  []iconDir // Directories of the theme chain, best first
+
+	cache sync.Map // Icon name to resolved path, "" if not found
+}
+
+// newIconResolver creates a resolver for the named theme. baseDirs are the
+// directories that contain icon themes, such as ~/.icons and /usr/share/icons.
+func newIconResolver(theme string, baseDirs, pixmapDirs []string) *iconResolver {
+	r := &iconResolver{
+		baseDirs:   baseDirs,
+		pixmapDirs: pixmapDirs,
+	}
+
+	chain := r.themeChain(theme)
+	for _, name := range chain {
+		r.themeDirs = append(r.themeDirs, r.loadThemeDirs(name)...)
+	}
+
+	return r
+}
+
+// Lookup returns the path of the named icon, or "" if it can't be found.
+// Names that are absolute paths are returned as is when the file exists.
+func (r *iconResolver) Lookup(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	if cached, ok := r.cache.Load(name); ok {
+		return cached.(string)
+	}
+
+	path := r.lookup(name)
+	r.cache.Store(name, path)
+
+	return path
+}
+
+// lookup searches the theme chain and pixmap directories for an icon
+func (r *iconResolver) lookup(name string) string {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+		return ""
+	}
+
+	// Some entries include the extension even though the spec says not to
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".png"), ".svg")
+
+	for _, dir := range r.themeDirs {
+		if path := findIconFile(dir.path, name); path != "" {
+			return path
+		}
+	}
+
+	for _, dir := range r.pixmapDirs {
+		if path := findIconFile(dir, name); path != "" {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// themeChain returns the theme, the themes it inherits from, and hicolor
+func (r *iconResolver) themeChain(theme string) []string {
+	chain := []string{}
+	seen := map[string]bool{}
+
+	var add func(name string)
+	add = func(name string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		chain = append(chain, name)
+
+		for _, parent := range r.themeIndex(name)["Inherits"] {
+			add(parent)
+		}
+	}
+
+	add(theme)
+	add(fallbackIconTheme)
+
+	return chain
+}
+
+// themeIndex reads the [Icon Theme] group of a theme's index.theme as lists
+func (r *iconResolver) themeIndex(theme string) map[string][]string {
+	for _, base := range r.baseDirs {
+		file, err := os.Open(filepath.Join(base, theme, "index.theme"))
+		if err != nil {
+			continue
+		}
+
+		groups, err := parseKeyFile(file)
+		file.Close()
+		if err != nil {
+			continue
+		}
+
+		index := map[string][]string{}
+		for key, value := range groups["Icon Theme"] {
+			index[key] = splitCommaList(value)
+		}
+		return index
+	}
+
+	return nil
+}
+
+// loadThemeDirs returns the icon directories of a theme across all base
+// directories, closest to the preferred size first
+func (r *iconResolver) loadThemeDirs(theme string) []iconDir {
+	dirs := []iconDir{}
+
+	for _, base := range r.baseDirs {
+		themePath := filepath.Join(base, theme)
+
+		file, err := os.Open(filepath.Join(themePath, "index.theme"))
+		if err != nil {
+			continue
+		}
+
+		groups, err := parseKeyFile(file)
+		file.Close()
+		if err != nil {
+			continue
+		}
+
+		names := splitCommaList(groups["Icon Theme"]["Directories"])
+		names = append(names, splitCommaList(groups["Icon Theme"]["ScaledDirectories"])...)
+
+		for _, name := range names {
+			group := groups[name]
+			if group == nil {
+				continue
+			}
+
+			// Only application icons are needed
+			if context := group["Context"]; context != "" && context != "Applications" {
+				continue
+			}
+
+			dir := iconDir{path: filepath.Join(themePath, name)}
+			dir.size, _ = strconv.Atoi(group["Size"])
+			dir.minSize, dir.maxSize = dir.size, dir.size
+			if group["Type"] == "Scalable" {
+				dir.scalable = true
+				if minSize, err := strconv.Atoi(group["MinSize"]); err == nil {
+					dir.minSize = minSize
+				}
+				if maxSize, err := strconv.Atoi(group["MaxSize"]); err == nil {
+					dir.maxSize = maxSize
+				}
+			}
+
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return dirs[i].distance(preferredIconSize) < dirs[j].distance(preferredIconSize)
+	})
+
+	return dirs
+}
+
+// findIconFile returns the path of the icon in dir with a supported extension, or ""
+func findIconFile(dir, name string) string {
+	for _, ext := range iconExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// splitCommaList splits a comma separated list value
+func splitCommaList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// currentIconTheme returns the icon theme configured for the desktop, or hicolor
+func currentIconTheme() string {
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "icon-theme").Output()
+	if err != nil {
+		return fallbackIconTheme
+	}
+
+	theme := strings.Trim(strings.TrimSpace(string(out)), "'")
+	if theme == "" {
+		return fallbackIconTheme
+	}
+
+	return theme
+}
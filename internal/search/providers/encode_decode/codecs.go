@@ -0,0 +1,457 @@
+package encodedecode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"mime/quotedprintable"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// maxDecompressedSize limits how much gzip data is inflated, so a small
+// compressed input can't exhaust memory
+const maxDecompressedSize = 16 << 20
+
+// maxBase58Size limits base58 input, as converting it to and from a number
+// takes time quadratic in its length
+const maxBase58Size = 16 << 10
+
+// errBase58TooLarge is returned for base58 input over maxBase58Size
+var errBase58TooLarge = fmt.Errorf("base58 input is limited to %d KB", maxBase58Size>>10)
+
+// codec converts text to and from an encoding
+type codec struct {
+	// name is shown in results, e.g. "Base64 URL"
+	name string
+	// keywords select the codec. The first is also used in result paths.
+	keywords []string
+	encode   func([]byte) ([]byte, error)
+	// decode is nil for codecs that are their own inverse, such as ROT13
+	decode func([]byte) ([]byte, error)
+}
+
+// codecs are the encodings the provider offers. Add a codec here to make it
+// available; Search finds codecs by their keywords.
+var codecs = []codec{
+	{
+		name:     "Base64",
+		keywords: []string{"base64", "b64"},
+		encode:   encodeWith(base64.StdEncoding.EncodeToString),
+		decode:   decodeWith(base64.StdEncoding.DecodeString),
+	},
+	{
+		name:     "Base64 URL",
+		keywords: []string{"base64url", "b64url", "url64"},
+		encode:   encodeWith(base64.URLEncoding.EncodeToString),
+		decode:   decodeWith(base64.URLEncoding.DecodeString),
+	},
+	{
+		name:     "Base64 Raw",
+		keywords: []string{"base64raw", "b64raw"},
+		encode:   encodeWith(base64.RawStdEncoding.EncodeToString),
+		decode:   decodeWith(base64.RawStdEncoding.DecodeString),
+	},
+	{
+		name:     "Base64 Raw URL",
+		keywords: []string{"base64rawurl", "b64rawurl"},
+		encode:   encodeWith(base64.RawURLEncoding.EncodeToString),
+		decode:   decodeWith(base64.RawURLEncoding.DecodeString),
+	},
+	{
+		name:     "Base32",
+		keywords: []string{"base32", "b32"},
+		encode:   encodeWith(base32.StdEncoding.EncodeToString),
+		decode:   decodeWith(base32.StdEncoding.DecodeString),
+	},
+	{
+		name:     "Base58",
+		keywords: []string{"base58", "b58"},
+		encode:   encodeBase58,
+		decode:   decodeBase58,
+	},
+	{
+		name:     "Hex",
+		keywords: []string{"hex"},
+		encode:   encodeWith(hex.EncodeToString),
+		decode:   decodeWith(hex.DecodeString),
+	},
+	{
+		name:     "URL Query",
+		keywords: []string{"url", "urlencode", "urlquery"},
+		encode:   escapeWith(url.QueryEscape),
+		decode:   unescapeWith(url.QueryUnescape),
+	},
+	{
+		name:     "URL Path",
+		keywords: []string{"urlpath"},
+		encode:   escapeWith(url.PathEscape),
+		decode:   unescapeWith(url.PathUnescape),
+	},
+	{
+		name:     "HTML Entities",
+		keywords: []string{"htmlentities", "entities"},
+		encode:   escapeWith(html.EscapeString),
+		decode:   escapeWith(html.UnescapeString),
+	},
+	{
+		name:     "Quoted-Printable",
+		keywords: []string{"qp", "quotedprintable"},
+		encode:   encodeQuotedPrintable,
+		decode: func(data []byte) ([]byte, error) {
+			return io.ReadAll(quotedprintable.NewReader(bytes.NewReader(data)))
+		},
+	},
+	{
+		name:     "Punycode",
+		keywords: []string{"punycode", "idna"},
+		encode:   unescapeWith(idna.Punycode.ToASCII),
+		decode:   unescapeWith(idna.Punycode.ToUnicode),
+	},
+	{
+		name:     "ROT13",
+		keywords: []string{"rot13"},
+		encode:   rot13,
+	},
+	{
+		name:     "Unicode Escapes",
+		keywords: []string{"unicode", "uescape"},
+		encode:   escapeUnicode,
+		decode:   unescapeUnicode,
+	},
+	{
+		name:     "Gzip + Base64",
+		keywords: []string{"gzip", "gz"},
+		encode:   encodeGzip,
+		decode:   decodeGzip,
+	},
+}
+
+// matchCodecs returns the codecs a keyword selects and whether it named one
+// exactly. A keyword naming a codec selects only that codec; otherwise
+// keywords of three or more letters select every codec with a keyword they
+// start, e.g. "base" selects all base encodings.
+func matchCodecs(keyword string) ([]codec, bool) {
+	keyword = strings.ToLower(keyword)
+	if keyword == "" {
+		return nil, false
+	}
+
+	for _, c := range codecs {
+		for _, k := range c.keywords {
+			if k == keyword {
+				return []codec{c}, true
+			}
+		}
+	}
+
+	if len(keyword) < 3 {
+		return nil, false
+	}
+
+	var matched []codec
+	for _, c := range codecs {
+		for _, k := range c.keywords {
+			if strings.HasPrefix(k, keyword) {
+				matched = append(matched, c)
+				break
+			}
+		}
+	}
+	return matched, false
+}
+
+// selectCodecs returns the codecs a query selects. A partial keyword only
+// selects codecs when text follows it, so an ordinary word typed on its own
+// isn't taken as a command to transform the clipboard.
+func selectCodecs(keyword, text string) []codec {
+	matched, exact := matchCodecs(keyword)
+	if !exact && strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return matched
+}
+
+// encodeWith adapts a standard library encoding function to a codec
+func encodeWith(encode func([]byte) string) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		return []byte(encode(data)), nil
+	}
+}
+
+// decodeWith adapts a standard library decoding function to a codec. The
+// input may be wrapped across lines, so all whitespace is removed first.
+func decodeWith(decode func(string) ([]byte, error)) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		return decode(removeSpaces(string(data)))
+	}
+}
+
+// escapeWith adapts a string escaping function to a codec
+func escapeWith(escape func(string) string) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		return []byte(escape(string(data))), nil
+	}
+}
+
+// unescapeWith adapts a string conversion that can fail to a codec,
+// ignoring whitespace around the input
+func unescapeWith(unescape func(string) (string, error)) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		text, err := unescape(strings.TrimSpace(string(data)))
+		return []byte(text), err
+	}
+}
+
+// removeSpaces removes all whitespace from text
+func removeSpaces(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// base58Alphabet is the Bitcoin base58 alphabet, which leaves out 0, O, I and l
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeBase58 encodes data as base58. Leading zero bytes are written as
+// leading 1s, as they would otherwise be lost in the conversion to a number.
+func encodeBase58(data []byte) ([]byte, error) {
+	if len(data) > maxBase58Size {
+		return nil, errBase58TooLarge
+	}
+
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var digits []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base58Alphabet[mod.Int64()])
+	}
+	for range zeros {
+		digits = append(digits, base58Alphabet[0])
+	}
+
+	// The digits were written least significant first
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return digits, nil
+}
+
+// decodeBase58 decodes base58 text
+func decodeBase58(data []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	if len(text) > maxBase58Size {
+		return nil, errBase58TooLarge
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i, r := range text {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	zeros := 0
+	for zeros < len(text) && text[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// encodeQuotedPrintable encodes data as quoted-printable, as used in email
+func encodeQuotedPrintable(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rot13 rotates the letters of the alphabet by 13 places, which also undoes it
+func rot13(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		switch {
+		case b >= 'a' && b <= 'z':
+			b = 'a' + (b-'a'+13)%26
+		case b >= 'A' && b <= 'Z':
+			b = 'A' + (b-'A'+13)%26
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+// escapeUnicode writes characters outside printable ASCII as \uXXXX escapes,
+// using surrogate pairs above U+FFFF as JSON and JavaScript do. Backslashes
+// are doubled so the result decodes back to the input.
+func escapeUnicode(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("input is not valid UTF-8")
+	}
+
+	var b strings.Builder
+	for _, r := range string(data) {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r >= 0x20 && r < 0x7f, r == '\n', r == '\t':
+			b.WriteRune(r)
+		case r > 0xffff:
+			high, low := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, `\u%04x\u%04x`, high, low)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// unescapeUnicode decodes \uXXXX (including surrogate pairs), \u{X...} and
+// \UXXXXXXXX escapes. Other backslashes are kept as they are.
+func unescapeUnicode(data []byte) ([]byte, error) {
+	text := string(data)
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] != '\\' || i+1 >= len(text) {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		r, size, err := readEscape(text[i:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid escape at input byte %d: %w", i, err)
+		}
+		if size == 0 {
+			// Not a Unicode escape, so copy the backslash and what follows
+			b.WriteString(text[i : i+2])
+			i += 2
+			continue
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return []byte(b.String()), nil
+}
+
+// readEscape reads the escape at the start of text, returning its character
+// and length, or a length of 0 if it isn't a Unicode escape
+func readEscape(text string) (rune, int, error) {
+	switch text[1] {
+	case '\\':
+		return '\\', 2, nil
+	case 'U':
+		r, err := parseCodePoint(text[2:min(10, len(text))], 8)
+		return r, 10, err
+	case 'u':
+		if strings.HasPrefix(text, `\u{`) {
+			end := strings.IndexByte(text, '}')
+			if end < 0 {
+				return 0, 0, fmt.Errorf("unterminated \\u{")
+			}
+			r, err := parseCodePoint(text[3:end], end-3)
+			return r, end + 1, err
+		}
+
+		r, err := parseCodePoint(text[2:min(6, len(text))], 4)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// A high surrogate is followed by a low one, together encoding a
+		// character above U+FFFF
+		if utf16.IsSurrogate(r) && len(text) >= 12 && text[6:8] == `\u` {
+			if low, err := parseCodePoint(text[8:12], 4); err == nil {
+				if combined := utf16.DecodeRune(r, low); combined != unicode.ReplacementChar {
+					return combined, 12, nil
+				}
+			}
+		}
+		return r, 6, nil
+	}
+	return 0, 0, nil
+}
+
+// parseCodePoint parses a code point of exactly the given number of hex digits
+func parseCodePoint(digits string, length int) (rune, error) {
+	if length == 0 || len(digits) != length {
+		return 0, fmt.Errorf("expected %d hex digits", length)
+	}
+
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return 0, fmt.Errorf("invalid code point %q", digits)
+	}
+	return rune(n), nil
+}
+
+// encodeGzip compresses data with gzip and encodes it as base64, the usual
+// way compressed data is passed around as text
+func encodeGzip(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// decodeGzip decodes base64 and decompresses the gzip data it contains
+func decodeGzip(data []byte) ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(removeSpaces(string(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed data is larger than %d MB", maxDecompressedSize>>20)
+	}
+	return out, nil
+}
@@ -0,0 +1,169 @@
+package encodedecode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	inputs := map[string]string{
+		"ascii":   "hello world",
+		"symbols": "a+b=c & d/e?f=<g> \"h\" 'i' 100% \\ ~",
+		"unicode": "héllo wörld 日本語 🎉",
+		"binary":  "\x00\x00\x01\xff\xfe",
+		"empty":   "",
+	}
+
+	for _, c := range codecs {
+		for name, input := range inputs {
+			t.Run(c.name+"/"+name, func(t *testing.T) {
+				// Punycode and Unicode escapes only apply to text
+				if name == "binary" && (c.name == "Punycode" || c.name == "Unicode Escapes") {
+					t.Skip("text only codec")
+				}
+				// Punycode encodes a single label, without spaces or symbols
+				if c.name == "Punycode" && (name == "ascii" || name == "symbols") {
+					input = "bücher"
+				}
+
+				encoded, err := c.encode([]byte(input))
+				if err != nil {
+					t.Fatalf("encode(%q) error = %v", input, err)
+				}
+
+				decode := c.decode
+				if decode == nil {
+					decode = c.encode
+				}
+				decoded, err := decode(encoded)
+				if err != nil {
+					t.Fatalf("decode(%q) error = %v", encoded, err)
+				}
+				if !bytes.Equal(decoded, []byte(input)) {
+					t.Errorf("decode(encode(%q)) = %q via %q", input, decoded, encoded)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecEncodings(t *testing.T) {
+	tests := []struct {
+		keyword string
+		input   string
+		want    string
+	}{
+		{"base64", "hello?", "aGVsbG8/"},
+		{"base64url", "hello?", "aGVsbG8_"},
+		{"base64raw", "hi", "aGk"},
+		{"base64rawurl", "\xfb\xff", "-_8"},
+		{"base32", "hi", "NBUQ===="},
+		{"base58", "\x00hello", "1Cn8eVZg"},
+		{"hex", "hi", "6869"},
+		{"url", "a b&c", "a+b%26c"},
+		{"urlpath", "a b&c", "a%20b&c"},
+		{"htmlentities", `<a href="x">`, "&lt;a href=&#34;x&#34;&gt;"},
+		{"qp", "café", "caf=C3=A9"},
+		{"punycode", "bücher", "xn--bcher-kva"},
+		{"rot13", "Hello", "Uryyb"},
+		{"unicode", "é🎉\\", `\u00e9\ud83c\udf89\\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword, func(t *testing.T) {
+			matched, exact := matchCodecs(tt.keyword)
+			if len(matched) != 1 || !exact {
+				t.Fatalf("matchCodecs(%q) = %d codecs, want exactly one", tt.keyword, len(matched))
+			}
+			got, err := matched[0].encode([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s encode(%q) = %q, want %q", matched[0].name, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchCodecs(t *testing.T) {
+	tests := []struct {
+		keyword string
+		want    []string
+		exact   bool
+	}{
+		{"base64", []string{"Base64"}, true},
+		{"B64", []string{"Base64"}, true},
+		{"url", []string{"URL Query"}, true},
+		{"base", []string{"Base64", "Base64 URL", "Base64 Raw", "Base64 Raw URL", "Base32", "Base58"}, false},
+		{"base64r", []string{"Base64 Raw", "Base64 Raw URL"}, false},
+		{"html", []string{"HTML Entities"}, false},
+		{"quoted", []string{"Quoted-Printable"}, false},
+		{"ba", nil, false},
+		{"use", nil, false},
+		{"path", nil, false},
+		{"query", nil, false},
+		{"code", nil, false},
+		{"bse", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword, func(t *testing.T) {
+			matched, exact := matchCodecs(tt.keyword)
+			var names []string
+			for _, c := range matched {
+				names = append(names, c.name)
+			}
+			if exact != tt.exact || len(names) != len(tt.want) {
+				t.Fatalf("matchCodecs(%q) = %q, %v, want %q, %v", tt.keyword, names, exact, tt.want, tt.exact)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("matchCodecs(%q) = %q, want %q", tt.keyword, names, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCanHandle(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"base64 hello", true},
+		{"base64", true},
+		{"hex", true},
+		{"base hello", true},
+		{"html <b>", true},
+		{"jwt", true},
+		{"clip", true},
+		{"base", false},
+		{"html", false},
+		{"use the force", false},
+		{"path", false},
+		{"path to file", false},
+		{"query", false},
+		{"codesign", false},
+		{"", false},
+	}
+
+	e := &EncoderDecoder{}
+	for _, tt := range tests {
+		if got := e.CanHandle(tt.query); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestBase58SizeLimit(t *testing.T) {
+	large := bytes.Repeat([]byte{1}, maxBase58Size+1)
+	if _, err := encodeBase58(large); err != errBase58TooLarge {
+		t.Errorf("encodeBase58(%d bytes) error = %v, want %v", len(large), err, errBase58TooLarge)
+	}
+	if _, err := decodeBase58(bytes.Repeat([]byte{'2'}, maxBase58Size+1)); err != errBase58TooLarge {
+		t.Errorf("decodeBase58(%d bytes) error = %v, want %v", maxBase58Size+1, err, errBase58TooLarge)
+	}
+}
@@ -0,0 +1,424 @@
+package encodedecode
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// minConfidence is the confidence below which a detected format isn't shown
+const minConfidence = 0.25
+
+// detectKeywords are the keywords that detect the input's format and show
+// every transform that applies to it
+var detectKeywords = map[string]bool{
+	"clip":      true,
+	"clipboard": true,
+	"detect":    true,
+}
+
+// detector recognises text in a format and decodes it
+type detector struct {
+	name string
+	// confidence returns how likely text is to be in the format, from 0 for
+	// not at all to 1 for certain. Text is trimmed of surrounding whitespace.
+	confidence func(text string) float64
+	decode     func(text string) ([]byte, error)
+}
+
+// detection is a format text was recognised in and its decoded form
+type detection struct {
+	name       string
+	confidence float64
+	output     []byte
+}
+
+// detectors are the formats input is checked for. Add a detector here to
+// have "clip" recognise a new format.
+var detectors = []detector{
+	{name: "JWT", confidence: jwtConfidence, decode: decodeJWTSegments},
+	{name: "JSON", confidence: jsonConfidence, decode: indentJSON},
+	{name: "UUID", confidence: uuidConfidence, decode: describeUUID},
+	{name: "Unix timestamp", confidence: epochConfidence, decode: decodeEpoch},
+	{name: "Gzip + Base64", confidence: gzipConfidence, decode: func(text string) ([]byte, error) {
+		return decodeGzip([]byte(text))
+	}},
+	{name: "Base64", confidence: base64Confidence, decode: decodeAnyBase64},
+	{name: "Base32", confidence: base32Confidence, decode: func(text string) ([]byte, error) {
+		return base32.StdEncoding.DecodeString(text)
+	}},
+	{name: "Hex", confidence: hexConfidence, decode: func(text string) ([]byte, error) {
+		return hex.DecodeString(removeSpaces(text))
+	}},
+	{name: "URL encoding", confidence: urlConfidence, decode: func(text string) ([]byte, error) {
+		decoded, err := url.QueryUnescape(text)
+		return []byte(decoded), err
+	}},
+	{name: "HTML entities", confidence: htmlConfidence, decode: func(text string) ([]byte, error) {
+		return []byte(html.UnescapeString(text)), nil
+	}},
+	{name: "Unicode escapes", confidence: unicodeEscapeConfidence, decode: func(text string) ([]byte, error) {
+		return unescapeUnicode([]byte(text))
+	}},
+	{name: "Punycode", confidence: punycodeConfidence, decode: func(text string) ([]byte, error) {
+		decoded, err := idna.Punycode.ToUnicode(text)
+		return []byte(decoded), err
+	}},
+}
+
+// detect returns the formats text may be in, most likely first
+func detect(text string) []detection {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	var found []detection
+	for _, d := range detectors {
+		confidence := d.confidence(text)
+		if confidence < minConfidence {
+			continue
+		}
+
+		output, err := d.decode(text)
+		if err != nil {
+			continue
+		}
+		found = append(found, detection{name: d.name, confidence: confidence, output: output})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].confidence > found[j].confidence
+	})
+	return found
+}
+
+// isPrintable reports whether data is text that can be shown, rather than binary
+func isPrintable(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// jwtPattern matches the three base64url segments of a JSON Web Token
+var jwtPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`)
+
+// jwtConfidence recognises JSON Web Tokens by their JSON header
+func jwtConfidence(text string) float64 {
+	if !jwtPattern.MatchString(text) {
+		return 0
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(text, ".")[0])
+	if err != nil || !json.Valid(header) {
+		return 0
+	}
+	if bytes.Contains(header, []byte(`"alg"`)) {
+		return 0.99
+	}
+	return 0.9
+}
+
+// decodeJWTSegments decodes a JWT's header and claims to JSON
+func decodeJWTSegments(text string) ([]byte, error) {
+	parts := strings.Split(text, ".")
+
+	var segments []string
+	for _, part := range parts[:2] {
+		decoded, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, string(decoded))
+	}
+	return []byte(strings.Join(segments, "\n")), nil
+}
+
+// jsonConfidence recognises JSON objects and arrays
+func jsonConfidence(text string) float64 {
+	if (text[0] == '{' || text[0] == '[') && json.Valid([]byte(text)) {
+		return 0.95
+	}
+	return 0
+}
+
+// indentJSON pretty prints JSON
+func indentJSON(text string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(text), "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// uuidPattern matches UUIDs, optionally in braces or with a urn:uuid: prefix
+var uuidPattern = regexp.MustCompile(`^(?i:urn:uuid:)?\{?([0-9a-fA-F]{8})-([0-9a-fA-F]{4})-([0-9a-fA-F]{4})-([0-9a-fA-F]{4})-([0-9a-fA-F]{12})\}?$`)
+
+// uuidConfidence recognises UUIDs
+func uuidConfidence(text string) float64 {
+	if uuidPattern.MatchString(text) {
+		return 0.98
+	}
+	return 0
+}
+
+// uuidVersions describes each UUID version
+var uuidVersions = map[byte]string{
+	1: "time-based",
+	2: "DCE security",
+	3: "MD5 name-based",
+	4: "random",
+	5: "SHA-1 name-based",
+	6: "reordered time-based",
+	7: "Unix time-based",
+	8: "custom",
+}
+
+// describeUUID gives a UUID's canonical form, version and, for time-based
+// versions, when it was created
+func describeUUID(text string) ([]byte, error) {
+	m := uuidPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil, fmt.Errorf("invalid UUID")
+	}
+
+	canonical := strings.ToLower(strings.Join(m[1:], "-"))
+	raw, err := hex.DecodeString(strings.Join(m[1:], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	version := raw[6] >> 4
+	parts := []string{canonical}
+	switch {
+	case canonical == "00000000-0000-0000-0000-000000000000":
+		parts = append(parts, "nil UUID")
+	case raw[8]&0xc0 != 0x80:
+		parts = append(parts, "non-RFC 9562 variant")
+	case uuidVersions[version] != "":
+		parts = append(parts, fmt.Sprintf("version %d (%s)", version, uuidVersions[version]))
+	default:
+		parts = append(parts, fmt.Sprintf("version %d", version))
+	}
+
+	if created, ok := uuidTime(raw, version); ok {
+		parts = append(parts, "created "+created.Local().Format(time.RFC3339))
+	}
+	return []byte(strings.Join(parts, ", ")), nil
+}
+
+// gregorianOffset is the number of 100ns intervals between the start of the
+// Gregorian calendar, which UUID times count from, and the Unix epoch
+const gregorianOffset = 122192928000000000
+
+// uuidTime returns when a time-based UUID was created
+func uuidTime(raw []byte, version byte) (time.Time, bool) {
+	switch version {
+	case 1:
+		low := uint64(raw[0])<<24 | uint64(raw[1])<<16 | uint64(raw[2])<<8 | uint64(raw[3])
+		mid := uint64(raw[4])<<8 | uint64(raw[5])
+		high := uint64(raw[6]&0x0f)<<8 | uint64(raw[7])
+		ticks := high<<48 | mid<<32 | low
+		return time.Unix(0, int64(ticks-gregorianOffset)*100), true
+	case 6:
+		high := uint64(raw[0])<<24 | uint64(raw[1])<<16 | uint64(raw[2])<<8 | uint64(raw[3])
+		mid := uint64(raw[4])<<8 | uint64(raw[5])
+		low := uint64(raw[6]&0x0f)<<8 | uint64(raw[7])
+		ticks := high<<28 | mid<<12 | low
+		return time.Unix(0, int64(ticks-gregorianOffset)*100), true
+	case 7:
+		var millis int64
+		for _, b := range raw[:6] {
+			millis = millis<<8 | int64(b)
+		}
+		return time.UnixMilli(millis), true
+	}
+	return time.Time{}, false
+}
+
+// epochUnits convert Unix timestamps to times by their number of digits.
+// Each unit is converted directly, as nanoseconds overflow after 2262.
+var epochUnits = map[int]func(int64) time.Time{
+	10: func(n int64) time.Time { return time.Unix(n, 0) },
+	13: time.UnixMilli,
+	16: time.UnixMicro,
+	19: func(n int64) time.Time { return time.Unix(0, n) },
+}
+
+// epochConfidence recognises Unix timestamps in seconds, milliseconds,
+// microseconds or nanoseconds between 2001 and 2286
+func epochConfidence(text string) float64 {
+	if _, ok := epochUnits[len(text)]; !ok || strings.Trim(text, "0123456789") != "" {
+		return 0
+	}
+	if text[0] == '0' {
+		return 0
+	}
+	return 0.7
+}
+
+// decodeEpoch converts a Unix timestamp to a local time
+func decodeEpoch(text string) ([]byte, error) {
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	t := epochUnits[len(text)](n)
+	return []byte(t.Local().Format(time.RFC3339Nano)), nil
+}
+
+// gzipConfidence recognises base64 encoded gzip data by its magic number,
+// which always encodes to H4sI
+func gzipConfidence(text string) float64 {
+	if strings.HasPrefix(text, "H4sI") {
+		return 0.97
+	}
+	return 0
+}
+
+// base64Pattern matches standard or URL-safe base64, with or without padding
+var base64Pattern = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// base64Confidence recognises base64. Decoding to readable text makes it
+// likely; text that is also a plain word or number makes it unlikely.
+func base64Confidence(text string) float64 {
+	text = removeSpaces(text)
+	if len(text) < 4 || !base64Pattern.MatchString(text) {
+		return 0
+	}
+
+	decoded, err := decodeAnyBase64(text)
+	if err != nil {
+		return 0
+	}
+
+	confidence := 0.4
+	if isPrintable(decoded) {
+		confidence = 0.8
+	}
+	if strings.HasSuffix(text, "=") {
+		confidence += 0.1
+	}
+
+	// Hex strings are also valid base64, as are words, which have no digits
+	// or padding
+	isHex := strings.Trim(text, "0123456789abcdefABCDEF") == ""
+	isWord := strings.IndexFunc(text, unicode.IsDigit) < 0 && !strings.HasSuffix(text, "=")
+	if isHex || isWord {
+		confidence -= 0.3
+	}
+	return confidence
+}
+
+// decodeAnyBase64 decodes standard or URL-safe base64, with or without padding
+func decodeAnyBase64(text string) ([]byte, error) {
+	text = removeSpaces(text)
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(text, "-_") {
+		encoding = base64.URLEncoding
+	}
+	if !strings.HasSuffix(text, "=") && len(text)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	return encoding.DecodeString(text)
+}
+
+// base32Pattern matches padded base32
+var base32Pattern = regexp.MustCompile(`^[A-Z2-7]+=*$`)
+
+// base32Confidence recognises base32 that decodes to readable text
+func base32Confidence(text string) float64 {
+	if len(text)%8 != 0 || !base32Pattern.MatchString(text) {
+		return 0
+	}
+
+	decoded, err := base32.StdEncoding.DecodeString(text)
+	if err != nil || !isPrintable(decoded) {
+		return 0
+	}
+	return 0.7
+}
+
+// hexConfidence recognises hex. Decoding to readable text makes it likely;
+// text that is only digits is more likely a number.
+func hexConfidence(text string) float64 {
+	text = removeSpaces(text)
+	if len(text) < 4 || len(text)%2 != 0 || strings.Trim(text, "0123456789abcdefABCDEF") != "" {
+		return 0
+	}
+
+	if strings.Trim(text, "0123456789") == "" {
+		return 0.2
+	}
+
+	decoded, err := hex.DecodeString(text)
+	if err == nil && isPrintable(decoded) {
+		return 0.85
+	}
+	return 0.6
+}
+
+// percentEscape matches a URL percent escape such as %20
+var percentEscape = regexp.MustCompile(`%[0-9a-fA-F]{2}`)
+
+// urlConfidence recognises URL encoded text by its percent escapes
+func urlConfidence(text string) float64 {
+	if !percentEscape.MatchString(text) {
+		return 0
+	}
+	return 0.85
+}
+
+// htmlEntity matches named and numeric HTML entities such as &amp; or &#233;
+var htmlEntity = regexp.MustCompile(`&(?:[a-zA-Z]+|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+// htmlConfidence recognises text with HTML entities
+func htmlConfidence(text string) float64 {
+	if !htmlEntity.MatchString(text) || html.UnescapeString(text) == text {
+		return 0
+	}
+	return 0.8
+}
+
+// unicodeEscape matches Unicode escapes such as \u00e9
+var unicodeEscape = regexp.MustCompile(`\\(?:u[0-9a-fA-F]{4}|u\{[0-9a-fA-F]{1,6}\}|U[0-9a-fA-F]{8})`)
+
+// unicodeEscapeConfidence recognises text with Unicode escapes
+func unicodeEscapeConfidence(text string) float64 {
+	if unicodeEscape.MatchString(text) {
+		return 0.85
+	}
+	return 0
+}
+
+// punycodeConfidence recognises internationalized domain names in their
+// ASCII form, such as xn--mnchen-3ya.de
+func punycodeConfidence(text string) float64 {
+	lower := strings.ToLower(text)
+	if strings.HasPrefix(lower, "xn--") || strings.Contains(lower, ".xn--") {
+		return 0.9
+	}
+	return 0
+}
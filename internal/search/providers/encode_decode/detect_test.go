@@ -0,0 +1,61 @@
+package encodedecode
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeEpoch(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"1700000000123", time.UnixMilli(1700000000123)},
+		{"1700000000123456", time.UnixMicro(1700000000123456)},
+		{"1700000000123456789", time.Unix(0, 1700000000123456789)},
+		// Past 2262, where nanoseconds overflow an int64
+		{"9999999999", time.Date(2286, 11, 20, 17, 46, 39, 0, time.UTC)},
+		{"9999999999999", time.Date(2286, 11, 20, 17, 46, 39, 999e6, time.UTC)},
+		{"9999999999999999", time.Date(2286, 11, 20, 17, 46, 39, 999999e3, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if confidence := epochConfidence(tt.text); confidence == 0 {
+				t.Errorf("epochConfidence(%q) = 0, want a timestamp", tt.text)
+			}
+
+			decoded, err := decodeEpoch(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := time.Parse(time.RFC3339Nano, string(decoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("decodeEpoch(%q) = %v, want %v", tt.text, got.UTC(), tt.want.UTC())
+			}
+		})
+	}
+
+	// 19 digits past the largest int64 can't be read as nanoseconds
+	if _, err := decodeEpoch("9999999999999999999"); err == nil {
+		t.Error("decodeEpoch(9999999999999999999) error = nil, want an error")
+	}
+	for _, text := range []string{"123456789", "0700000000", "17000000001", "1700000000.5"} {
+		if confidence := epochConfidence(text); confidence != 0 {
+			t.Errorf("epochConfidence(%q) = %v, want 0", text, confidence)
+		}
+	}
+}
+
+func TestDetectResultsTooLarge(t *testing.T) {
+	in := input{text: strings.Repeat("a", maxEncodeAllSize+1), source: "clipboard"}
+	results := detectResults(in)
+	if len(results) != 1 || results[0].Path != "detect:too_large" {
+		t.Errorf("detectResults(%d bytes) = %+v, want a too large result", len(in.text), results)
+	}
+}
@@ -1,20 +1,29 @@
 package encodedecode
 
 import (
-	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/MordFustang21/marvin-go/internal/search"
-	"github.com/lithammer/fuzzysearch/fuzzy"
 	"golang.design/x/clipboard"
 )
 
 var _ search.Provider = (*EncoderDecoder)(nil)
 
+const (
+	// maxPreviewLength is the number of characters of input and output shown in results
+	maxPreviewLength = 60
+	// maxEncodeAllSize is the largest input encoded with every codec when
+	// detecting its format, as that runs on every keystroke
+	maxEncodeAllSize = 64 << 10
+)
+
 // EncoderDecoder is a search provider that handles encoding and decoding tasks.
+// The text after the keyword is transformed, as in "base64 hello world", or
+// the clipboard if there is none.
 type EncoderDecoder struct {
 	priority int
 }
@@ -31,9 +40,17 @@ func NewProvider(priority int) (*EncoderDecoder, error) {
 	}, nil
 }
 
+// splitQuery splits a query into its keyword and the text after it
+func splitQuery(query string) (keyword, text string) {
+	keyword, text, _ = strings.Cut(strings.TrimLeft(query, " \t"), " ")
+	return keyword, text
+}
+
 // CanHandle implements search.Provider.
 func (e *EncoderDecoder) CanHandle(query string) bool {
-	canHandle := fuzzy.Match(query, "base64") || fuzzy.Match(query, "hex")
+	keyword, text := splitQuery(query)
+	lower := strings.ToLower(keyword)
+	canHandle := lower == jwtKeyword || detectKeywords[lower] || len(selectCodecs(keyword, text)) > 0
 	slog.Debug("CanHandle check",
 		slog.String("query", query),
 		slog.Bool("result", canHandle))
@@ -58,112 +75,185 @@ func (e *EncoderDecoder) Priority() int {
 	return e.priority
 }
 
+// input is the text being transformed and where it came from
+type input struct {
+	text   string
+	source string
+}
+
+// readInput returns the text typed after the keyword, or the clipboard if
+// nothing was typed
+func readInput(text string) input {
+	if strings.TrimSpace(text) != "" {
+		return input{text: text, source: "query"}
+	}
+	return input{text: string(clipboard.Read(clipboard.FmtText)), source: "clipboard"}
+}
+
+// describe says where the input came from and previews it
+func (in input) describe() string {
+	return fmt.Sprintf("From %s: %s", in.source, preview([]byte(in.text)))
+}
+
 // Search implements search.Provider.
 func (e *EncoderDecoder) Search(query string) ([]search.SearchResult, error) {
-	clipText := string(clipboard.Read(clipboard.FmtText))
+	keyword, text := splitQuery(query)
+	if strings.ToLower(keyword) == jwtKeyword {
+		return jwtResults(text, time.Now()), nil
+	}
+
+	in := readInput(text)
 	slog.Debug("Encode/Decode provider search",
 		slog.String("query", query),
-		slog.String("clipboardLength", fmt.Sprintf("%d", len(clipText))))
+		slog.String("source", in.source),
+		slog.Int("inputLength", len(in.text)))
 
-	if len(clipText) == 0 {
+	if len(in.text) == 0 {
 		return []search.SearchResult{{
-			Title:       "No text in clipboard",
-			Description: "Copy some text to encode or decode",
+			Title:       "Nothing to encode or decode",
+			Description: fmt.Sprintf("Type text after %q or copy some to the clipboard", keyword),
 			Type:        search.TypeSystem,
 			Path:        "clipboard:empty",
 		}}, nil
 	}
 
+	if detectKeywords[strings.ToLower(keyword)] {
+		return detectResults(in), nil
+	}
+
+	// Decode failures are only worth showing when a single codec was asked for
+	selected := selectCodecs(keyword, text)
 	var results []search.SearchResult
-	// Show potential base64 operations.
-	if fuzzy.Match(query, "base64") {
-		// Add encode option
-		encoded := base64.StdEncoding.EncodeToString([]byte(clipText))
-		results = append(results, search.SearchResult{
-			Title:       "Base64 Encode",
-			Description: fmt.Sprintf("Result: %s", encoded),
-			Type:        search.TypeSystem,
-			Path:        "base64:encode",
-			Action: func() {
-				clipboard.Write(clipboard.FmtText, []byte(encoded))
-			},
-		})
+	for _, c := range selected {
+		results = append(results, c.results(in, len(selected) == 1)...)
+	}
+
+	slog.Debug("Encode/Decode results", slog.Int("count", len(results)))
+	return results, nil
+}
 
-		// Try to decode - clean up the input first
-		cleanClipText := strings.TrimSpace(clipText)
-		slog.Debug("Attempting base64 decode", slog.String("input", cleanClipText[:min(20, len(cleanClipText))]))
-
-		decoded, err := base64.StdEncoding.DecodeString(cleanClipText)
-		if err == nil {
-			decodedStr := string(decoded)
-			slog.Debug("Base64 decode successful",
-				slog.String("result", decodedStr[:min(20, len(decodedStr))]))
-
-			results = append(results, search.SearchResult{
-				Title:       "Base64 Decode",
-				Description: fmt.Sprintf("Result: %s", decodedStr),
-				Type:        search.TypeSystem,
-				Path:        "base64:decode",
-				Action: func() {
-					clipboard.Write(clipboard.FmtText, decoded)
-				},
-			})
-		} else {
-			slog.Debug("Base64 decode failed", slog.Any("error", err))
-			results = append(results, search.SearchResult{
-				Title:       "Base64 Decode Failed",
-				Description: "Invalid base64 input in clipboard: " + err.Error(),
-				Type:        search.TypeSystem,
-				Path:        "base64:decode_failed",
-			})
+// results creates the results of encoding and decoding the input with a codec
+func (c codec) results(in input, showFailure bool) []search.SearchResult {
+	id := c.keywords[0]
+	data := []byte(in.text)
+
+	if c.decode == nil {
+		result, err := c.encodeResult(in)
+		if err != nil {
+			return c.failure("", id, in, err, showFailure)
 		}
+		return []search.SearchResult{result}
+	}
+
+	var results []search.SearchResult
+	if result, err := c.encodeResult(in); err == nil {
+		results = append(results, result)
+	} else {
+		results = append(results, c.failure("Encode", id, in, err, showFailure)...)
 	}
 
-	if fuzzy.Match(query, "hex") {
-		// Add encode option
-		encoded := hex.EncodeToString([]byte(clipText))
-		results = append(results, search.SearchResult{
-			Title:       "Hex Encode",
-			Description: fmt.Sprintf("Result: %s", encoded),
+	slog.Debug("Attempting decode", slog.String("codec", c.name), slog.String("input", in.text[:min(20, len(in.text))]))
+	if decoded, err := c.decode(data); err == nil {
+		slog.Debug("Decode successful", slog.String("codec", c.name), slog.Int("length", len(decoded)))
+		results = append(results, transformResult(c.name+" Decode", in, decoded, id+":decode"))
+	} else {
+		slog.Debug("Decode failed", slog.String("codec", c.name), slog.Any("error", err))
+		results = append(results, c.failure("Decode", id, in, err, showFailure)...)
+	}
+
+	return results
+}
+
+// encodeResult creates the result of encoding the input with a codec
+func (c codec) encodeResult(in input) (search.SearchResult, error) {
+	encoded, err := c.encode([]byte(in.text))
+	if err != nil {
+		return search.SearchResult{}, err
+	}
+
+	name := c.name
+	if c.decode != nil {
+		name += " Encode"
+	}
+	return transformResult(name, in, encoded, c.keywords[0]+":encode"), nil
+}
+
+// detectResults creates a result decoding each format the input may be in,
+// most likely first, followed by encoding it with every codec
+func detectResults(in input) []search.SearchResult {
+	var results []search.SearchResult
+	for _, d := range detect(in.text) {
+		result := transformResult(d.name, in, d.output, "detect:"+d.name)
+		result.Description = fmt.Sprintf("Looks like %s (%.0f%% confidence). %s", d.name, d.confidence*100, result.Description)
+		results = append(results, result)
+	}
+
+	if len(in.text) > maxEncodeAllSize {
+		return append(results, search.SearchResult{
+			Title:       "Too large to preview every encoding",
+			Description: fmt.Sprintf("The input from %s is %d KB. Type a codec keyword such as base64 to encode it", in.source, len(in.text)>>10),
 			Type:        search.TypeSystem,
-			Path:        "hex:encode",
-			Action: func() {
-				clipboard.Write(clipboard.FmtText, []byte(encoded))
-			},
+			Path:        "detect:too_large",
 		})
+	}
 
-		// Try to decode - clean up the input first
-		cleanClipText := strings.TrimSpace(clipText)
-		slog.Debug("Attempting hex decode", slog.String("input", cleanClipText[:min(20, len(cleanClipText))]))
-
-		decoded, err := hex.DecodeString(cleanClipText)
-		if err == nil {
-			decodedStr := string(decoded)
-			slog.Debug("Hex decode successful",
-				slog.String("result", decodedStr[:min(20, len(decodedStr))]))
-
-			results = append(results, search.SearchResult{
-				Title:       "Hex Decode",
-				Description: fmt.Sprintf("Result: %s", decodedStr),
-				Type:        search.TypeSystem,
-				Path:        "hex:decode",
-				Action: func() {
-					clipboard.Write(clipboard.FmtText, decoded)
-				},
-			})
-		} else {
-			slog.Debug("Hex decode failed", slog.Any("error", err))
-			results = append(results, search.SearchResult{
-				Title:       "Hex Decode Failed",
-				Description: "Invalid hex input in clipboard: " + err.Error(),
-				Type:        search.TypeSystem,
-				Path:        "hex:decode_failed",
-			})
+	for _, c := range codecs {
+		if result, err := c.encodeResult(in); err == nil {
+			results = append(results, result)
 		}
 	}
 
-	slog.Debug("Encode/Decode results", slog.Int("count", len(results)))
-	return results, nil
+	return results
+}
+
+// failure creates a result explaining why a transform failed, if it should be shown
+func (c codec) failure(action, id string, in input, err error, show bool) []search.SearchResult {
+	if !show {
+		return nil
+	}
+
+	title, path := c.name+" Failed", id+":failed"
+	if action != "" {
+		title = fmt.Sprintf("%s %s Failed", c.name, action)
+		path = fmt.Sprintf("%s:%s_failed", id, strings.ToLower(action))
+	}
+
+	return []search.SearchResult{{
+		Title:       title,
+		Description: fmt.Sprintf("Invalid %s input in %s: %s", c.name, in.source, err.Error()),
+		Type:        search.TypeSystem,
+		Path:        path,
+	}}
+}
+
+// transformResult creates a result previewing a transform's output that
+// copies it to the clipboard when chosen
+func transformResult(name string, in input, output []byte, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       fmt.Sprintf("%s: %s", name, preview(output)),
+		Description: in.describe() + ". Press Enter to copy the result",
+		Type:        search.TypeSystem,
+		Path:        path,
+		Action: func() {
+			clipboard.Write(clipboard.FmtText, output)
+		},
+	}
+}
+
+// preview shortens text to fit in a result, describing binary data by its size
+func preview(data []byte) string {
+	if len(data) > 0 && !isPrintable(data) {
+		if len(data) == 1 {
+			return "1 byte of binary data"
+		}
+		return fmt.Sprintf("%d bytes of binary data", len(data))
+	}
+
+	text := strings.Join(strings.Fields(string(data)), " ")
+	if utf8.RuneCountInString(text) > maxPreviewLength {
+		text = string([]rune(text)[:maxPreviewLength]) + "…"
+	}
+	return text
 }
 
 // Type implements search.Provider.
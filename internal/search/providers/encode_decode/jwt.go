@@ -0,0 +1,274 @@
+package encodedecode
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"golang.design/x/clipboard"
+)
+
+// jwtKeyword is the keyword that inspects a JSON Web Token
+const jwtKeyword = "jwt"
+
+// token is a decoded JSON Web Token
+type token struct {
+	raw    string
+	header []byte
+	claims []byte
+	// alg is the signing algorithm from the header, e.g. HS256
+	alg    string
+	parsed map[string]any
+}
+
+// parseToken decodes a JWT's header and claims. The signature is not checked.
+func parseToken(text string) (*token, error) {
+	text = strings.TrimSpace(text)
+	if !jwtPattern.MatchString(text) {
+		return nil, fmt.Errorf("a JWT has three parts separated by dots")
+	}
+	parts := strings.Split(text, ".")
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	// Numbers are kept as written, as float64 would round large timestamps
+	var parsed map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(claims))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+
+	return &token{raw: text, header: header, claims: claims, alg: h.Alg, parsed: parsed}, nil
+}
+
+// decodeSegment decodes a base64url JWT segment, tolerating padding
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+// time returns a time claim such as exp, which is in seconds since the epoch
+func (t *token) time(claim string) (time.Time, bool) {
+	n, ok := t.parsed[claim].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if seconds, err := n.Int64(); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+
+	// Fractional seconds are split so times past 2262 don't overflow
+	seconds, err := n.Float64()
+	if err != nil || math.IsNaN(seconds) || math.Abs(seconds) >= math.MaxInt64 {
+		return time.Time{}, false
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true
+}
+
+// hmacHashes are the hash functions of the HMAC signing algorithms
+var hmacHashes = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// verify checks the token's HMAC signature against a secret
+func (t *token) verify(secret string) (bool, error) {
+	newHash, ok := hmacHashes[t.alg]
+	if !ok {
+		return false, fmt.Errorf("%s signatures can't be checked with a secret", t.alg)
+	}
+
+	signingInput := t.raw[:strings.LastIndex(t.raw, ".")]
+	signature, err := decodeSegment(t.raw[len(signingInput)+1:])
+	if err != nil {
+		return false, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return hmac.Equal(mac.Sum(nil), signature), nil
+}
+
+// splitJWTQuery splits the text after the jwt keyword into the token and
+// secret. The token is read from the clipboard if the text doesn't start
+// with one, in which case all of the text is the secret.
+func splitJWTQuery(text string) (input, string) {
+	text = strings.TrimSpace(text)
+	first, rest, _ := strings.Cut(text, " ")
+	if jwtPattern.MatchString(first) {
+		return input{text: first, source: "query"}, strings.TrimSpace(rest)
+	}
+	return readInput(""), text
+}
+
+// jwtResults inspects a JWT, showing its header, claims and times, and
+// checking its signature if a secret is given
+func jwtResults(text string, now time.Time) []search.SearchResult {
+	in, secret := splitJWTQuery(text)
+
+	t, err := parseToken(in.text)
+	if err != nil {
+		return []search.SearchResult{{
+			Title:       "Not a JWT",
+			Description: fmt.Sprintf("Invalid token in %s: %s. Type a token after %q or copy one", in.source, err.Error(), jwtKeyword),
+			Type:        search.TypeSystem,
+			Path:        "jwt:invalid",
+		}}
+	}
+
+	summary := []string{fmt.Sprintf("%s token from %s", t.alg, in.source)}
+	status := "valid"
+	if exp, ok := t.time("exp"); ok && !now.Before(exp) {
+		status = "expired"
+		summary = append(summary, "expired "+formatAgo(exp, now))
+	} else if ok {
+		summary = append(summary, "expires "+formatAgo(exp, now))
+	}
+	if nbf, ok := t.time("nbf"); ok && now.Before(nbf) {
+		status = "not yet valid"
+		summary = append(summary, "valid "+formatAgo(nbf, now))
+	}
+	if sub, ok := t.parsed["sub"].(string); ok {
+		summary = append(summary, "subject "+sub)
+	}
+
+	results := []search.SearchResult{
+		jwtResult(fmt.Sprintf("JWT (%s)", status), strings.Join(summary, ", "), t.raw, "jwt:summary"),
+		jwtResult("JWT Header: "+preview(t.header), "Press Enter to copy the header as JSON", prettyJSON(t.header), "jwt:header"),
+		jwtResult("JWT Claims: "+preview(t.claims), "Press Enter to copy the claims as JSON", prettyJSON(t.claims), "jwt:claims"),
+	}
+
+	for _, claim := range []struct{ name, label string }{
+		{"exp", "Expires"},
+		{"iat", "Issued"},
+		{"nbf", "Not before"},
+	} {
+		when, ok := t.time(claim.name)
+		if !ok {
+			continue
+		}
+
+		text := when.Local().Format(time.RFC3339)
+		description := fmt.Sprintf("The %s claim, %s. Press Enter to copy", claim.name, formatAgo(when, now))
+		results = append(results, jwtResult(fmt.Sprintf("%s: %s", claim.label, text), description, text, "jwt:claim:"+claim.name))
+	}
+
+	return append(results, signatureResult(t, secret))
+}
+
+// signatureResult reports whether the token's signature matches the secret,
+// or why it can't be checked
+func signatureResult(t *token, secret string) search.SearchResult {
+	result := search.SearchResult{
+		Type: search.TypeSystem,
+		Path: "jwt:signature",
+	}
+
+	switch {
+	case strings.EqualFold(t.alg, "none"):
+		result.Title = "Unsigned token"
+		result.Description = "The token's algorithm is none, so anyone could have made it"
+	case hmacHashes[t.alg] == nil:
+		result.Title = "Signature not checked"
+		result.Description = fmt.Sprintf("%s signatures need the issuer's public key to check", t.alg)
+	case secret == "":
+		result.Title = "Signature not checked"
+		result.Description = fmt.Sprintf("Type the secret after %q and the token to check the %s signature", jwtKeyword, t.alg)
+	default:
+		valid, err := t.verify(secret)
+		switch {
+		case err != nil:
+			result.Title = "Signature not checked"
+			result.Description = err.Error()
+		case valid:
+			result.Title = "Signature valid"
+			result.Description = fmt.Sprintf("The %s signature matches the secret", t.alg)
+		default:
+			result.Title = "Signature invalid"
+			result.Description = fmt.Sprintf("The %s signature doesn't match the secret", t.alg)
+		}
+	}
+
+	return result
+}
+
+// jwtResult creates a result that copies text when chosen
+func jwtResult(title, description, text, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        path,
+		Action: func() {
+			clipboard.Write(clipboard.FmtText, []byte(text))
+		},
+	}
+}
+
+// prettyJSON indents JSON, returning it unchanged if it can't be
+func prettyJSON(data []byte) string {
+	indented, err := indentJSON(string(data))
+	if err != nil {
+		return string(data)
+	}
+	return string(indented)
+}
+
+// formatAgo describes a time relative to now, e.g. "in 5 minutes" or "3 days ago"
+func formatAgo(t, now time.Time) string {
+	// Count in Unix seconds, as a time.Duration stops at 292 years. They're
+	// subtracted as floats so claims near the int64 limits can't overflow.
+	seconds := float64(t.Unix()) - float64(now.Unix())
+	abs := math.Abs(seconds)
+
+	var text string
+	switch {
+	case abs < 60:
+		return "just now"
+	case abs < 60*60:
+		text = pluralize(int64(abs/60), "minute")
+	case abs < 24*60*60:
+		text = pluralize(int64(abs/(60*60)), "hour")
+	default:
+		text = pluralize(int64(abs/(24*60*60)), "day")
+	}
+
+	if seconds < 0 {
+		return text + " ago"
+	}
+	return "in " + text
+}
+
+// pluralize formats a count with its unit, e.g. "1 day" or "3 days"
+func pluralize(n int64, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,322 @@
+package filebrowser
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/ui/icons"
+	"github.com/MordFustang21/marvin-go/internal/util"
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+var _ search.ParentQueryProvider = (*Provider)(nil)
+
+// Provider is a search provider that browses the file system when the query
+// is a path such as ~/src/ or /etc/
+type Provider struct {
+	priority   int
+	maxResults int
+	homeDir    string
+}
+
+// NewProvider creates a new file browser provider
+func NewProvider(priority, maxResults int) *Provider {
+	if maxResults <= 0 {
+		maxResults = 50 // Default max results if invalid value provided
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		slog.Error("Failed to get home directory", slog.Any("error", err))
+	}
+
+	return &Provider{
+		priority:   priority,
+		maxResults: maxResults,
+		homeDir:    homeDir,
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "File Browser"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeFile
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// CanHandle returns whether the query is a path to browse
+func (p *Provider) CanHandle(query string) bool {
+	return strings.HasPrefix(query, "/") || query == "~" || strings.HasPrefix(query, "~/")
+}
+
+// ParentQuery returns the query for the parent directory when the query is a
+// directory ending in a slash, e.g. ~/src/marvin/ becomes ~/src/
+func (p *Provider) ParentQuery(query string) (string, bool) {
+	if !strings.HasSuffix(query, "/") || query == "/" || query == "~/" {
+		return "", false
+	}
+
+	parent := strings.TrimSuffix(query, "/")
+	idx := strings.LastIndex(parent, "/")
+	if idx < 0 {
+		return "", false
+	}
+
+	return parent[:idx+1], true
+}
+
+// Search lists the entries of the directory in the query that match the text after the last slash
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	if !p.CanHandle(query) {
+		return nil, nil
+	}
+
+	dirQuery, filter := splitQuery(query)
+	dir := p.expandHome(dirQuery)
+
+	entries, err := listEntries(dir, filter)
+	if err != nil {
+		return []search.SearchResult{
+			{
+				Title:       fmt.Sprintf("Cannot open %s", dirQuery),
+				Description: err.Error(),
+				Path:        "browse:error:" + dir,
+				Icon:        theme.ErrorIcon(),
+				Type:        search.TypeFile,
+			},
+		}, nil
+	}
+
+	results := []search.SearchResult{}
+
+	// Actions for the directory itself, or for an entry typed out in full,
+	// come first. While filtering they go after the matching entries.
+	target, targetQuery := dir, dirQuery
+	actionsFirst := filter == ""
+	for _, entry := range entries {
+		if entry.name == filter {
+			target = filepath.Join(dir, entry.name)
+			targetQuery = dirQuery + entry.name
+			actionsFirst = true
+			break
+		}
+	}
+
+	actions := p.pathActions(target, targetQuery)
+	if actionsFirst {
+		results = append(results, actions...)
+	}
+
+	for _, entry := range entries {
+		if len(results) >= p.maxResults {
+			break
+		}
+
+		results = append(results, p.createEntryResult(dir, dirQuery, entry))
+	}
+
+	if !actionsFirst {
+		results = append(results, actions...)
+	}
+
+	return results, nil
+}
+
+// pathActions returns the open, reveal and copy actions for a path
+func (p *Provider) pathActions(path, query string) []search.SearchResult {
+	name := filepath.Base(path)
+
+	return []search.SearchResult{
+		{
+			Title:       "Open " + name,
+			Description: query,
+			Path:        "browse:open:" + path,
+			Icon:        icons.GetSystemIcon(path),
+			Type:        search.TypeFile,
+			Action: func() {
+				if err := openPath(path); err != nil {
+					slog.Error("Failed to open path", slog.String("path", path), slog.Any("error", err))
+				}
+			},
+		},
+		{
+			Title:       "Reveal " + name + " in " + opener.FileManagerName(),
+			Description: query,
+			Path:        "browse:reveal:" + path,
+			Icon:        theme.FolderOpenIcon(),
+			Type:        search.TypeFile,
+			Action: func() {
+				if err := revealPath(path); err != nil {
+					slog.Error("Failed to reveal path", slog.String("path", path), slog.Any("error", err))
+				}
+			},
+		},
+		{
+			Title:       "Copy Path",
+			Description: path,
+			Path:        "browse:copy:" + path,
+			Icon:        theme.ContentCopyIcon(),
+			Type:        search.TypeFile,
+			Action: func() {
+				if err := util.CopyToClipboard(path); err != nil {
+					slog.Error("Failed to copy path", slog.String("path", path), slog.Any("error", err))
+				}
+			},
+		},
+	}
+}
+
+// createEntryResult creates a result for a directory entry.
+// Completing a directory with Tab descends into it.
+func (p *Provider) createEntryResult(dir, dirQuery string, e entry) search.SearchResult {
+	path := filepath.Join(dir, e.name)
+
+	title := e.name
+	completion := dirQuery + e.name
+	if e.isDir {
+		title += "/"
+		completion += "/"
+	}
+
+	return search.SearchResult{
+		Title:       title,
+		Description: path,
+		Path:        path,
+		Icon:        icons.GetSystemIcon(path),
+		Type:        search.TypeFile,
+		Completion:  completion,
+		Preview: func() (*search.Preview, error) {
+			return search.NewFilePreview(path)
+		},
+		Action: func() {
+			if err := openPath(path); err != nil {
+				slog.Error("Failed to open path", slog.String("path", path), slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeFile {
+		return fmt.Errorf("not a file result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+
+	return nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func (p *Provider) expandHome(path string) string {
+	if p.homeDir == "" || (path != "~" && !strings.HasPrefix(path, "~/")) {
+		return path
+	}
+
+	return filepath.Join(p.homeDir, path[1:])
+}
+
+// splitQuery splits a path query into the directory to list and the filter
+// for its entries, e.g. ~/src/mar becomes ~/src/ and mar
+func splitQuery(query string) (dir, filter string) {
+	if query == "~" {
+		return "~/", ""
+	}
+
+	idx := strings.LastIndex(query, "/")
+	return query[:idx+1], query[idx+1:]
+}
+
+// entry is a directory entry that matched the filter
+type entry struct {
+	name  string
+	isDir bool
+	rank  int // Fuzzy match distance, lower is better
+}
+
+// listEntries returns the entries of dir that fuzzy match the filter, best match
+// first. Hidden entries are only included when the filter starts with a dot.
+func listEntries(dir, filter string) ([]entry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	showHidden := strings.HasPrefix(filter, ".")
+
+	entries := []entry{}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if strings.HasPrefix(name, ".") && !showHidden {
+			continue
+		}
+
+		rank := 0
+		if filter != "" {
+			rank = fuzzy.RankMatchFold(filter, name)
+			if rank < 0 {
+				continue
+			}
+		}
+
+		isDir := dirEntry.IsDir()
+		if dirEntry.Type()&os.ModeSymlink != 0 {
+			// Follow symlinks so linked directories can be descended into
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+
+		entries = append(entries, entry{name: name, isDir: isDir, rank: rank})
+	}
+
+	lowerFilter := strings.ToLower(filter)
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		// Prefix matches first, then closest fuzzy matches
+		aPrefix := strings.HasPrefix(strings.ToLower(a.name), lowerFilter)
+		bPrefix := strings.HasPrefix(strings.ToLower(b.name), lowerFilter)
+		if aPrefix != bPrefix {
+			return aPrefix
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+
+		// Directories before files, then alphabetically
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+
+	return entries, nil
+}
+
+// openPath opens a file or directory with its default application
+func openPath(path string) error {
+	return opener.Open(path)
+}
+
+// revealPath selects a file or directory in the file manager
+func revealPath(path string) error {
+	return opener.Open(path, opener.Reveal())
+}
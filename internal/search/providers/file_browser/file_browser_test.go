@@ -0,0 +1,278 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
+)
+
+// makeTree creates files and, for names ending in a slash, directories in dir
+func makeTree(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSplitQuery(t *testing.T) {
+	tests := []struct {
+		query, dir, filter string
+	}{
+		{"~", "~/", ""},
+		{"~/", "~/", ""},
+		{"~/src/mar", "~/src/", "mar"},
+		{"/", "/", ""},
+		{"/etc", "/", "etc"},
+		{"/etc/", "/etc/", ""},
+		{"/etc/hosts", "/etc/", "hosts"},
+	}
+
+	for _, tt := range tests {
+		dir, filter := splitQuery(tt.query)
+		if dir != tt.dir || filter != tt.filter {
+			t.Errorf("splitQuery(%q) = %q, %q, want %q, %q", tt.query, dir, filter, tt.dir, tt.filter)
+		}
+	}
+}
+
+func TestParentQuery(t *testing.T) {
+	tests := []struct {
+		query  string
+		parent string
+		ok     bool
+	}{
+		{"~/src/marvin/", "~/src/", true},
+		{"~/src/", "~/", true},
+		{"/etc/", "/", true},
+		{"/usr/local/bin/", "/usr/local/", true},
+		{"/", "", false},
+		{"~/", "", false},
+		{"~/src/mar", "", false},
+		{"~", "", false},
+	}
+
+	p := &Provider{}
+	for _, tt := range tests {
+		parent, ok := p.ParentQuery(tt.query)
+		if parent != tt.parent || ok != tt.ok {
+			t.Errorf("ParentQuery(%q) = %q, %v, want %q, %v", tt.query, parent, ok, tt.parent, tt.ok)
+		}
+	}
+}
+
+func TestListEntriesHidden(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, ".git/", ".env", "env.go", "main.go")
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"env.go", "main.go"}},
+		{"env", []string{"env.go"}},
+		// A leading dot shows hidden entries, which match before other names with a dot
+		{".", []string{".git", ".env", "env.go", "main.go"}},
+		{".e", []string{".env"}},
+	}
+
+	for _, tt := range tests {
+		entries, err := listEntries(dir, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := entryNames(entries); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("listEntries(%q) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestListEntriesOrder(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, "Makefile", "marvin/", "main.go", "my_app.go", "cmd/", "README.md")
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		// Directories before files, then alphabetically
+		{"", []string{"cmd", "marvin", "main.go", "Makefile", "my_app.go", "README.md"}},
+		// Prefix matches before fuzzy ones, closest first
+		{"ma", []string{"marvin", "main.go", "Makefile", "my_app.go"}},
+		{"mapp", []string{"my_app.go"}},
+	}
+
+	for _, tt := range tests {
+		entries, err := listEntries(dir, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := entryNames(entries); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("listEntries(%q) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestListEntriesFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, "target/")
+	if err := os.Symlink(filepath.Join(dir, "target"), filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	entries, err := listEntries(dir, "link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].isDir {
+		t.Errorf("listEntries(link) = %+v, want one directory", entries)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	home := t.TempDir()
+	makeTree(t, home, "src/", "src/marvin/", "src/main.go", "src/mage.go")
+	p := &Provider{maxResults: 50, homeDir: home}
+	src := filepath.Join(home, "src")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "directory actions first",
+			query: "~/src/",
+			want: []string{
+				"browse:open:" + src, "browse:reveal:" + src, "browse:copy:" + src,
+				filepath.Join(src, "marvin"), filepath.Join(src, "mage.go"), filepath.Join(src, "main.go"),
+			},
+		},
+		{
+			name:  "directory actions last while filtering",
+			query: "~/src/ma",
+			want: []string{
+				filepath.Join(src, "marvin"), filepath.Join(src, "mage.go"), filepath.Join(src, "main.go"),
+				"browse:open:" + src, "browse:reveal:" + src, "browse:copy:" + src,
+			},
+		},
+		{
+			name:  "exact entry actions first",
+			query: "~/src/main.go",
+			want: []string{
+				"browse:open:" + filepath.Join(src, "main.go"),
+				"browse:reveal:" + filepath.Join(src, "main.go"),
+				"browse:copy:" + filepath.Join(src, "main.go"),
+				filepath.Join(src, "main.go"),
+			},
+		},
+		{
+			name:  "missing directory",
+			query: "~/missing/",
+			want:  []string{"browse:error:" + filepath.Join(home, "missing")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := p.Search(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, len(results))
+			for i, r := range results {
+				got[i] = r.Path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) paths = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchCompletion(t *testing.T) {
+	home := t.TempDir()
+	makeTree(t, home, "src/", "notes.txt")
+	p := &Provider{maxResults: 50, homeDir: home}
+
+	results, err := p.Search("~/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	completions := map[string]string{}
+	for _, r := range results {
+		if r.Completion != "" {
+			completions[r.Title] = r.Completion
+		}
+	}
+	want := map[string]string{"src/": "~/src/", "notes.txt": "~/notes.txt"}
+	if !reflect.DeepEqual(completions, want) {
+		t.Errorf("completions = %q, want %q", completions, want)
+	}
+}
+
+func TestSearchMaxResults(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, "a", "b", "c", "d", "e")
+	p := &Provider{maxResults: 4}
+
+	results, err := p.Search(dir + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The three directory actions and one entry
+	if len(results) != 4 {
+		t.Errorf("Search returned %d results, want 4", len(results))
+	}
+}
+
+func TestSearchActionsOpen(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, "notes.txt")
+	p := &Provider{maxResults: 50}
+
+	recorder := &opener.Recorder{}
+	previous := opener.SetBackend(recorder)
+	defer opener.SetBackend(previous)
+
+	results, err := p.Search(dir + "/notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Action != nil && r.Path != "browse:copy:"+filepath.Join(dir, "notes.txt") {
+			r.Action()
+		}
+	}
+
+	path := filepath.Join(dir, "notes.txt")
+	want := []opener.Call{
+		{Target: path},
+		{Target: path, Options: opener.Options{Reveal: true}},
+		{Target: path},
+	}
+	if got := recorder.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("opened %+v, want %+v", got, want)
+	}
+}
+
+func entryNames(entries []entry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.name
+	}
+	return names
+}
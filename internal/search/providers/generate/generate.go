@@ -0,0 +1,177 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+var _ search.VolatileProvider = (*Provider)(nil)
+
+// Provider is a search provider for random values: UUIDs, ULIDs, nanoids,
+// tokens, passwords, passphrases and PINs, as in "uuid" or "password 32
+// nosymbols". Values come from crypto/rand and are made again on every
+// search, so each time the window is opened there are fresh ones to copy.
+type Provider struct {
+	priority int
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithClock replaces the clock time based values such as ULIDs are made with
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		p.now = now
+	}
+}
+
+// NewProvider creates a new generators provider
+func NewProvider(priority int, opts ...Option) *Provider {
+	p := &Provider{
+		priority: priority,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Generators"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// CanHandle returns whether the query starts with a generator's keyword or "generate"
+func (p *Provider) CanHandle(query string) bool {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	if strings.EqualFold(keyword, allKeyword) {
+		return true
+	}
+	_, ok := lookupGenerator(keyword)
+	return ok
+}
+
+// Volatile reports that every query the provider handles makes new values
+func (p *Provider) Volatile(query string) bool {
+	return true
+}
+
+// Search generates values for the keyword and its arguments
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	keyword, args := fields[0], fields[1:]
+	now := p.now()
+
+	if strings.EqualFold(keyword, allKeyword) {
+		return p.allResults(now), nil
+	}
+
+	g, ok := lookupGenerator(keyword)
+	if !ok {
+		return nil, nil
+	}
+
+	values, err := g.generate(args, now)
+	if err != nil {
+		return []search.SearchResult{errorResult("Can't generate "+strings.ToLower(keyword), fmt.Sprintf("%s. Usage: %s", err.Error(), g.usage))}, nil
+	}
+
+	results := make([]search.SearchResult, 0, len(values))
+	for _, v := range values {
+		results = append(results, valueResult(v))
+	}
+
+	slog.Debug("Generated values", slog.String("keyword", keyword), slog.Int("count", len(results)))
+	return results, nil
+}
+
+// allResults creates a result for the first value of each generator with
+// its default options
+func (p *Provider) allResults(now time.Time) []search.SearchResult {
+	var results []search.SearchResult
+	seen := map[string]bool{}
+	for _, g := range generators {
+		values, err := g.generate(nil, now)
+		if err != nil {
+			slog.Error("Generator failed", slog.String("generator", g.usage), slog.Any("error", err))
+			continue
+		}
+
+		for _, v := range values {
+			// "uuid" makes the same kinds of value as "uuid4" and "uuid7"
+			if seen[v.key] {
+				continue
+			}
+			seen[v.key] = true
+
+			result := valueResult(v)
+			result.Description = fmt.Sprintf("Type %q for options. Press Enter to copy", g.usage)
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// valueResult creates a result that copies a generated value when chosen
+func valueResult(v value) search.SearchResult {
+	return search.SearchResult{
+		Title:       fmt.Sprintf("%s: %s", v.label, v.text),
+		Description: v.detail + ". Press Enter to copy",
+		Type:        search.TypeSystem,
+		Path:        "generate:" + v.key,
+		Icon:        theme.ContentCopyIcon(),
+		Action: func() {
+			if err := util.CopyToClipboard(v.text); err != nil {
+				slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// errorResult creates a result explaining why nothing was generated
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "generate:error",
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeSystem {
+		return errors.New("not a generated value")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package generate
+
+import (
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testNow is the time values are made at in tests
+var testNow = time.Date(2026, time.March, 15, 10, 30, 0, 123e6, time.UTC)
+
+// generated searches the provider and returns the value of the result with
+// the given path
+func generated(t *testing.T, p *Provider, query, path string) string {
+	t.Helper()
+
+	results, err := p.Search(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Path == path {
+			_, text, _ := strings.Cut(r.Title, ": ")
+			return text
+		}
+	}
+	t.Fatalf("Search(%q) has no result %s", query, path)
+	return ""
+}
+
+// parseUUID checks a UUID's form and returns its bytes
+func parseUUID(t *testing.T, text string) []byte {
+	t.Helper()
+
+	parts := strings.Split(text, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		t.Fatalf("UUID %q isn't in the 8-4-4-4-12 form", text)
+	}
+	b, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		t.Fatalf("UUID %q isn't hex: %v", text, err)
+	}
+	return b
+}
+
+// millis reads the Unix time in milliseconds from the first 6 bytes of b
+func millis(b []byte) int64 {
+	var ms int64
+	for _, c := range b[:6] {
+		ms = ms<<8 | int64(c)
+	}
+	return ms
+}
+
+func TestUUIDv4(t *testing.T) {
+	p := NewProvider(0)
+
+	seen := map[string]bool{}
+	for range 100 {
+		text := generated(t, p, "uuid4", "generate:uuid4")
+		b := parseUUID(t, text)
+		if version := b[6] >> 4; version != 4 {
+			t.Errorf("UUID %s has version %d, want 4", text, version)
+		}
+		if variant := b[8] >> 6; variant != 0b10 {
+			t.Errorf("UUID %s has variant %02b, want 10", text, variant)
+		}
+		if seen[text] {
+			t.Errorf("UUID %s was made twice", text)
+		}
+		seen[text] = true
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	p := NewProvider(0, WithClock(func() time.Time { return testNow }))
+
+	for range 100 {
+		text := generated(t, p, "uuid7", "generate:uuid7")
+		b := parseUUID(t, text)
+		if version := b[6] >> 4; version != 7 {
+			t.Errorf("UUID %s has version %d, want 7", text, version)
+		}
+		if variant := b[8] >> 6; variant != 0b10 {
+			t.Errorf("UUID %s has variant %02b, want 10", text, variant)
+		}
+		if ms := millis(b); ms != testNow.UnixMilli() {
+			t.Errorf("UUID %s has time %d, want %d", text, ms, testNow.UnixMilli())
+		}
+	}
+}
+
+func TestULID(t *testing.T) {
+	p := NewProvider(0, WithClock(func() time.Time { return testNow }))
+
+	text := generated(t, p, "ulid", "generate:ulid")
+	if len(text) != 26 {
+		t.Fatalf("ULID %q has %d characters, want 26", text, len(text))
+	}
+	if strings.Trim(text, crockfordAlphabet) != "" {
+		t.Errorf("ULID %q isn't Crockford's Base32", text)
+	}
+
+	// The first 10 characters hold the 48 bit time
+	var ms int64
+	for _, c := range text[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockfordAlphabet, c))
+	}
+	if ms != testNow.UnixMilli() {
+		t.Errorf("ULID %s has time %d, want %d", text, ms, testNow.UnixMilli())
+	}
+}
+
+func TestNewULIDEncoding(t *testing.T) {
+	// A time of all ones fills the first 10 characters, the largest ULID time
+	// starting with 7
+	maxTime := time.UnixMilli(1<<48 - 1)
+	if got := newULID(maxTime)[:10]; got != "7ZZZZZZZZZ" {
+		t.Errorf("newULID(max time) starts %s, want 7ZZZZZZZZZ", got)
+	}
+	if got := newULID(time.UnixMilli(0))[:10]; got != "0000000000" {
+		t.Errorf("newULID(epoch) starts %s, want 0000000000", got)
+	}
+	if got := newULID(time.UnixMilli(32))[:10]; got != "0000000010" {
+		t.Errorf("newULID(32ms) starts %s, want 0000000010", got)
+	}
+}
+
+func TestTimeOrdering(t *testing.T) {
+	now := testNow
+	p := NewProvider(0, WithClock(func() time.Time { return now }))
+
+	var ulids, uuids []string
+	for i := range 50 {
+		now = testNow.Add(time.Duration(i) * 7 * time.Millisecond)
+		ulids = append(ulids, generated(t, p, "ulid", "generate:ulid"))
+		uuids = append(uuids, generated(t, p, "uuid7", "generate:uuid7"))
+	}
+
+	if !sort.StringsAreSorted(ulids) {
+		t.Errorf("ULIDs made later don't sort after earlier ones: %q", ulids)
+	}
+	if !sort.StringsAreSorted(uuids) {
+		t.Errorf("UUID v7s made later don't sort after earlier ones: %q", uuids)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"uuid extra", "Can't generate uuid"},
+		{"nanoid 1", "Can't generate nanoid"},
+		{"token 2048", "Can't generate token"},
+		{"password 3", "Can't generate password"},
+		{"password nouppercase", "Can't generate password"},
+		{"passphrase 2", "Can't generate passphrase"},
+		{"pin 4 5", "Can't generate pin"},
+	}
+
+	p := NewProvider(0)
+	for _, tt := range tests {
+		results, err := p.Search(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Title != tt.want || results[0].Path != "generate:error" {
+			t.Errorf("Search(%q) = %+v, want an error %q", tt.query, results, tt.want)
+		}
+	}
+}
+
+func TestVolatile(t *testing.T) {
+	p := NewProvider(0)
+	for _, query := range []string{"uuid", "generate", "password 32"} {
+		if !p.CanHandle(query) || !p.Volatile(query) {
+			t.Errorf("query %q isn't handled as volatile", query)
+		}
+	}
+	if p.CanHandle("uuids") {
+		t.Error("CanHandle(uuids) = true, want false")
+	}
+}
@@ -0,0 +1,323 @@
+package generate
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// value is a generated value with what it is
+type value struct {
+	// label names the kind of value, e.g. "UUID v4"
+	label string
+	text  string
+	// detail describes how it was made, e.g. "122 random bits"
+	detail string
+	// key tells a generator's values apart in result paths
+	key string
+}
+
+// generator makes values for a keyword and its arguments
+type generator struct {
+	keywords []string
+	// usage shows the keyword with its arguments, e.g. "token [bytes]"
+	usage    string
+	generate func(args []string, now time.Time) ([]value, error)
+}
+
+// generators are the generators in the order "generate" shows them. Add a
+// generator here to make it available.
+var generators = []generator{
+	{keywords: []string{"uuid"}, usage: "uuid", generate: generateUUIDs(true, true)},
+	{keywords: []string{"uuid4", "uuidv4"}, usage: "uuid4", generate: generateUUIDs(true, false)},
+	{keywords: []string{"uuid7", "uuidv7"}, usage: "uuid7", generate: generateUUIDs(false, true)},
+	{keywords: []string{"ulid"}, usage: "ulid", generate: generateULID},
+	{keywords: []string{"nanoid"}, usage: "nanoid [length]", generate: generateNanoid},
+	{keywords: []string{"token", "random"}, usage: "token [bytes]", generate: generateToken},
+	{keywords: []string{"password", "pw"}, usage: "password [length] [nosymbols] [nodigits] [noambiguous]", generate: generatePassword},
+	{keywords: []string{"passphrase"}, usage: "passphrase [words] [separator] [caps]", generate: generatePassphrase},
+	{keywords: []string{"pin"}, usage: "pin [length]", generate: generatePIN},
+}
+
+// allKeyword is the keyword that shows a value from each generator
+const allKeyword = "generate"
+
+// lookupGenerator finds the generator a keyword names
+func lookupGenerator(keyword string) (generator, bool) {
+	keyword = strings.ToLower(keyword)
+	for _, g := range generators {
+		for _, k := range g.keywords {
+			if k == keyword {
+				return g, true
+			}
+		}
+	}
+	return generator{}, false
+}
+
+// generateUUIDs returns a generator of version 4 and version 7 UUIDs
+func generateUUIDs(v4, v7 bool) func([]string, time.Time) ([]value, error) {
+	return func(args []string, now time.Time) ([]value, error) {
+		if err := noArgs(args); err != nil {
+			return nil, err
+		}
+
+		var values []value
+		if v4 {
+			values = append(values, value{label: "UUID v4", text: newUUIDv4(), detail: "122 random bits", key: "uuid4"})
+		}
+		if v7 {
+			values = append(values, value{label: "UUID v7", text: newUUIDv7(now), detail: "The time in milliseconds and 74 random bits, sorts by creation time", key: "uuid7"})
+		}
+		return values, nil
+	}
+}
+
+// generateULID makes a ULID
+func generateULID(args []string, now time.Time) ([]value, error) {
+	if err := noArgs(args); err != nil {
+		return nil, err
+	}
+	return []value{{label: "ULID", text: newULID(now), detail: "The time in milliseconds and 80 random bits, sorts by creation time", key: "ulid"}}, nil
+}
+
+// generateNanoid makes a nanoid, 21 characters long unless another length is given
+func generateNanoid(args []string, now time.Time) ([]value, error) {
+	length, err := countArg(args, 21, 2, 256, "length")
+	if err != nil {
+		return nil, err
+	}
+	return []value{{
+		label:  "Nanoid",
+		text:   randomString(nanoidAlphabet, length),
+		detail: fmt.Sprintf("%d URL safe characters, %d random bits", length, length*6),
+		key:    "nanoid",
+	}}, nil
+}
+
+// generateToken makes a random token, 32 bytes long unless another length is
+// given, written in hex and Base64
+func generateToken(args []string, now time.Time) ([]value, error) {
+	n, err := countArg(args, 32, 1, 1024, "number of bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	b := randomBytes(n)
+	detail := fmt.Sprintf("%d random bytes, %d bits", n, n*8)
+	return []value{
+		{label: "Hex token", text: hex.EncodeToString(b), detail: detail, key: "token:hex"},
+		{label: "Base64 token", text: base64.StdEncoding.EncodeToString(b), detail: detail, key: "token:base64"},
+		{label: "Base64url token", text: base64.RawURLEncoding.EncodeToString(b), detail: detail + ", URL safe without padding", key: "token:base64url"},
+	}, nil
+}
+
+// passwordPolicy is what a password is made of
+type passwordPolicy struct {
+	length  int
+	symbols bool
+	digits  bool
+	// ambiguous allows characters that look alike, such as O and 0
+	ambiguous bool
+}
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()-_=+[]{};:,.?/~"
+	// ambiguousChars are left out of passwords with noambiguous
+	ambiguousChars = "Il1O0o"
+)
+
+// classes returns the character sets the policy's passwords have at least
+// one character of
+func (p passwordPolicy) classes() []string {
+	classes := []string{lowerChars, upperChars}
+	if p.digits {
+		classes = append(classes, digitChars)
+	}
+	if p.symbols {
+		classes = append(classes, symbolChars)
+	}
+
+	if !p.ambiguous {
+		for i, class := range classes {
+			classes[i] = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, class)
+		}
+	}
+	return classes
+}
+
+// describe says what the policy's passwords are made of
+func (p passwordPolicy) describe() string {
+	var parts []string
+	if p.digits {
+		parts = append(parts, "digits")
+	}
+	if p.symbols {
+		parts = append(parts, "symbols")
+	}
+
+	text := fmt.Sprintf("%d characters of letters", p.length)
+	switch len(parts) {
+	case 1:
+		text += " and " + parts[0]
+	case 2:
+		text += ", " + parts[0] + " and " + parts[1]
+	}
+	if !p.ambiguous {
+		text += ", without look-alikes such as O and 0"
+	}
+	return text
+}
+
+// generatePassword makes a password, 20 characters of letters, digits and
+// symbols unless the arguments change the policy
+func generatePassword(args []string, now time.Time) ([]value, error) {
+	policy := passwordPolicy{length: 20, symbols: true, digits: true, ambiguous: true}
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n < 4 || n > 256 {
+				return nil, fmt.Errorf("length must be between 4 and 256")
+			}
+			policy.length = n
+			continue
+		}
+
+		switch strings.ToLower(arg) {
+		case "nosymbols", "alnum":
+			policy.symbols = false
+		case "nodigits":
+			policy.digits = false
+		case "noambiguous":
+			policy.ambiguous = false
+		default:
+			return nil, fmt.Errorf("unknown option %q, use a length, nosymbols, nodigits or noambiguous", arg)
+		}
+	}
+
+	classes := policy.classes()
+	charset := strings.Join(classes, "")
+
+	// Passwords missing a class are made again rather than patched, so every
+	// password meeting the policy is equally likely
+	var password string
+	for password == "" || !hasEach(password, classes) {
+		password = randomString(charset, policy.length)
+	}
+
+	return []value{{
+		label:  "Password",
+		text:   password,
+		detail: fmt.Sprintf("%s, about %d bits", policy.describe(), bits(len(charset), policy.length)),
+		key:    "password",
+	}}, nil
+}
+
+// hasEach reports whether text has a character from each class
+func hasEach(text string, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(text, class) {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePassphrase makes a passphrase of 5 words joined by dashes unless
+// the arguments give another number of words, a separator or caps
+func generatePassphrase(args []string, now time.Time) ([]value, error) {
+	count, separator, capitalize := 5, "-", false
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n < 3 || n > 20 {
+				return nil, fmt.Errorf("number of words must be between 3 and 20")
+			}
+			count = n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(arg)
+		switch lower := strings.ToLower(arg); {
+		case lower == "space":
+			separator = " "
+		case lower == "caps" || lower == "capitalize":
+			capitalize = true
+		case size == len(arg) && !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			separator = arg
+		default:
+			return nil, fmt.Errorf("unknown option %q, use a number of words, a separator such as . or space, or caps", arg)
+		}
+	}
+
+	picked := make([]string, count)
+	for i := range picked {
+		picked[i] = words[randomIndex(len(words))]
+		if capitalize {
+			picked[i] = strings.ToUpper(picked[i][:1]) + picked[i][1:]
+		}
+	}
+
+	return []value{{
+		label:  "Passphrase",
+		text:   strings.Join(picked, separator),
+		detail: fmt.Sprintf("%d words from a list of %d, about %d bits", count, len(words), bits(len(words), count)),
+		key:    "passphrase",
+	}}, nil
+}
+
+// generatePIN makes a PIN, 6 digits long unless another length is given
+func generatePIN(args []string, now time.Time) ([]value, error) {
+	length, err := countArg(args, 6, 4, 64, "length")
+	if err != nil {
+		return nil, err
+	}
+	return []value{{
+		label:  "PIN",
+		text:   randomString(digitChars, length),
+		detail: fmt.Sprintf("%d digits, about %d bits", length, bits(len(digitChars), length)),
+		key:    "pin",
+	}}, nil
+}
+
+// noArgs returns an error if there are arguments for a generator that takes none
+func noArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected %q, this generator takes no options", strings.Join(args, " "))
+	}
+	return nil
+}
+
+// countArg reads an optional number argument, returning def if there is none
+func countArg(args []string, def, low, high int, what string) (int, error) {
+	switch len(args) {
+	case 0:
+		return def, nil
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < low || n > high {
+			return 0, fmt.Errorf("%s must be a number between %d and %d", what, low, high)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unexpected %q, only the %s can be given", strings.Join(args[1:], " "), what)
+	}
+}
+
+// bits returns the entropy of picking n items uniformly from a set of size
+// choices, rounded down
+func bits(choices, n int) int {
+	return int(float64(n) * math.Log2(float64(choices)))
+}
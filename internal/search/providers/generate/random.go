@@ -0,0 +1,124 @@
+package generate
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// nanoidAlphabet is the URL safe alphabet nanoids are made from
+	nanoidAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// crockfordAlphabet is the Base32 alphabet ULIDs are written in, which
+	// leaves out I, L, O and U
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+//go:embed words.txt
+var wordsTXT string
+
+// words are the words passphrases are made from
+var words = parseWords(wordsTXT)
+
+// parseWords reads the word list, skipping comments
+func parseWords(data string) []string {
+	var list []string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, line)
+	}
+
+	return list
+}
+
+// randomBytes returns n bytes from crypto/rand
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never returns an error, it crashes the program instead
+	_, _ = rand.Read(b)
+	return b
+}
+
+// randomIndex returns a uniformly random number in [0, n)
+func randomIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		// rand.Reader doesn't fail, see crypto/rand.Read
+		panic(err)
+	}
+	return int(i.Int64())
+}
+
+// randomString returns length characters picked uniformly from alphabet
+func randomString(alphabet string, length int) string {
+	chars := []rune(alphabet)
+	out := make([]rune, length)
+	for i := range out {
+		out[i] = chars[randomIndex(len(chars))]
+	}
+	return string(out)
+}
+
+// newUUIDv4 returns a random UUID
+func newUUIDv4() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// newUUIDv7 returns a UUID starting with the Unix time in milliseconds, so
+// UUIDs made later sort after earlier ones
+func newUUIDv7(now time.Time) string {
+	b := randomBytes(16)
+	putMillis(b, now)
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// formatUUID writes 16 bytes in the 8-4-4-4-12 form
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// newULID returns a ULID: the Unix time in milliseconds followed by 80
+// random bits, written as 26 characters of Crockford's Base32
+func newULID(now time.Time) string {
+	b := randomBytes(16)
+	putMillis(b, now)
+
+	// The 128 bits are written 5 at a time, with the first character holding
+	// only the top 3 bits
+	out := make([]byte, 26)
+	for i := range out {
+		var v byte
+		for j := 0; j < 5; j++ {
+			bit := i*5 + j - 2
+			v <<= 1
+			if bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockfordAlphabet[v]
+	}
+	return string(out)
+}
+
+// putMillis writes the Unix time in milliseconds to the first 6 bytes of b
+func putMillis(b []byte, now time.Time) {
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(now.UnixMilli()))
+	copy(b[:6], ms[2:])
+}
@@ -0,0 +1,91 @@
+package hashing
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// algorithm is a hash function the provider computes digests with
+type algorithm struct {
+	// name is shown in results, e.g. "SHA-256"
+	name string
+	// keyword selects the algorithm, e.g. "sha256"
+	keyword string
+	new     func() hash.Hash
+	// keyed is set for algorithms that can be used in an HMAC
+	keyed bool
+}
+
+// algorithms are the hash functions the provider offers, in the order "hash"
+// shows them. Add an algorithm here to make it available.
+var algorithms = []algorithm{
+	{name: "MD5", keyword: "md5", new: md5.New, keyed: true},
+	{name: "SHA-1", keyword: "sha1", new: sha1.New, keyed: true},
+	{name: "SHA-224", keyword: "sha224", new: sha256.New224, keyed: true},
+	{name: "SHA-256", keyword: "sha256", new: sha256.New, keyed: true},
+	{name: "SHA-384", keyword: "sha384", new: sha512.New384, keyed: true},
+	{name: "SHA-512", keyword: "sha512", new: sha512.New, keyed: true},
+	{name: "SHA3-256", keyword: "sha3-256", new: func() hash.Hash { return sha3.New256() }, keyed: true},
+	{name: "SHA3-512", keyword: "sha3-512", new: func() hash.Hash { return sha3.New512() }, keyed: true},
+	{name: "BLAKE2b-256", keyword: "blake2b-256", new: newBlake2b(blake2b.New256)},
+	{name: "BLAKE2b-512", keyword: "blake2b", new: newBlake2b(blake2b.New512)},
+	{name: "CRC-32", keyword: "crc32", new: func() hash.Hash { return crc32.NewIEEE() }},
+}
+
+// allKeyword is the keyword that computes every algorithm's digest
+const allKeyword = "hash"
+
+// hmacPrefix starts the keywords of HMACs, e.g. "hmac-sha256"
+const hmacPrefix = "hmac-"
+
+// newBlake2b adapts a BLAKE2b constructor, which takes an optional key, to an
+// unkeyed hash
+func newBlake2b(newHash func(key []byte) (hash.Hash, error)) func() hash.Hash {
+	return func() hash.Hash {
+		h, err := newHash(nil)
+		if err != nil {
+			// Only a key that is too long is an error
+			panic(err)
+		}
+		return h
+	}
+}
+
+// lookupAlgorithm finds the algorithm a keyword names. For HMAC keywords
+// such as "hmac-sha256" it reports that the algorithm is keyed.
+func lookupAlgorithm(keyword string) (algorithm, bool, bool) {
+	keyword = strings.ToLower(keyword)
+	name, isHMAC := strings.CutPrefix(keyword, hmacPrefix)
+
+	for _, a := range algorithms {
+		if a.keyword == name && (a.keyed || !isHMAC) {
+			return a, isHMAC, true
+		}
+	}
+	return algorithm{}, false, false
+}
+
+// withKey returns the algorithm as an HMAC with the given key
+func (a algorithm) withKey(key []byte) algorithm {
+	return algorithm{
+		name:    "HMAC-" + a.name,
+		keyword: hmacPrefix + a.keyword,
+		new: func() hash.Hash {
+			return hmac.New(a.new, key)
+		},
+	}
+}
+
+// size returns the length of the algorithm's digests in bytes
+func (a algorithm) size() int {
+	return a.new().Size()
+}
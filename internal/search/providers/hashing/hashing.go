@@ -0,0 +1,422 @@
+package hashing
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"golang.design/x/clipboard"
+)
+
+var _ search.LaunchRecorder = (*Provider)(nil)
+
+const (
+	// maxFileSize is the largest file hashed, as hashing runs as the query is typed
+	maxFileSize = 256 << 20
+	// maxPreviewLength is the number of characters of text input shown in results
+	maxPreviewLength = 40
+)
+
+// Provider is a search provider for digests and checksums. It hashes the
+// text after the keyword, as in "sha256 hello", a file path, the clipboard,
+// or the file last opened from a file result such as Spotlight's, and
+// verifies digests against an expected one.
+type Provider struct {
+	priority int
+
+	// mu guards lastFile and cache
+	mu sync.Mutex
+	// lastFile is the file last opened from a file result
+	lastFile string
+	// cache holds file digests so a file isn't hashed again on every keystroke
+	cache map[fileKey][]byte
+	// generation counts searches, so a file being hashed for a search that a
+	// newer one has replaced stops being read
+	generation atomic.Uint64
+}
+
+// fileKey identifies a version of a file hashed with an algorithm
+type fileKey struct {
+	path      string
+	size      int64
+	modified  time.Time
+	algorithm string
+}
+
+// NewProvider creates a new hashing provider
+func NewProvider(priority int) (*Provider, error) {
+	err := clipboard.Init()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+
+	return &Provider{
+		priority: priority,
+		cache:    map[fileKey][]byte{},
+	}, nil
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Hashing"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// CanHandle returns whether the query starts with an algorithm, HMAC or "hash"
+func (p *Provider) CanHandle(query string) bool {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	if strings.EqualFold(keyword, allKeyword) {
+		return true
+	}
+	_, _, ok := lookupAlgorithm(keyword)
+	return ok
+}
+
+// RecordLaunch remembers the last file opened from a file result, so it can
+// be hashed by typing just the algorithm
+func (p *Provider) RecordLaunch(result search.SearchResult) {
+	if result.Type != search.TypeFile || !filepath.IsAbs(result.Path) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastFile = result.Path
+}
+
+// input is something to hash: text, or a file if path is set
+type input struct {
+	// label says what the input is, e.g. `query text "hello"` or "file report.pdf"
+	label string
+	text  string
+	path  string
+}
+
+// verifyPattern matches a trailing "verify <digest>", where the digest may be
+// prefixed with its algorithm as in sha256:<digest>
+var verifyPattern = regexp.MustCompile(`(?i)^(.*?)\s*\bverify\s+(?:[a-z0-9-]+:)?([0-9a-f]+)$`)
+
+// hexPattern matches a digest in hex
+var hexPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// errSuperseded stops hashing a file once a newer search has started
+var errSuperseded = errors.New("superseded by a newer search")
+
+// Search hashes the input with the algorithm the query names
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	generation := p.generation.Add(1)
+	keyword, text, _ := strings.Cut(strings.TrimSpace(query), " ")
+	text = strings.TrimSpace(text)
+
+	var selected []algorithm
+	if strings.EqualFold(keyword, allKeyword) {
+		selected = algorithms
+	} else {
+		a, isHMAC, ok := lookupAlgorithm(keyword)
+		if !ok {
+			return nil, nil
+		}
+		if isHMAC {
+			var key string
+			key, text, _ = strings.Cut(text, " ")
+			if key == "" {
+				return []search.SearchResult{errorResult(
+					"HMAC key needed",
+					fmt.Sprintf("Type the key after %q, then the text or file to hash", strings.ToLower(keyword)),
+				)}, nil
+			}
+			a = a.withKey([]byte(key))
+		}
+		selected = []algorithm{a}
+	}
+
+	expected := ""
+	if m := verifyPattern.FindStringSubmatch(text); m != nil {
+		text, expected = strings.TrimSpace(m[1]), strings.ToLower(m[2])
+	}
+
+	clipText := strings.TrimSpace(string(clipboard.Read(clipboard.FmtText)))
+	inputs := p.inputs(text, clipText)
+	if len(inputs) == 0 {
+		return []search.SearchResult{errorResult(
+			"Nothing to hash",
+			"Type text or a file path after the algorithm, copy some text, or open a file first",
+		)}, nil
+	}
+
+	var results []search.SearchResult
+	for i, in := range inputs {
+		digests, err := p.digests(in, selected, generation)
+		if errors.Is(err, errSuperseded) {
+			return nil, nil
+		}
+		if err != nil {
+			results = append(results, errorResult(fmt.Sprintf("Can't hash %s", in.label), err.Error()))
+			continue
+		}
+
+		fromClipboard := in.path == "" && in.text == clipText
+		for j, a := range selected {
+			// A digest of the right length in the clipboard is checked
+			// against anything else being hashed
+			want := expected
+			if want == "" && !fromClipboard && len(clipText) == a.size()*2 && hexPattern.MatchString(clipText) {
+				want = strings.ToLower(clipText)
+			}
+			results = append(results, digestResults(in, i, a, digests[j], want, len(selected) == 1)...)
+		}
+	}
+
+	slog.Debug("Hashing results", slog.String("query", query), slog.Int("count", len(results)))
+	return results, nil
+}
+
+// inputs returns what to hash: the text typed, or the file it names, or if
+// nothing was typed, the clipboard and the file last opened
+func (p *Provider) inputs(text, clipText string) []input {
+	if text != "" {
+		if path, ok := filePath(text); ok {
+			return []input{{label: "file " + filepath.Base(path), path: path}}
+		}
+		return []input{{label: fmt.Sprintf("query text %q", preview(text)), text: text}}
+	}
+
+	var inputs []input
+	if clipText != "" {
+		if path, ok := filePath(clipText); ok {
+			inputs = append(inputs, input{label: "file " + filepath.Base(path) + " from clipboard", path: path})
+		} else {
+			inputs = append(inputs, input{label: "clipboard", text: clipText})
+		}
+	}
+
+	p.mu.Lock()
+	lastFile := p.lastFile
+	p.mu.Unlock()
+	if _, ok := filePath(lastFile); ok {
+		inputs = append(inputs, input{label: "last opened file " + filepath.Base(lastFile), path: lastFile})
+	}
+
+	return inputs
+}
+
+// filePath returns the path text names if it is an existing regular file,
+// expanding a leading ~ to the home directory
+func filePath(text string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+
+	if rest, ok := strings.CutPrefix(text, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		text = filepath.Join(homeDir, rest)
+	}
+	if !filepath.IsAbs(text) {
+		return "", false
+	}
+
+	info, err := os.Stat(text)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return text, true
+}
+
+// digestResults creates results for an input's digest with an algorithm,
+// and for whether it matches the expected digest if there is one
+func digestResults(in input, index int, a algorithm, digest []byte, expected string, detailed bool) []search.SearchResult {
+	path := fmt.Sprintf("hashing:%s:%d", a.keyword, index)
+	hexDigest := hex.EncodeToString(digest)
+
+	results := []search.SearchResult{
+		copyResult(fmt.Sprintf("%s: %s", a.name, hexDigest), in.label, hexDigest, path+":hex"),
+	}
+	if detailed {
+		b64 := base64.StdEncoding.EncodeToString(digest)
+		results = append(results, copyResult(fmt.Sprintf("%s (base64): %s", a.name, b64), in.label, b64, path+":base64"))
+	}
+
+	switch {
+	case expected == "":
+	case len(expected) != len(hexDigest):
+		// When showing every algorithm, only those the digest could be from are checked
+		if detailed {
+			results = append(results, errorResult(
+				fmt.Sprintf("Expected digest isn't %s", a.name),
+				fmt.Sprintf("It has %d hex digits, %s digests have %d", len(expected), a.name, len(hexDigest)),
+			))
+		}
+	case expected == hexDigest:
+		results = append(results, search.SearchResult{
+			Title:       fmt.Sprintf("%s matches the expected digest", a.name),
+			Description: fmt.Sprintf("The %s of %s is %s", a.name, in.label, hexDigest),
+			Type:        search.TypeSystem,
+			Path:        path + ":verify",
+			Icon:        theme.ConfirmIcon(),
+		})
+	default:
+		results = append(results, errorResult(
+			fmt.Sprintf("%s does NOT match the expected digest", a.name),
+			fmt.Sprintf("Expected %s, got %s", expected, hexDigest),
+		))
+	}
+
+	return results
+}
+
+// digests hashes an input with each algorithm. A file is read once for all
+// of them, reusing the digests of a file that hasn't changed, and reading
+// stops with errSuperseded once a search newer than generation starts.
+func (p *Provider) digests(in input, selected []algorithm, generation uint64) ([][]byte, error) {
+	digests := make([][]byte, len(selected))
+	if in.path == "" {
+		for i, a := range selected {
+			h := a.new()
+			h.Write([]byte(in.text))
+			digests[i] = h.Sum(nil)
+		}
+		return digests, nil
+	}
+
+	info, err := os.Stat(in.path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("files over %d MB aren't hashed", maxFileSize>>20)
+	}
+
+	keys := make([]fileKey, len(selected))
+	var missing []int
+	p.mu.Lock()
+	for i, a := range selected {
+		keys[i] = fileKey{path: in.path, size: info.Size(), modified: info.ModTime(), algorithm: a.keyword}
+		if digest, ok := p.cache[keys[i]]; ok {
+			digests[i] = digest
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	p.mu.Unlock()
+	if len(missing) == 0 {
+		return digests, nil
+	}
+
+	hashes := make([]hash.Hash, len(missing))
+	writers := make([]io.Writer, len(missing))
+	for j, i := range missing {
+		hashes[j] = selected[i].new()
+		writers[j] = hashes[j]
+	}
+
+	f, err := os.Open(in.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &searchReader{reader: f, provider: p, generation: generation}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		if errors.Is(err, errSuperseded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(in.path), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for j, i := range missing {
+		digests[i] = hashes[j].Sum(nil)
+		// HMACs aren't cached, so their keys aren't kept around
+		if !strings.HasPrefix(selected[i].keyword, hmacPrefix) {
+			p.cache[keys[i]] = digests[i]
+		}
+	}
+	return digests, nil
+}
+
+// searchReader reads a file for a search, failing with errSuperseded once a
+// newer search has started
+type searchReader struct {
+	reader     io.Reader
+	provider   *Provider
+	generation uint64
+}
+
+// Read implements io.Reader
+func (r *searchReader) Read(b []byte) (int, error) {
+	if r.provider.generation.Load() != r.generation {
+		return 0, errSuperseded
+	}
+	return r.reader.Read(b)
+}
+
+// copyResult creates a result that copies text to the clipboard when chosen
+func copyResult(title, label, text, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: fmt.Sprintf("Of %s. Press Enter to copy", label),
+		Type:        search.TypeSystem,
+		Path:        path,
+		Action: func() {
+			clipboard.Write(clipboard.FmtText, []byte(text))
+		},
+	}
+}
+
+// errorResult creates a result explaining a problem
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "hashing:error:" + title,
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// preview shortens text to fit in a result's description
+func preview(text string) string {
+	if utf8.RuneCountInString(text) > maxPreviewLength {
+		return string([]rune(text)[:maxPreviewLength]) + "…"
+	}
+	return text
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeSystem {
+		return errors.New("not a hashing result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package hashing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAlgorithms(t *testing.T) {
+	tests := []struct {
+		keyword string
+		input   string
+		want    string
+	}{
+		{"md5", "", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"md5", "abc", "900150983cd24fb0d6963f7d28e17f72"},
+		{"sha1", "abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha224", "abc", "23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7"},
+		{"sha256", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha384", "abc", "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"},
+		{"sha512", "abc", "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{"sha3-256", "abc", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{"sha3-512", "abc", "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		{"blake2b-256", "abc", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{"blake2b", "abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{"crc32", "123456789", "cbf43926"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword+"/"+tt.input, func(t *testing.T) {
+			a, isHMAC, ok := lookupAlgorithm(tt.keyword)
+			if !ok || isHMAC {
+				t.Fatalf("lookupAlgorithm(%q) = %v, %v", tt.keyword, isHMAC, ok)
+			}
+			h := a.new()
+			h.Write([]byte(tt.input))
+			if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+				t.Errorf("%s(%q) = %s, want %s", a.name, tt.input, got, tt.want)
+			}
+			if a.size()*2 != len(tt.want) {
+				t.Errorf("%s size = %d, want %d", a.name, a.size(), len(tt.want)/2)
+			}
+		})
+	}
+}
+
+// TestHMAC checks the HMAC-SHA-2 test cases of RFC 4231. Test case 5, whose
+// output is truncated, is left out.
+func TestHMAC(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+		data []byte
+		want map[string]string
+	}{
+		{
+			name: "case 1",
+			key:  bytes.Repeat([]byte{0x0b}, 20),
+			data: []byte("Hi There"),
+			want: map[string]string{
+				"sha224": "896fb1128abbdf196832107cd49df33f47b4b1169912ba4f53684b22",
+				"sha256": "b0344c61d8db38535ca8afceaf0bf12b881dc200c9833da726e9376c2e32cff7",
+				"sha384": "afd03944d84895626b0825f4ab46907f15f9dadbe4101ec682aa034c7cebc59cfaea9ea9076ede7f4af152e8b2fa9cb6",
+				"sha512": "87aa7cdea5ef619d4ff0b4241a1d6cb02379f4e2ce4ec2787ad0b30545e17cdedaa833b7d6b8a702038b274eaea3f4e4be9d914eeb61f1702e696c203a126854",
+			},
+		},
+		{
+			name: "case 2",
+			key:  []byte("Jefe"),
+			data: []byte("what do ya want for nothing?"),
+			want: map[string]string{
+				"sha224": "a30e01098bc6dbbf45690f3a7e9e6d0f8bbea2a39e6148008fd05e44",
+				"sha256": "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+				"sha384": "af45d2e376484031617f78d2b58a6b1b9c7ef464f5a01b47e42ec3736322445e8e2240ca5e69e2c78b3239ecfab21649",
+				"sha512": "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+			},
+		},
+		{
+			name: "case 3",
+			key:  bytes.Repeat([]byte{0xaa}, 20),
+			data: bytes.Repeat([]byte{0xdd}, 50),
+			want: map[string]string{
+				"sha224": "7fb3cb3588c6c1f6ffa9694d7d6ad2649365b0c1f65d69d1ec8333ea",
+				"sha256": "773ea91e36800e46854db8ebd09181a72959098b3ef8c122d9635514ced565fe",
+				"sha384": "88062608d3e6ad8a0aa2ace014c8a86f0aa635d947ac9febe83ef4e55966144b2a5ab39dc13814b94e3ab6e101a34f27",
+				"sha512": "fa73b0089d56a284efb0f0756c890be9b1b5dbdd8ee81a3655f83e33b2279d39bf3e848279a722c806b485a47e67c807b946a337bee8942674278859e13292fb",
+			},
+		},
+		{
+			name: "case 4",
+			key:  []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25},
+			data: bytes.Repeat([]byte{0xcd}, 50),
+			want: map[string]string{
+				"sha224": "6c11506874013cac6a2abc1bb382627cec6a90d86efc012de7afec5a",
+				"sha256": "82558a389a443c0ea4cc819899f2083a85f0faa3e578f8077a2e3ff46729665b",
+				"sha384": "3e8a69b7783c25851933ab6290af6ca77a9981480850009cc5577c6e1f573b4e6801dd23c4a7d679ccf8a386c674cffb",
+				"sha512": "b0ba465637458c6990e5a8c5f61d4af7e576d97ff94b872de76f8050361ee3dba91ca5c11aa25eb4d679275cc5788063a5f19741120c4f2de2adebeb10a298dd",
+			},
+		},
+		{
+			name: "case 6",
+			key:  bytes.Repeat([]byte{0xaa}, 131),
+			data: []byte("Test Using Larger Than Block-Size Key - Hash Key First"),
+			want: map[string]string{
+				"sha224": "95e9a0db962095adaebe9b2d6f0dbce2d499f112f2d2b7273fa6870e",
+				"sha256": "60e431591ee0b67f0d8a26aacbf5b77f8e0bc6213728c5140546040f0ee37f54",
+				"sha384": "4ece084485813e9088d2c63a041bc5b44f9ef1012a2b588f3cd11f05033ac4c60c2ef6ab4030fe8296248df163f44952",
+				"sha512": "80b24263c7c1a3ebb71493c1dd7be8b49b46d1f41b4aeec1121b013783f8f3526b56d037e05f2598bd0fd2215d6a1e5295e64f73f63f0aec8b915a985d786598",
+			},
+		},
+		{
+			name: "case 7",
+			key:  bytes.Repeat([]byte{0xaa}, 131),
+			data: []byte("This is a test using a larger than block-size key and a larger than block-size data. The key needs to be hashed before being used by the HMAC algorithm."),
+			want: map[string]string{
+				"sha224": "3a854166ac5d9f023f54d517d0b39dbd946770db9c2b95c9f6f565d1",
+				"sha256": "9b09ffa71b942fcb27635fbcd5b0e944bfdc63644f0713938a7f51535c3a35e2",
+				"sha384": "6617178e941f020d351e2f254e8fd32c602420feb0b8fb9adccebb82461e99c5a678cc31e799176d3860e6110c46523e",
+				"sha512": "e37b6a775dc87dbaa4dfa9f96e5e3ffddebd71f8867289865df5a32d20cdc944b6022cac3c4982b10d5eeb55c3e4de15134676fb6de0446065c97440fa8c6a58",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for keyword, want := range tt.want {
+			t.Run(tt.name+"/"+keyword, func(t *testing.T) {
+				a, isHMAC, ok := lookupAlgorithm(hmacPrefix + keyword)
+				if !ok || !isHMAC {
+					t.Fatalf("lookupAlgorithm(%q) = %v, %v", hmacPrefix+keyword, isHMAC, ok)
+				}
+				h := a.withKey(tt.key).new()
+				h.Write(tt.data)
+				if got := hex.EncodeToString(h.Sum(nil)); got != want {
+					t.Errorf("HMAC-%s = %s, want %s", keyword, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestLookupAlgorithm(t *testing.T) {
+	tests := []struct {
+		keyword string
+		name    string
+		isHMAC  bool
+		ok      bool
+	}{
+		{"SHA256", "SHA-256", false, true},
+		{"blake2b", "BLAKE2b-512", false, true},
+		{"hmac-md5", "MD5", true, true},
+		{"hmac-crc32", "", false, false},
+		{"hmac-blake2b", "", false, false},
+		{"sha", "", false, false},
+		{"hash", "", false, false},
+	}
+
+	for _, tt := range tests {
+		a, isHMAC, ok := lookupAlgorithm(tt.keyword)
+		if a.name != tt.name || isHMAC != tt.isHMAC || ok != tt.ok {
+			t.Errorf("lookupAlgorithm(%q) = %q, %v, %v, want %q, %v, %v", tt.keyword, a.name, isHMAC, ok, tt.name, tt.isHMAC, tt.ok)
+		}
+	}
+}
+
+func TestVerifyPattern(t *testing.T) {
+	tests := []struct {
+		text     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"hello verify abc123", "hello", "abc123", true},
+		{"hello VERIFY ABC123", "hello", "ABC123", true},
+		{"~/app.dmg verify sha256:3a7b", "~/app.dmg", "3a7b", true},
+		{"~/app.dmg verify sha3-256:3a7b", "~/app.dmg", "3a7b", true},
+		{"verify 3a7b", "", "3a7b", true},
+		{"hello verify   3a7b", "hello", "3a7b", true},
+		{"a verify b verify 3a7b", "a verify b", "3a7b", true},
+		{"hello verify xyz", "", "", false},
+		{"hello verify", "", "", false},
+		{"hello verify 3a7b extra", "", "", false},
+		{"helloverify 3a7b", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			m := verifyPattern.FindStringSubmatch(tt.text)
+			if (m != nil) != tt.ok {
+				t.Fatalf("verifyPattern.FindStringSubmatch(%q) = %q, want a match %v", tt.text, m, tt.ok)
+			}
+			if m != nil && (m[1] != tt.input || m[2] != tt.expected) {
+				t.Errorf("verifyPattern.FindStringSubmatch(%q) = %q, %q, want %q, %q", tt.text, m[1], m[2], tt.input, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDigests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Provider{cache: map[fileKey][]byte{}}
+	md5, _, _ := lookupAlgorithm("md5")
+	sha256, _, _ := lookupAlgorithm("sha256")
+	hmacSHA256, _, _ := lookupAlgorithm("hmac-sha256")
+	selected := []algorithm{md5, sha256, hmacSHA256.withKey([]byte("key"))}
+
+	fromText, err := p.digests(input{text: "abc"}, selected, p.generation.Load())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := p.digests(input{path: path}, selected, p.generation.Load())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range selected {
+		if !bytes.Equal(fromText[i], fromFile[i]) {
+			t.Errorf("%s of the file = %x, want %x", a.name, fromFile[i], fromText[i])
+		}
+	}
+
+	// The file's digests are cached, but not its HMAC
+	if len(p.cache) != 2 {
+		t.Errorf("cache has %d digests, want 2", len(p.cache))
+	}
+	for key := range p.cache {
+		if strings.HasPrefix(key.algorithm, hmacPrefix) {
+			t.Errorf("cache holds an HMAC digest")
+		}
+	}
+}
+
+func TestDigestsSuperseded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Provider{cache: map[fileKey][]byte{}}
+	generation := p.generation.Add(1)
+	p.generation.Add(1)
+
+	if _, err := p.digests(input{path: path}, algorithms, generation); !errors.Is(err, errSuperseded) {
+		t.Errorf("digests() error = %v, want %v", err, errSuperseded)
+	}
+	if len(p.cache) != 0 {
+		t.Errorf("cache has %d digests after a superseded search, want 0", len(p.cache))
+	}
+
+	// Text isn't read, so it is always hashed
+	if _, err := p.digests(input{text: "abc"}, algorithms, generation); err != nil {
+		t.Errorf("digests() of text error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package recent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxHistoryEntries limits how many launches are remembered
+const maxHistoryEntries = 200
+
+// Launch is a file or application launched from Marvin
+type Launch struct {
+	Path         string    `json:"path"`
+	Title        string    `json:"title"`
+	LastLaunched time.Time `json:"lastLaunched"`
+	Count        int       `json:"count"`
+}
+
+// History is Marvin's own record of launched files and applications,
+// persisted as JSON so it survives restarts
+type History struct {
+	path     string
+	mu       sync.Mutex
+	launches map[string]*Launch
+}
+
+// NewHistory loads the launch history stored at path. If path is empty the
+// default ~/.config/marvin/history.json is used. A missing file starts an empty history.
+func NewHistory(path string) (*History, error) {
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		path = filepath.Join(homeDir, ".config", "marvin", "history.json")
+	}
+
+	h := &History{
+		path:     path,
+		launches: make(map[string]*Launch),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var launches []*Launch
+	if err := json.Unmarshal(data, &launches); err != nil {
+		return h, fmt.Errorf("failed to parse history file: %w", err)
+	}
+
+	for _, launch := range launches {
+		h.launches[launch.Path] = launch
+	}
+
+	return h, nil
+}
+
+// Record adds a launch of the path to the history and saves it
+func (h *History) Record(path, title string, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	launch, ok := h.launches[path]
+	if !ok {
+		launch = &Launch{Path: path}
+		h.launches[path] = launch
+	}
+
+	launch.Title = title
+	launch.LastLaunched = at
+	launch.Count++
+
+	// Forget the oldest launches once the history is full
+	launches := h.sortedLocked()
+	for _, old := range launches[min(len(launches), maxHistoryEntries):] {
+		delete(h.launches, old.Path)
+	}
+
+	return h.saveLocked()
+}
+
+// Launches returns a copy of the history, most recently launched first
+func (h *History) Launches() []Launch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	launches := []Launch{}
+	for _, launch := range h.sortedLocked() {
+		launches = append(launches, *launch)
+	}
+
+	return launches
+}
+
+// sortedLocked returns the launches, most recent first. h.mu must be held.
+func (h *History) sortedLocked() []*Launch {
+	launches := make([]*Launch, 0, len(h.launches))
+	for _, launch := range h.launches {
+		launches = append(launches, launch)
+	}
+
+	sort.Slice(launches, func(i, j int) bool {
+		return launches[i].LastLaunched.After(launches[j].LastLaunched)
+	})
+
+	return launches
+}
+
+// saveLocked writes the history to disk. h.mu must be held.
+func (h *History) saveLocked() error {
+	data, err := json.MarshalIndent(h.sortedLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated history
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,330 @@
+package recent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	desktopapps "github.com/MordFustang21/marvin-go/internal/search/providers/desktop_apps"
+	"github.com/MordFustang21/marvin-go/internal/search/providers/spotlight"
+	"github.com/MordFustang21/marvin-go/internal/ui/icons"
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+const (
+	// queryPrefix starts a query that searches only recent items, e.g. "recent report"
+	queryPrefix = "recent"
+	// spotlightWindow is how far back Spotlight is asked for recently used items
+	spotlightWindow = 30 * 24 * time.Hour
+	// spotlightCacheTTL is how long Spotlight's recent items are reused before asking again
+	spotlightCacheTTL = time.Minute
+	// spotlightTimeout limits how long a Spotlight lookup may take
+	spotlightTimeout = 3 * time.Second
+)
+
+var (
+	_ search.DefaultResultsProvider = (*Provider)(nil)
+	_ search.LaunchRecorder         = (*Provider)(nil)
+)
+
+// Provider is a search provider for recently opened files and applications.
+// It shows them when nothing has been typed and for queries starting with "recent".
+type Provider struct {
+	priority   int
+	maxResults int
+	history    *History
+	// spotlight supplies items opened outside of Marvin, may be nil
+	spotlight *spotlight.Provider
+
+	mu             sync.Mutex
+	spotlightItems []spotlight.RecentFile
+	cachedAt       time.Time
+}
+
+// item is a recently used file or application from either source
+type item struct {
+	path     string
+	title    string
+	lastUsed time.Time
+	// result is the full Spotlight result, if the item came from Spotlight
+	result *search.SearchResult
+}
+
+// NewProvider creates a new recent items provider. Items opened outside of
+// Marvin are included when a Spotlight provider is given.
+func NewProvider(priority, maxResults int, history *History, spotlightProvider *spotlight.Provider) *Provider {
+	if maxResults <= 0 {
+		maxResults = 10 // Default max results if invalid value provided
+	}
+
+	return &Provider{
+		priority:   priority,
+		maxResults: maxResults,
+		history:    history,
+		spotlight:  spotlightProvider,
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Recent"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeFile
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// CanHandle returns whether the query asks for recent items
+func (p *Provider) CanHandle(query string) bool {
+	query = strings.ToLower(query)
+	return query == queryPrefix || strings.HasPrefix(query, queryPrefix+" ")
+}
+
+// DefaultResults returns the most recently used items, shown when the window opens
+func (p *Provider) DefaultResults() ([]search.SearchResult, error) {
+	return p.createResults(p.recentItems(""), false), nil
+}
+
+// Search returns the recent items matching the text after "recent"
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	if !p.CanHandle(query) {
+		return nil, nil
+	}
+
+	filter := strings.TrimSpace(query[len(queryPrefix):])
+	return p.createResults(p.recentItems(filter), true), nil
+}
+
+// RecordLaunch adds launched files and applications to the history. Linux
+// applications are recorded by the path of their .desktop file.
+func (p *Provider) RecordLaunch(result search.SearchResult) {
+	if p.history == nil || !filepath.IsAbs(result.Path) {
+		return
+	}
+	if result.Type != search.TypeFile && result.Type != search.TypeApp {
+		return
+	}
+
+	go func() {
+		if err := p.history.Record(result.Path, result.Title, time.Now()); err != nil {
+			slog.Error("Failed to record launch", slog.String("path", result.Path), slog.Any("error", err))
+		}
+	}()
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeFile {
+		return fmt.Errorf("not a file result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+
+	return nil
+}
+
+// recentItems merges Marvin's launch history with Spotlight's recently used
+// items and returns those matching the filter, most recent first
+func (p *Provider) recentItems(filter string) []item {
+	byPath := map[string]item{}
+	add := func(it item) {
+		if existing, ok := byPath[it.path]; ok && !it.lastUsed.After(existing.lastUsed) {
+			return
+		}
+		byPath[it.path] = it
+	}
+
+	if p.history != nil {
+		for _, launch := range p.history.Launches() {
+			// Skip files that have been moved or deleted since they were launched
+			if _, err := os.Stat(launch.Path); err != nil {
+				continue
+			}
+			add(item{path: launch.Path, title: launch.Title, lastUsed: launch.LastLaunched})
+		}
+	}
+
+	for _, recent := range p.spotlightRecent() {
+		result := recent.Result
+		add(item{path: result.Path, title: result.Title, lastUsed: recent.LastUsed, result: &result})
+	}
+
+	items := []item{}
+	for _, it := range byPath {
+		if filter != "" && !fuzzy.MatchFold(filter, it.title) && !fuzzy.MatchFold(filter, filepath.Base(it.path)) {
+			continue
+		}
+		items = append(items, it)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].lastUsed.After(items[j].lastUsed)
+	})
+
+	return items
+}
+
+// spotlightRecent returns Spotlight's recently used items, refreshing them
+// when the cached copy is older than spotlightCacheTTL
+func (p *Provider) spotlightRecent() []spotlight.RecentFile {
+	if p.spotlight == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.cachedAt) < spotlightCacheTTL {
+		return p.spotlightItems
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spotlightTimeout)
+	defer cancel()
+
+	items, err := p.spotlight.RecentlyUsed(ctx, time.Now().Add(-spotlightWindow), p.maxResults*2)
+	if err != nil {
+		slog.Debug("Failed to get recent items from Spotlight", slog.Any("error", err))
+		return p.spotlightItems
+	}
+
+	p.spotlightItems = items
+	p.cachedAt = time.Now()
+
+	return items
+}
+
+// createResults converts items to search results, up to maxResults
+func (p *Provider) createResults(items []item, filtered bool) []search.SearchResult {
+	results := []search.SearchResult{}
+	for _, it := range items {
+		if len(results) >= p.maxResults {
+			break
+		}
+		results = append(results, p.createResult(it))
+	}
+
+	if len(results) == 0 && filtered {
+		return []search.SearchResult{
+			{
+				Title:       "No recent items",
+				Description: "Nothing matching has been opened recently",
+				Path:        "recent:none",
+				Type:        search.TypeFile,
+			},
+		}
+	}
+
+	return results
+}
+
+// createResult creates a search result for a recent item
+func (p *Provider) createResult(it item) search.SearchResult {
+	description := "Opened " + formatAge(time.Since(it.lastUsed))
+	if parent := filepath.Base(filepath.Dir(it.path)); parent != "/" && parent != "." {
+		description += " in " + parent
+	}
+
+	// Reuse Spotlight's result for its richer title, icon and preview
+	if it.result != nil {
+		result := *it.result
+		result.Description = description
+		return result
+	}
+
+	path := it.path
+	title := it.title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), ".app")
+	}
+
+	// Opening a .desktop file shows it as text, so relaunch the application instead
+	if strings.HasSuffix(path, ".desktop") {
+		return search.SearchResult{
+			Title:       title,
+			Description: description,
+			Path:        path,
+			Icon:        theme.ComputerIcon(),
+			Type:        search.TypeFile,
+			Action: func() {
+				if err := launchDesktopEntry(path); err != nil {
+					slog.Error("Failed to launch application", slog.String("path", path), slog.Any("error", err))
+				}
+			},
+		}
+	}
+
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Path:        path,
+		Icon:        iconForPath(path),
+		Type:        search.TypeFile,
+		Preview: func() (*search.Preview, error) {
+			return search.NewFilePreview(path)
+		},
+		Action: func() {
+			if err := opener.Open(path); err != nil {
+				slog.Error("Failed to open file", slog.String("path", path), slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// launchDesktopEntry launches the application described by the .desktop file at path
+func launchDesktopEntry(path string) error {
+	entry, err := desktopapps.ParseDesktopEntryFile(path, nil)
+	if err != nil {
+		return err
+	}
+	entry.ID = filepath.Base(path)
+	return desktopapps.Launch(entry)
+}
+
+// iconForPath returns the icon for an application or file
+func iconForPath(path string) fyne.Resource {
+	if strings.HasSuffix(path, ".app") {
+		return icons.GetAppIcon(path)
+	}
+	return icons.GetSystemIcon(path)
+}
+
+// formatAge describes how long ago something happened, e.g. "5 minutes ago"
+func formatAge(age time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return plural(int(age/time.Minute), "minute")
+	case age < 24*time.Hour:
+		return plural(int(age/time.Hour), "hour")
+	case age < 30*24*time.Hour:
+		return plural(int(age/(24*time.Hour)), "day")
+	default:
+		return plural(int(age/(30*24*time.Hour)), "month")
+	}
+}
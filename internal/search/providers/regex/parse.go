@@ -0,0 +1,158 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// expression is a parsed /pattern/flags or s/pattern/replacement/flags
+type expression struct {
+	pattern string
+	flags   string
+	// replace is set for s/pattern/replacement/ expressions
+	replace     bool
+	replacement string
+	// global replaces every match rather than only the first, as sed's g flag does
+	global bool
+	// text is what follows the expression, matched instead of the clipboard
+	text string
+	// typed is the expression as it was typed, without the text after it
+	typed string
+}
+
+// String returns the expression as it was typed
+func (e expression) String() string {
+	return e.typed
+}
+
+// flagNames maps the flags that can follow an expression to Go's inline flags
+var flagNames = map[rune]string{
+	'i': "i", // case insensitive
+	'm': "m", // ^ and $ match at line breaks
+	's': "s", // . matches line breaks
+	'U': "U", // ungreedy
+}
+
+// parseExpression parses /pattern/flags, s/pattern/replacement/flags or, for
+// convenience, a bare pattern. s can be followed by any punctuation as the
+// delimiter, as in s#a/b#c#.
+func parseExpression(query string) (expression, error) {
+	var e expression
+
+	switch {
+	case strings.HasPrefix(query, "/"):
+		parts, rest, err := splitDelimited(query[1:], '/', 2)
+		if err != nil {
+			return e, err
+		}
+		e.pattern = parts[0]
+		e.flags, e.text = cutFlags(rest)
+		e.typed = query[:len(query)-len(rest)] + e.flags
+	case len(query) > 1 && query[0] == 's' && isDelimiter(rune(query[1])):
+		parts, rest, err := splitDelimited(query[2:], rune(query[1]), 3)
+		if err != nil {
+			return e, err
+		}
+		e.replace = true
+		e.pattern, e.replacement = parts[0], sedReplacement(parts[1])
+		e.flags, e.text = cutFlags(rest)
+		e.typed = query[:len(query)-len(rest)] + e.flags
+	default:
+		e.pattern, e.text, _ = strings.Cut(query, " ")
+		e.typed = "/" + e.pattern + "/"
+	}
+
+	for _, flag := range e.flags {
+		switch {
+		case flag == 'g':
+			e.global = true
+		case flagNames[flag] == "":
+			return e, fmt.Errorf("unknown flag %q, use i, m, s, U or g", flag)
+		}
+	}
+	if e.pattern == "" {
+		return e, fmt.Errorf("the pattern is empty")
+	}
+	return e, nil
+}
+
+// isDelimiter reports whether r can delimit an s expression
+func isDelimiter(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// splitDelimited splits text at unescaped delimiters until it has n parts,
+// the last being what follows the final delimiter. Escaped delimiters are
+// unescaped, and other escapes are kept for the regexp.
+func splitDelimited(text string, delimiter rune, n int) ([]string, string, error) {
+	var parts []string
+	var b strings.Builder
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			if runes[i+1] != delimiter {
+				b.WriteRune(r)
+			}
+			b.WriteRune(runes[i+1])
+			i++
+		case r == delimiter:
+			parts = append(parts, b.String())
+			b.Reset()
+			if len(parts) == n-1 {
+				return parts, string(runes[i+1:]), nil
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return nil, "", fmt.Errorf("missing closing %c", delimiter)
+}
+
+// cutFlags splits the flags directly after an expression from the text
+// after them
+func cutFlags(rest string) (string, string) {
+	flags, text, _ := strings.Cut(rest, " ")
+	return flags, strings.TrimSpace(text)
+}
+
+// sedReplacement converts a sed style replacement to Go's template syntax.
+// Group references such as \1 become ${1}, \\ is a backslash and $ is
+// literal, as it is in sed, so $5 isn't read as a missing group.
+func sedReplacement(replacement string) string {
+	var b strings.Builder
+	runes := []rune(replacement)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '$':
+			b.WriteString("$$")
+		case r == '\\' && i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9':
+			fmt.Fprintf(&b, "${%c}", runes[i+1])
+			i++
+		case r == '\\' && i+1 < len(runes) && runes[i+1] == '\\':
+			b.WriteRune(r)
+			i++
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// compile compiles the expression's pattern with its flags
+func (e expression) compile() (*regexp.Regexp, error) {
+	var inline strings.Builder
+	for _, flag := range e.flags {
+		inline.WriteString(flagNames[flag])
+	}
+
+	pattern := e.pattern
+	if inline.Len() > 0 {
+		pattern = "(?" + inline.String() + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
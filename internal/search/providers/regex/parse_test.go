@@ -0,0 +1,135 @@
+package regex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		query string
+		want  expression
+	}{
+		{"/a+b/", expression{pattern: "a+b", typed: "/a+b/"}},
+		{"/a+b/i some text", expression{pattern: "a+b", flags: "i", text: "some text", typed: "/a+b/i"}},
+		{`/a\/b/`, expression{pattern: "a/b", typed: `/a\/b/`}},
+		{`/\d+/ 12 34`, expression{pattern: `\d+`, text: "12 34", typed: `/\d+/`}},
+		{"s/a/b/", expression{pattern: "a", replace: true, replacement: "b", typed: "s/a/b/"}},
+		{"s/a/b/gi text", expression{pattern: "a", replace: true, replacement: "b", flags: "gi", global: true, text: "text", typed: "s/a/b/gi"}},
+		{`s/(\w+)@/\1 at /`, expression{pattern: `(\w+)@`, replace: true, replacement: "${1} at ", typed: `s/(\w+)@/\1 at /`}},
+		{"s#a/b#c#", expression{pattern: "a/b", replace: true, replacement: "c", typed: "s#a/b#c#"}},
+		{"s|x||", expression{pattern: "x", replace: true, typed: "s|x||"}},
+		{`\w+ hello`, expression{pattern: `\w+`, text: "hello", typed: `/\w+/`}},
+		{"sad", expression{pattern: "sad", typed: "/sad/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parseExpression(tt.query)
+			if err != nil {
+				t.Fatalf("parseExpression(%q) error = %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseExpression(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"/abc", "missing closing /"},
+		{"s/a/b", "missing closing /"},
+		{"s#a#b", "missing closing #"},
+		{"/a/x", `unknown flag 'x', use i, m, s, U or g`},
+		{"//", "the pattern is empty"},
+		{"s///", "the pattern is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseExpression(tt.query)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parseExpression(%q) error = %v, want %q", tt.query, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitDelimited(t *testing.T) {
+	tests := []struct {
+		text      string
+		delimiter rune
+		n         int
+		parts     []string
+		rest      string
+	}{
+		{"a/b/c", '/', 3, []string{"a", "b"}, "c"},
+		{"a/b/", '/', 3, []string{"a", "b"}, ""},
+		{`a\/b/c/`, '/', 3, []string{"a/b", "c"}, ""},
+		{`a\d/\1/g`, '/', 3, []string{`a\d`, `\1`}, "g"},
+		{"é#ü#", '#', 3, []string{"é", "ü"}, ""},
+		{"abc/ rest", '/', 2, []string{"abc"}, " rest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			parts, rest, err := splitDelimited(tt.text, tt.delimiter, tt.n)
+			if err != nil {
+				t.Fatalf("splitDelimited(%q) error = %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(parts, tt.parts) || rest != tt.rest {
+				t.Errorf("splitDelimited(%q) = %q, %q, want %q, %q", tt.text, parts, rest, tt.parts, tt.rest)
+			}
+		})
+	}
+
+	if _, _, err := splitDelimited(`a\/b`, '/', 2); err == nil {
+		t.Error("splitDelimited without a closing delimiter error = nil, want an error")
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  string
+	}{
+		{"s/a/b/", "banana", "bbnana"},
+		{"s/a/b/g", "banana", "bbnbnb"},
+		{"s/A/b/gi", "bAnana", "bbnbnb"},
+		{"s/a/$5/", "abc", "$5bc"},
+		{"s/a/$1/g", "aa", "$1$1"},
+		{"s/(a)/${1}/", "abc", "${1}bc"},
+		{"s/(b)(c)/\\2\\1/", "abc", "acb"},
+		{"s/(\\w+)@(\\w+)/\\2 at \\1/g", "me@home you@work", "home at me work at you"},
+		{"s/b/\\\\1/", "abc", "a\\1c"},
+		{"s/x/y/", "abc", "abc"},
+		{"s/$/!/", "abc", "abc!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			e, err := parseExpression(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			re, err := e.compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := replaceResult(e, re, tt.text, "query", len(re.FindAllStringIndex(tt.text, -1)))
+			p, err := result.Preview()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Text != tt.want {
+				t.Errorf("%s on %q = %q, want %q", tt.query, tt.text, p.Text, tt.want)
+			}
+		})
+	}
+}
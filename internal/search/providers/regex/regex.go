@@ -0,0 +1,322 @@
+package regex
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+var _ search.Provider = (*Provider)(nil)
+
+const (
+	// maxMatches is the number of matches shown as results of their own
+	maxMatches = 10
+	// maxPreviewLength is the number of characters of a match shown in results
+	maxPreviewLength = 60
+)
+
+// keywords start regular expression queries
+var keywords = []string{"re", "regex", "regexp"}
+
+// Provider is a search provider for testing regular expressions. It runs
+// /pattern/flags against the clipboard, or the text after the expression,
+// showing the matches and their capture groups, and previews replacements
+// given as s/pattern/replacement/flags. Patterns use Go's RE2 syntax.
+type Provider struct {
+	priority int
+}
+
+// NewProvider creates a new regex provider
+func NewProvider(priority int) *Provider {
+	return &Provider{
+		priority: priority,
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Regex"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// splitQuery splits the keyword from the expression after it
+func splitQuery(query string) (string, bool) {
+	keyword, rest, _ := strings.Cut(strings.TrimLeft(query, " \t"), " ")
+	for _, k := range keywords {
+		if strings.EqualFold(keyword, k) {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// CanHandle returns whether the query starts with re, regex or regexp
+func (p *Provider) CanHandle(query string) bool {
+	_, ok := splitQuery(query)
+	return ok
+}
+
+// Search runs the expression against the clipboard or the text after it
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	rest, ok := splitQuery(query)
+	if !ok {
+		return nil, nil
+	}
+	if rest == "" {
+		return []search.SearchResult{errorResult(
+			"Type a regular expression",
+			"e.g. re /\\d+/ to match the clipboard, or re s/(\\w+)@/\\1 at / to replace in it",
+		)}, nil
+	}
+
+	e, err := parseExpression(rest)
+	if err != nil {
+		return []search.SearchResult{errorResult("Invalid expression", err.Error())}, nil
+	}
+	re, err := e.compile()
+	if err != nil {
+		// The regexp package's errors start with where they come from
+		return []search.SearchResult{errorResult("Invalid regular expression", strings.TrimPrefix(err.Error(), "error parsing regexp: "))}, nil
+	}
+
+	source, text := "query text", e.text
+	if text == "" {
+		clipText, err := util.GetFromClipboard()
+		if err != nil {
+			return []search.SearchResult{errorResult("Can't read the clipboard", err.Error())}, nil
+		}
+		source, text = "clipboard", strings.ReplaceAll(clipText, "\r\n", "\n")
+	}
+
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	slog.Debug("Regex search",
+		slog.String("expression", e.String()),
+		slog.String("source", source),
+		slog.Int("matches", len(matches)))
+
+	var results []search.SearchResult
+	if e.replace {
+		results = append(results, replaceResult(e, re, text, source, len(matches)))
+	}
+	results = append(results, matchResults(e, re, text, source, matches)...)
+	return results, nil
+}
+
+// matchResults creates a summary result that copies every match, a result
+// for each of the first matches, and one for each capture group's values
+func matchResults(e expression, re *regexp.Regexp, text, source string, matches [][]int) []search.SearchResult {
+	if len(matches) == 0 {
+		return []search.SearchResult{{
+			Title:       "No matches for " + e.String(),
+			Description: fmt.Sprintf("In %s, %s", source, plural(utf8.RuneCountInString(text), "%d character")),
+			Type:        search.TypeSystem,
+			Path:        "regex:summary",
+			Icon:        theme.InfoIcon(),
+		}}
+	}
+
+	all := make([]string, len(matches))
+	for i, m := range matches {
+		all[i] = text[m[0]:m[1]]
+	}
+	results := []search.SearchResult{copyResult(
+		fmt.Sprintf("%s of %s", plural(len(matches), "%d match"), e.String()),
+		fmt.Sprintf("In %s. Press Enter to copy every match, one per line", source),
+		strings.Join(all, "\n"),
+		"regex:summary",
+	)}
+
+	names := re.SubexpNames()
+	for i, m := range matches[:min(len(matches), maxMatches)] {
+		line, column := position(text, m[0])
+
+		var groups []string
+		for g := 1; g < len(names); g++ {
+			groups = append(groups, fmt.Sprintf("%s = %s", groupName(names, g), groupValue(text, m, g)))
+		}
+		description := fmt.Sprintf("Line %d, column %d", line, column)
+		if len(groups) > 0 {
+			description += ": " + strings.Join(groups, ", ")
+		}
+
+		result := copyResult(
+			fmt.Sprintf("Match %d: %s", i+1, preview(all[i])),
+			description+". Press Enter to copy",
+			all[i],
+			fmt.Sprintf("regex:match:%d", i),
+		)
+		result.Preview = matchPreview(names, text, m, i, line, column)
+		results = append(results, result)
+	}
+
+	// Each group's values across every match can be copied together, like a
+	// column picked out of the text
+	for g := 1; g < len(names); g++ {
+		values := make([]string, 0, len(matches))
+		for _, m := range matches {
+			if m[2*g] >= 0 {
+				values = append(values, text[m[2*g]:m[2*g+1]])
+			}
+		}
+		results = append(results, copyResult(
+			fmt.Sprintf("Group %s: %s", groupName(names, g), preview(strings.Join(values, ", "))),
+			fmt.Sprintf("%s from every match. Press Enter to copy them, one per line", plural(len(values), "%d value")),
+			strings.Join(values, "\n"),
+			fmt.Sprintf("regex:group:%d", g),
+		))
+	}
+
+	return results
+}
+
+// matchPreview builds a preview listing a match and each of its groups
+func matchPreview(names []string, text string, m []int, index, line, column int) func() (*search.Preview, error) {
+	return func() (*search.Preview, error) {
+		p := &search.Preview{
+			Title: fmt.Sprintf("Match %d", index+1),
+			Text:  text[m[0]:m[1]],
+		}
+		p.AddMetadata("Position", fmt.Sprintf("Line %d, column %d", line, column))
+		for g := 1; g < len(names); g++ {
+			p.AddMetadata("Group "+groupName(names, g), groupValue(text, m, g))
+		}
+		return p, nil
+	}
+}
+
+// replaceResult creates a result previewing the text with matches replaced
+func replaceResult(e expression, re *regexp.Regexp, text, source string, count int) search.SearchResult {
+	replaced := text
+	switch {
+	case count == 0:
+	case e.global:
+		replaced = re.ReplaceAllString(text, e.replacement)
+	default:
+		// Only the first match is replaced, as sed does without g
+		m := re.FindStringSubmatchIndex(text)
+		replaced = text[:m[0]] + string(re.ExpandString(nil, e.replacement, text, m)) + text[m[1]:]
+		count = 1
+	}
+
+	result := copyResult(
+		"Replaced: "+preview(replaced),
+		fmt.Sprintf("%s replaced in %s. Press Enter to copy the result", plural(count, "%d match"), source),
+		replaced,
+		"regex:replace",
+	)
+	result.Preview = func() (*search.Preview, error) {
+		p := &search.Preview{Title: "Replaced with " + e.String(), Text: replaced}
+		p.AddMetadata("Replacements", strconv.Itoa(count))
+		p.AddMetadata("From", source)
+		return p, nil
+	}
+	return result
+}
+
+// groupName returns a capture group's name, or its number if it has none
+func groupName(names []string, g int) string {
+	if names[g] != "" {
+		return names[g]
+	}
+	return strconv.Itoa(g)
+}
+
+// groupValue returns a capture group's text in a match, quoted, or "unmatched"
+// if the group took no part in it
+func groupValue(text string, m []int, g int) string {
+	if m[2*g] < 0 {
+		return "unmatched"
+	}
+	return strconv.Quote(shorten(text[m[2*g]:m[2*g+1]]))
+}
+
+// position returns the line and column of a byte offset, both counted from 1
+func position(text string, offset int) (int, int) {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
+
+// plural formats a count into a phrase, adding "es" or "s" unless it is one
+func plural(n int, phrase string) string {
+	text := fmt.Sprintf(phrase, n)
+	switch {
+	case n == 1:
+	case strings.HasSuffix(text, "ch"):
+		text += "es"
+	default:
+		text += "s"
+	}
+	return text
+}
+
+// copyResult creates a result that copies text to the clipboard when chosen
+func copyResult(title, description, text, path string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        path,
+		Icon:        theme.SearchIcon(),
+		Action: func() {
+			if err := util.CopyToClipboard(text); err != nil {
+				slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+			}
+		},
+	}
+}
+
+// errorResult creates a result explaining why the expression can't be run
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "regex:error",
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// preview shortens text to fit on one line of a result, showing line breaks
+// and tabs as escapes
+func preview(text string) string {
+	return shorten(strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(text))
+}
+
+// shorten cuts text to maxPreviewLength characters
+func shorten(text string) string {
+	if utf8.RuneCountInString(text) > maxPreviewLength {
+		return string([]rune(text)[:maxPreviewLength]) + "…"
+	}
+	return text
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeSystem {
+		return errors.New("not a regex result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
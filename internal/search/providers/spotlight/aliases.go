@@ -0,0 +1,74 @@
+package spotlight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// aliasTable maps a lowercase application name or bundle identifier to the
+// lowercase aliases the user has defined for it
+type aliasTable map[string][]string
+
+// SetAliases replaces the user-defined application aliases.
+// Keys may be an application name (e.g. "Visual Studio Code") or a bundle
+// identifier (e.g. "com.microsoft.VSCode") and are matched case-insensitively.
+func (p *Provider) SetAliases(aliases map[string][]string) {
+	table := make(aliasTable, len(aliases))
+	for key, values := range aliases {
+		key = strings.ToLower(strings.TrimSpace(key))
+		for _, alias := range values {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+			if alias != "" {
+				table[key] = append(table[key], alias)
+			}
+		}
+	}
+
+	p.aliases.Store(&table)
+}
+
+// LoadAliases reads user-defined application aliases from a JSON file of the form
+// {"com.microsoft.VSCode": ["code", "vsc"]}. If path is empty the default
+// ~/.config/marvin/aliases.json is used. A missing file is not an error.
+func (p *Provider) LoadAliases(path string) error {
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		path = filepath.Join(homeDir, ".config", "marvin", "aliases.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read aliases file: %w", err)
+	}
+
+	var aliases map[string][]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("failed to parse aliases file: %w", err)
+	}
+
+	p.SetAliases(aliases)
+	slog.Debug("Loaded application aliases", slog.String("path", path), slog.Int("numApps", len(aliases)))
+
+	return nil
+}
+
+// loadAliasTable returns the current alias table, which may be nil
+func (p *Provider) loadAliasTable() aliasTable {
+	if table := p.aliases.Load(); table != nil {
+		return *table
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package spotlight
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MordFustang21/marvin-go/internal/search"
+)
+
+// Match scores used to rank cached applications. Higher is better.
+const (
+	scoreExact          = 1000
+	scoreAliasExact     = 950
+	scorePrefix         = 900
+	scoreInitials       = 850
+	scoreWordPrefix     = 800
+	scoreAliasPrefix    = 750
+	scoreInitialsPrefix = 700
+	scoreBundleWord     = 650
+	scoreSubstring      = 600
+	scoreBundlePrefix   = 550
+	scoreSubsequence    = 100 // Base score for a fuzzy subsequence match
+	maxSubsequenceBonus = 399
+)
+
+// appName is a name an application can be found by, pre-split for matching
+type appName struct {
+	lower    string   // Lowercase full name
+	words    []string // Lowercase words, split on spaces, punctuation and camelCase
+	initials string   // First letter of each word
+	starts   []bool   // Whether each byte of lower begins a word
+}
+
+// newAppName prepares a display name for matching
+func newAppName(name string) appName {
+	words := splitWords(name)
+
+	var initials strings.Builder
+	for _, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		initials.WriteRune(r)
+	}
+
+	lower := strings.ToLower(name)
+
+	return appName{
+		lower:    lower,
+		words:    words,
+		initials: initials.String(),
+		starts:   wordStarts(name),
+	}
+}
+
+// appEntry is a cached application together with everything it can be matched by
+type appEntry struct {
+	result   search.SearchResult
+	names    []appName // Display name, bundle file name and localized names
+	bundleID string    // Lowercase bundle identifier, e.g. com.apple.safari
+	keys     []string  // Lowercase keys used to look up user-defined aliases
+}
+
+// newAppEntry creates an entry for the result with the given alternative names.
+// Duplicate and empty names are ignored.
+func newAppEntry(result search.SearchResult, bundleID string, names ...string) appEntry {
+	entry := appEntry{
+		result:   result,
+		bundleID: strings.ToLower(bundleID),
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range append([]string{result.Title}, names...) {
+		lower := strings.ToLower(strings.TrimSpace(name))
+		if lower == "" || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+
+		entry.names = append(entry.names, newAppName(strings.TrimSpace(name)))
+		entry.keys = append(entry.keys, lower)
+	}
+
+	if entry.bundleID != "" {
+		entry.keys = append(entry.keys, entry.bundleID)
+	}
+
+	return entry
+}
+
+// scoredApp is an application that matched a query
+type scoredApp struct {
+	entry *appEntry
+	score int
+}
+
+// matchApps returns the applications matching the query, best match first.
+// Ties are broken by title and then path so the ordering is deterministic.
+func matchApps(apps []appEntry, query string, aliases aliasTable, limit int) []search.SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	matches := []scoredApp{}
+	for i := range apps {
+		if score := scoreApp(&apps[i], query, aliases); score > 0 {
+			matches = append(matches, scoredApp{entry: &apps[i], score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+
+		titleA, titleB := strings.ToLower(a.entry.result.Title), strings.ToLower(b.entry.result.Title)
+		if titleA != titleB {
+			return titleA < titleB
+		}
+
+		return a.entry.result.Path < b.entry.result.Path
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]search.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, match.entry.result)
+	}
+
+	return results
+}
+
+// scoreApp returns the best score of the query against any of the application's names,
+// aliases or bundle identifier. A score of zero means no match.
+func scoreApp(app *appEntry, query string, aliases aliasTable) int {
+	best := 0
+
+	for _, name := range app.names {
+		best = max(best, scoreName(query, name))
+	}
+
+	for _, key := range app.keys {
+		for _, alias := range aliases[key] {
+			switch {
+			case alias == query:
+				best = max(best, scoreAliasExact)
+			case strings.HasPrefix(alias, query):
+				best = max(best, scoreAliasPrefix)
+			}
+		}
+	}
+
+	return max(best, scoreBundleID(query, app.bundleID))
+}
+
+// scoreName scores the query against a single name
+func scoreName(query string, name appName) int {
+	switch {
+	case name.lower == query:
+		return scoreExact
+	case strings.HasPrefix(name.lower, query):
+		// Prefer shorter names so "mail" ranks Mail above Mailplane
+		return scorePrefix - min(len(name.lower)-len(query), 49)
+	case len(name.words) > 1 && name.initials == query:
+		return scoreInitials
+	}
+
+	for _, word := range name.words[min(1, len(name.words)):] {
+		if strings.HasPrefix(word, query) {
+			return scoreWordPrefix
+		}
+	}
+
+	switch {
+	case len(name.words) > 1 && strings.HasPrefix(name.initials, query):
+		return scoreInitialsPrefix
+	case strings.Contains(name.lower, query):
+		return scoreSubstring
+	}
+
+	return scoreFuzzy(query, name)
+}
+
+// scoreFuzzy matches the query as a subsequence of the name, rewarding characters
+// that begin words or directly follow the previous match. Returns zero if the
+// query is not a subsequence of the name.
+func scoreFuzzy(query string, name appName) int {
+	bonus := 0
+	pos := 0
+	last := -2
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		// Find the next occurrence of c, preferring one that starts a word as long
+		// as the rest of the query can still be matched after it
+		next := strings.IndexByte(name.lower[pos:], c)
+		if next < 0 {
+			return 0
+		}
+		next += pos
+
+		for j := next; j < len(name.lower); j++ {
+			if name.lower[j] == c && name.starts[j] && isSubsequence(query[i+1:], name.lower[j+1:]) {
+				next = j
+				break
+			}
+		}
+
+		if name.starts[next] {
+			bonus += 15
+		}
+		if next == last+1 {
+			bonus += 10
+		}
+		bonus -= min(next-pos, 5)
+
+		last = next
+		pos = next + 1
+	}
+
+	return scoreSubsequence + min(max(bonus, 0), maxSubsequenceBonus)
+}
+
+// scoreBundleID matches the query against the components of a bundle identifier.
+// The top-level domain is skipped so "com" doesn't match every application.
+func scoreBundleID(query, bundleID string) int {
+	if bundleID == "" {
+		return 0
+	}
+
+	if bundleID == query {
+		return scoreExact
+	}
+
+	parts := strings.Split(bundleID, ".")
+	best := 0
+	for _, part := range parts[min(1, len(parts)):] {
+		switch {
+		case part == query:
+			best = max(best, scoreBundleWord)
+		case strings.HasPrefix(part, query):
+			best = max(best, scoreBundlePrefix)
+		}
+	}
+
+	return best
+}
+
+// isSubsequence reports whether every byte of sub appears in s in order
+func isSubsequence(sub, s string) bool {
+	for i := 0; i < len(sub); i++ {
+		idx := strings.IndexByte(s, sub[i])
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+1:]
+	}
+	return true
+}
+
+// splitWords splits a name into lowercase words on whitespace, punctuation and
+// camelCase boundaries, e.g. "Visual Studio Code" or "iTerm2" or "FaceTime"
+func splitWords(name string) []string {
+	words := []string{}
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// wordStarts reports, for each byte of the lowercased name, whether it begins a word
+func wordStarts(name string) []bool {
+	lower := strings.ToLower(name)
+	starts := make([]bool, len(lower))
+
+	var prev rune
+	offset := 0
+	for i, r := range name {
+		// Lowercasing can change the byte length of some runes, so track the
+		// offset into the lowercase string separately
+		lr := unicode.ToLower(r)
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		prevWord := unicode.IsLetter(prev) || unicode.IsDigit(prev)
+
+		if offset < len(starts) && isWord && (i == 0 || !prevWord || (unicode.IsUpper(r) && unicode.IsLower(prev))) {
+			starts[offset] = true
+		}
+
+		offset += len(string(lr))
+		prev = r
+	}
+
+	return starts
+}
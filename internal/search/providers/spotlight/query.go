@@ -0,0 +1,467 @@
+package spotlight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mdQuery is a parsed Spotlight query made up of free text terms and structured
+// filters, e.g. `kind:pdf modified:this week in:~/Documents budget`
+type mdQuery struct {
+	terms      []string // Free text terms
+	predicates []string // Metadata predicates built from filters, joined with &&
+	onlyIn     []string // Directories to limit the search to
+	raw        string   // Raw kMDItem predicate passed through untouched
+	interpret  bool     // Whether unknown filters mean the text should be interpreted by mdfind
+	text       string   // The query text with in: filters removed, used for -interpret
+}
+
+// kindContentTypes maps friendly kind: names to the uniform type identifiers they match
+var kindContentTypes = map[string]string{
+	"app":          "com.apple.application-bundle",
+	"application":  "com.apple.application-bundle",
+	"archive":      "public.archive",
+	"audio":        "public.audio",
+	"code":         "public.source-code",
+	"contact":      "public.contact",
+	"document":     "public.content",
+	"email":        "com.apple.mail.emlx",
+	"folder":       "public.folder",
+	"image":        "public.image",
+	"mail":         "com.apple.mail.emlx",
+	"movie":        "public.movie",
+	"music":        "public.audio",
+	"pdf":          "com.adobe.pdf",
+	"presentation": "public.presentation",
+	"source":       "public.source-code",
+	"spreadsheet":  "public.spreadsheet",
+	"text":         "public.text",
+	"video":        "public.movie",
+}
+
+// dateAttributes maps date filter names to the metadata attribute they compare
+var dateAttributes = map[string]string{
+	"modified": "kMDItemFSContentChangeDate",
+	"date":     "kMDItemFSContentChangeDate",
+	"created":  "kMDItemFSCreationDate",
+	"opened":   "kMDItemLastUsedDate",
+}
+
+// sizeUnits maps size suffixes to their multiplier in bytes
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseQuery parses a query using the filter grammar. Relative dates are
+// resolved against now.
+//
+// Supported filters:
+//
+//	kind:pdf            content type (see kindContentTypes), or a kind name like "keynote"
+//	ext:go              file extension
+//	in:~/src            limit the search to a directory
+//	author:alice        document author
+//	modified:<range>    today, yesterday, this week, last month, 7d, 2w, 3m, 1y,
+//	                    2024-01-31, >2024-01, <=2023, 2024-01-01..2024-02-01
+//	created:, opened:   as modified, for creation and last opened dates
+//	size:>10MB          >, >=, <, <=, or a range like 1MB..1GB
+//
+// A query starting with kMDItem is passed to mdfind untouched as a raw predicate.
+func parseQuery(input string, now time.Time) (mdQuery, error) {
+	input = strings.TrimSpace(input)
+
+	if strings.HasPrefix(strings.TrimLeft(input, "( "), "kMDItem") {
+		return mdQuery{raw: input}, nil
+	}
+
+	q := mdQuery{}
+	textParts := []string{}
+
+	tokens := tokenizeQuery(input)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		key, value, ok := strings.Cut(token, ":")
+		key = strings.ToLower(key)
+		if !ok || !isFilterKey(key) || strings.HasPrefix(value, "//") {
+			// Plain term, or something like a URL
+			q.terms = append(q.terms, token)
+			textParts = append(textParts, token)
+			continue
+		}
+
+		// Relative dates may be written as two words, e.g. "date:this week"
+		if _, isDate := dateAttributes[key]; isDate && i+1 < len(tokens) {
+			switch strings.ToLower(value) {
+			case "this", "last", "past":
+				value += " " + tokens[i+1]
+				i++
+			}
+		}
+
+		if value == "" {
+			if !isKnownFilter(key) {
+				// Not a filter we understand, so don't complain while it's being typed
+				q.terms = append(q.terms, token)
+				textParts = append(textParts, token)
+				continue
+			}
+			return mdQuery{}, fmt.Errorf("missing value for %s:", key)
+		}
+
+		switch key {
+		case "kind":
+			if contentType, ok := kindContentTypes[strings.ToLower(value)]; ok {
+				q.predicates = append(q.predicates, fmt.Sprintf(`kMDItemContentTypeTree == "%s"`, contentType))
+			} else {
+				q.predicates = append(q.predicates, fmt.Sprintf(`kMDItemKind == "*%s*"cd`, escapePredicateValue(value)))
+			}
+		case "ext":
+			q.predicates = append(q.predicates, fmt.Sprintf(`kMDItemFSName == "*.%s"c`, escapePredicateValue(strings.TrimPrefix(value, "."))))
+		case "in":
+			q.onlyIn = append(q.onlyIn, expandHome(value))
+			continue
+		case "author":
+			q.predicates = append(q.predicates, fmt.Sprintf(`kMDItemAuthors == "*%s*"cd`, escapePredicateValue(value)))
+		case "size":
+			predicate, err := parseSizeFilter(value)
+			if err != nil {
+				return mdQuery{}, err
+			}
+			q.predicates = append(q.predicates, predicate)
+		default:
+			if attribute, isDate := dateAttributes[key]; isDate {
+				r, err := parseTimeRange(value, now)
+				if err != nil {
+					return mdQuery{}, err
+				}
+				q.predicates = append(q.predicates, r.predicate(attribute))
+			} else {
+				// Unknown filter, let mdfind interpret the query the way Finder would
+				q.interpret = true
+			}
+		}
+
+		textParts = append(textParts, key+":"+value)
+	}
+
+	q.text = strings.Join(textParts, " ")
+
+	return q, nil
+}
+
+// hasFilters reports whether the query uses anything other than free text terms
+func (q mdQuery) hasFilters() bool {
+	return q.raw != "" || q.interpret || len(q.predicates) > 0 || len(q.onlyIn) > 0
+}
+
+// args returns the arguments to pass to mdfind to run the query
+func (q mdQuery) args() []string {
+	args := []string{}
+	for _, dir := range q.onlyIn {
+		args = append(args, "-onlyin", dir)
+	}
+
+	switch {
+	case q.raw != "":
+		return append(args, q.raw)
+	case q.interpret:
+		return append(args, "-interpret", q.text)
+	}
+
+	predicates := []string{}
+	for _, term := range q.terms {
+		term = escapePredicateValue(term)
+		predicates = append(predicates, fmt.Sprintf(`(kMDItemDisplayName == "*%s*"cd || kMDItemTextContent == "%s*"cdw)`, term, term))
+	}
+	predicates = append(predicates, q.predicates...)
+
+	if len(predicates) == 0 {
+		// Only a directory was given, list everything in it
+		predicates = append(predicates, `kMDItemFSName == "*"`)
+	}
+
+	return append(args, strings.Join(predicates, " && "))
+}
+
+// tokenizeQuery splits a query on whitespace, keeping double quoted sections
+// together and removing the quotes, e.g. in:"~/My Files" is a single token
+func tokenizeQuery(input string) []string {
+	tokens := []string{}
+	var current strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// isFilterKey reports whether key looks like a filter name rather than part of
+// a term such as a time (12:30)
+func isFilterKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isKnownFilter reports whether key is one of the filters in the grammar
+func isKnownFilter(key string) bool {
+	switch key {
+	case "kind", "ext", "in", "author", "size":
+		return true
+	}
+
+	_, isDate := dateAttributes[key]
+	return isDate
+}
+
+// escapePredicateValue escapes a value for use inside a quoted predicate string
+func escapePredicateValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(homeDir, path[1:])
+}
+
+// timeRange is a half-open range of time [from, to). A zero bound is unbounded.
+type timeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// predicate returns a metadata predicate matching the attribute against the range
+func (r timeRange) predicate(attribute string) string {
+	parts := []string{}
+	if !r.from.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s >= $time.iso(%s)", attribute, r.from.UTC().Format(time.RFC3339)))
+	}
+	if !r.to.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s < $time.iso(%s)", attribute, r.to.UTC().Format(time.RFC3339)))
+	}
+
+	return strings.Join(parts, " && ")
+}
+
+// parseTimeRange parses a date filter value into a time range
+func parseTimeRange(value string, now time.Time) (timeRange, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Monday is the first day of the week
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "today":
+		return timeRange{from: today}, nil
+	case "yesterday":
+		return timeRange{from: today.AddDate(0, 0, -1), to: today}, nil
+	case "this week":
+		return timeRange{from: weekStart}, nil
+	case "last week":
+		return timeRange{from: weekStart.AddDate(0, 0, -7), to: weekStart}, nil
+	case "this month":
+		return timeRange{from: monthStart}, nil
+	case "last month":
+		return timeRange{from: monthStart.AddDate(0, -1, 0), to: monthStart}, nil
+	case "this year":
+		return timeRange{from: yearStart}, nil
+	case "last year":
+		return timeRange{from: yearStart.AddDate(-1, 0, 0), to: yearStart}, nil
+	}
+
+	// Relative durations such as 7d, 2w, 3m or 1y, optionally written "past 7d"
+	if n, unit, ok := parseRelativeDuration(strings.TrimPrefix(value, "past ")); ok {
+		switch unit {
+		case 'd':
+			return timeRange{from: today.AddDate(0, 0, -n)}, nil
+		case 'w':
+			return timeRange{from: today.AddDate(0, 0, -7*n)}, nil
+		case 'm':
+			return timeRange{from: today.AddDate(0, -n, 0)}, nil
+		case 'y':
+			return timeRange{from: today.AddDate(-n, 0, 0)}, nil
+		}
+	}
+
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		start, _, err := parseDatePeriod(from, now.Location())
+		if err != nil {
+			return timeRange{}, err
+		}
+		_, end, err := parseDatePeriod(to, now.Location())
+		if err != nil {
+			return timeRange{}, err
+		}
+		return timeRange{from: start, to: end}, nil
+	}
+
+	op, dateText := splitComparison(value)
+	start, end, err := parseDatePeriod(dateText, now.Location())
+	if err != nil {
+		return timeRange{}, err
+	}
+
+	switch op {
+	case ">":
+		return timeRange{from: end}, nil
+	case ">=":
+		return timeRange{from: start}, nil
+	case "<":
+		return timeRange{to: start}, nil
+	case "<=":
+		return timeRange{to: end}, nil
+	default:
+		return timeRange{from: start, to: end}, nil
+	}
+}
+
+// parseRelativeDuration parses values like 7d into a count and unit
+func parseRelativeDuration(value string) (int, byte, bool) {
+	if len(value) < 2 {
+		return 0, 0, false
+	}
+
+	unit := value[len(value)-1]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, 0, false
+	}
+
+	return n, unit, strings.IndexByte("dwmy", unit) >= 0
+}
+
+// parseDatePeriod parses a date written as a day, month or year and returns
+// the start of that period and the start of the following one
+func parseDatePeriod(value string, loc *time.Location) (time.Time, time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01", value, loc); err == nil {
+		return t, t.AddDate(0, 1, 0), nil
+	}
+	if t, err := time.ParseInLocation("2006", value, loc); err == nil {
+		return t, t.AddDate(1, 0, 0), nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD, YYYY-MM or YYYY", value)
+}
+
+// splitComparison splits a leading comparison operator from a value
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, strings.TrimSpace(value[len(op):])
+		}
+	}
+	return "", value
+}
+
+// parseSizeFilter parses a size filter value into a kMDItemFSSize predicate
+func parseSizeFilter(value string) (string, error) {
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		lower, err := parseSize(from)
+		if err != nil {
+			return "", err
+		}
+		upper, err := parseSize(to)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("kMDItemFSSize >= %d && kMDItemFSSize <= %d", lower, upper), nil
+	}
+
+	op, sizeText := splitComparison(value)
+	size, err := parseSize(sizeText)
+	if err != nil {
+		return "", err
+	}
+
+	switch op {
+	case "", "=":
+		// A bare size means "at least this big"
+		op = ">="
+	}
+
+	return fmt.Sprintf("kMDItemFSSize %s %d", op, size), nil
+}
+
+// parseSize parses a size like 10MB or 1.5GiB into bytes
+func parseSize(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	split := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split < 0 {
+		split = len(value)
+	}
+
+	number, unit := value[:split], strings.TrimSpace(value[split:])
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", unit)
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
@@ -0,0 +1,259 @@
+package spotlight
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// queryNow is a Wednesday, so weeks start on Monday 2024-03-11
+var queryNow = time.Date(2024, time.March, 13, 15, 4, 5, 0, time.UTC)
+
+func TestParseQuery(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  mdQuery
+	}{
+		{
+			name:  "terms only",
+			input: "quarterly  report",
+			want:  mdQuery{terms: []string{"quarterly", "report"}, text: "quarterly report"},
+		},
+		{
+			name:  "kind content type",
+			input: "kind:PDF budget",
+			want: mdQuery{
+				terms:      []string{"budget"},
+				predicates: []string{`kMDItemContentTypeTree == "com.adobe.pdf"`},
+				text:       "kind:PDF budget",
+			},
+		},
+		{
+			name:  "kind name",
+			input: "kind:keynote",
+			want: mdQuery{
+				predicates: []string{`kMDItemKind == "*keynote*"cd`},
+				text:       "kind:keynote",
+			},
+		},
+		{
+			name:  "ext",
+			input: "ext:.go",
+			want: mdQuery{
+				predicates: []string{`kMDItemFSName == "*.go"c`},
+				text:       "ext:.go",
+			},
+		},
+		{
+			name:  "in with quoted home path",
+			input: `in:"~/My Files" notes`,
+			want: mdQuery{
+				terms:  []string{"notes"},
+				onlyIn: []string{filepath.Join(home, "My Files")},
+				text:   "notes",
+			},
+		},
+		{
+			name:  "author escapes backslashes",
+			input: `author:"a\"b"`,
+			want: mdQuery{
+				predicates: []string{`kMDItemAuthors == "*a\\b*"cd`},
+				text:       `author:a\b`,
+			},
+		},
+		{
+			name:  "modified today",
+			input: "modified:today",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSContentChangeDate >= $time.iso(2024-03-13T00:00:00Z)"},
+				text:       "modified:today",
+			},
+		},
+		{
+			name:  "modified two words",
+			input: "modified:this week",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSContentChangeDate >= $time.iso(2024-03-11T00:00:00Z)"},
+				text:       "modified:this week",
+			},
+		},
+		{
+			name:  "created last month",
+			input: "created:last month",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSCreationDate >= $time.iso(2024-02-01T00:00:00Z) && kMDItemFSCreationDate < $time.iso(2024-03-01T00:00:00Z)"},
+				text:       "created:last month",
+			},
+		},
+		{
+			name:  "opened relative duration",
+			input: "opened:past 2w",
+			want: mdQuery{
+				predicates: []string{"kMDItemLastUsedDate >= $time.iso(2024-02-28T00:00:00Z)"},
+				text:       "opened:past 2w",
+			},
+		},
+		{
+			name:  "date after month",
+			input: "date:>2024-01",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSContentChangeDate >= $time.iso(2024-02-01T00:00:00Z)"},
+				text:       "date:>2024-01",
+			},
+		},
+		{
+			name:  "modified up to year",
+			input: "modified:<=2023",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSContentChangeDate < $time.iso(2024-01-01T00:00:00Z)"},
+				text:       "modified:<=2023",
+			},
+		},
+		{
+			name:  "modified date range",
+			input: "modified:2024-01-01..2024-02-01",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSContentChangeDate >= $time.iso(2024-01-01T00:00:00Z) && kMDItemFSContentChangeDate < $time.iso(2024-02-02T00:00:00Z)"},
+				text:       "modified:2024-01-01..2024-02-01",
+			},
+		},
+		{
+			name:  "size comparison",
+			input: "size:>10MB",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSSize > 10000000"},
+				text:       "size:>10MB",
+			},
+		},
+		{
+			name:  "bare size is a minimum",
+			input: "size:1.5KiB",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSSize >= 1536"},
+				text:       "size:1.5KiB",
+			},
+		},
+		{
+			name:  "size range",
+			input: "size:1MB..1GB",
+			want: mdQuery{
+				predicates: []string{"kMDItemFSSize >= 1000000 && kMDItemFSSize <= 1000000000"},
+				text:       "size:1MB..1GB",
+			},
+		},
+		{
+			name:  "raw predicate",
+			input: ` (kMDItemFSName == "*.go") `,
+			want:  mdQuery{raw: `(kMDItemFSName == "*.go")`},
+		},
+		{
+			name:  "unknown filter is interpreted",
+			input: "tag:red photos",
+			want: mdQuery{
+				terms:     []string{"photos"},
+				interpret: true,
+				text:      "tag:red photos",
+			},
+		},
+		{
+			name:  "unknown filter without value is a term",
+			input: "tag: photos",
+			want:  mdQuery{terms: []string{"tag:", "photos"}, text: "tag: photos"},
+		},
+		{
+			name:  "times and urls are terms",
+			input: "12:30 https://example.com",
+			want: mdQuery{
+				terms: []string{"12:30", "https://example.com"},
+				text:  "12:30 https://example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQuery(tt.input, queryNow)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQuery(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"kind:", "missing value for kind:"},
+		{"size:", "missing value for size:"},
+		{"modified:", "missing value for modified:"},
+		{"size:10XB", `invalid size unit "xb"`},
+		{"size:>1.2.3MB", `invalid size "1.2.3mb"`},
+		{"size:1MB..lots", `invalid size unit "lots"`},
+		{"size:1KB..1..2MB", `invalid size "1..2mb"`},
+		{"modified:someday", `invalid date "someday", use YYYY-MM-DD, YYYY-MM or YYYY`},
+		{"created:>2024-13", `invalid date "2024-13", use YYYY-MM-DD, YYYY-MM or YYYY`},
+		{"opened:2024..soon", `invalid date "soon", use YYYY-MM-DD, YYYY-MM or YYYY`},
+		{"opened:later..2024", `invalid date "later", use YYYY-MM-DD, YYYY-MM or YYYY`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseQuery(tt.input, queryNow)
+			if err == nil {
+				t.Fatalf("parseQuery(%q) error = nil, want %q", tt.input, tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("parseQuery(%q) error = %q, want %q", tt.input, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			input: "report kind:pdf",
+			want:  []string{`(kMDItemDisplayName == "*report*"cd || kMDItemTextContent == "report*"cdw) && kMDItemContentTypeTree == "com.adobe.pdf"`},
+		},
+		{
+			input: "in:/tmp",
+			want:  []string{"-onlyin", "/tmp", `kMDItemFSName == "*"`},
+		},
+		{
+			input: "in:/tmp tag:red",
+			want:  []string{"-onlyin", "/tmp", "-interpret", "tag:red"},
+		},
+		{
+			input: `kMDItemFSSize > 0`,
+			want:  []string{`kMDItemFSSize > 0`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := parseQuery(tt.input, queryNow)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tt.input, err)
+			}
+			if got := q.args(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
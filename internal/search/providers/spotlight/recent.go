@@ -0,0 +1,131 @@
+package spotlight
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MordFustang21/marvin-go/internal/search"
+)
+
+// maxRecentCandidates limits how many recently used paths are looked up with mdls
+const maxRecentCandidates = 200
+
+// mdlsDateLayout is the format mdls uses for dates
+const mdlsDateLayout = "2006-01-02 15:04:05 -0700"
+
+// RecentFile is a file or application along with when it was last used
+type RecentFile struct {
+	Result   search.SearchResult
+	LastUsed time.Time
+}
+
+// RecentlyUsed returns the files and applications Spotlight has recorded as
+// used since the given time, most recently used first
+func (p *Provider) RecentlyUsed(ctx context.Context, since time.Time, limit int) ([]RecentFile, error) {
+	predicate := fmt.Sprintf("kMDItemLastUsedDate >= $time.iso(%s)", since.UTC().Format(time.RFC3339))
+
+	out, err := exec.CommandContext(ctx, "mdfind", predicate).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recent files: %w", err)
+	}
+
+	paths := []string{}
+	for _, path := range strings.Split(string(out), "\n") {
+		if path == "" || !isUserPath(path) {
+			continue
+		}
+
+		paths = append(paths, path)
+		if len(paths) >= maxRecentCandidates {
+			break
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	dates, err := lastUsedDates(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	recent := []RecentFile{}
+	for i, path := range paths {
+		if dates[i].IsZero() {
+			continue
+		}
+		recent = append(recent, RecentFile{Result: search.SearchResult{Path: path}, LastUsed: dates[i]})
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].LastUsed.After(recent[j].LastUsed)
+	})
+
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+
+	// Only build full results, which runs mdls for each path, for the ones that are kept
+	for i := range recent {
+		result, err := p.createSearchResultFromPath(recent[i].Result.Path)
+		if err != nil {
+			continue
+		}
+		recent[i].Result = result
+	}
+
+	return recent, nil
+}
+
+// lastUsedDates looks up kMDItemLastUsedDate for all paths with a single mdls
+// call. Paths without a date get the zero time.
+func lastUsedDates(ctx context.Context, paths []string) ([]time.Time, error) {
+	args := append([]string{"-name", "kMDItemLastUsedDate", "-raw", "-nullMarker", ""}, paths...)
+
+	out, err := exec.CommandContext(ctx, "mdls", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last used dates: %w", err)
+	}
+
+	// With -raw, values for multiple files are separated by NUL bytes
+	values := bytes.Split(out, []byte{0})
+
+	dates := make([]time.Time, len(paths))
+	for i := range paths {
+		if i >= len(values) {
+			break
+		}
+		if date, ok := parseMdlsDate(string(values[i])); ok {
+			dates[i] = date
+		}
+	}
+
+	return dates, nil
+}
+
+// parseMdlsDate parses a date printed by mdls, e.g. 2024-05-01 09:30:00 +0000
+func parseMdlsDate(value string) (time.Time, bool) {
+	date, err := time.Parse(mdlsDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// isUserPath reports whether a path is a user's document or application rather
+// than a system or hidden file
+func isUserPath(path string) bool {
+	for _, prefix := range []string{"/System/", "/Library/", "/private/", "/usr/", "/bin/", "/sbin/"} {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	return !strings.Contains(path, "/.")
+}
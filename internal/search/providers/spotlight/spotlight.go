@@ -1,28 +1,48 @@
 package spotlight
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/theme"
 	"github.com/MordFustang21/marvin-go/internal/search"
 	"github.com/MordFustang21/marvin-go/internal/ui/icons"
-	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
 )
 
+var _ search.StreamingProvider = (*Provider)(nil)
+
 // CachedResultProvider is a search provider that uses macOS Spotlight
 type Provider struct {
 	priority   int
 	maxResults int
-	// cachedApps stores pre-indexed applications for quick access
-	cachedApps map[string][]search.SearchResult // maps lowercase name -> results
+	// cachedApps stores pre-indexed applications for quick access.
+	// It is built off to the side and published once complete so searches
+	// never observe a partially populated index.
+	cachedApps atomic.Pointer[appIndex]
+	// cacheReady is closed once cachedApps has been published
+	cacheReady chan struct{}
+	// aliases holds user-defined alternative names for applications
+	aliases atomic.Pointer[aliasTable]
+}
+
+// appIndex is a snapshot of the cached applications.
+// An appIndex is never modified after it has been published.
+type appIndex struct {
+	apps []appEntry
 }
 
 // NewProvider creates a new Spotlight provider
@@ -34,7 +54,7 @@ func NewProvider(priority, maxResults int) *Provider {
 	provider := &Provider{
 		priority:   priority,
 		maxResults: maxResults,
-		cachedApps: make(map[string][]search.SearchResult),
+		cacheReady: make(chan struct{}),
 	}
 
 	// Cache applications in the background
@@ -48,6 +68,12 @@ func (p *Provider) Name() string {
 	return "Spotlight"
 }
 
+// Ready returns a channel that is closed once the application cache has been built.
+// Searches issued before then fall back to querying Spotlight directly.
+func (p *Provider) Ready() <-chan struct{} {
+	return p.cacheReady
+}
+
 // Type returns the provider type
 func (p *Provider) Type() search.ProviderType {
 	return search.TypeFile
@@ -65,8 +91,46 @@ func (p *Provider) CanHandle(query string) bool {
 
 // Search performs a Spotlight search with the given query
 func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	results := []search.SearchResult{}
+	batches := make(chan []search.SearchResult)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- p.SearchStream(context.Background(), query, batches)
+		close(batches)
+	}()
+
+	for batch := range batches {
+		results = append(results, batch...)
+	}
+
+	return results, <-errCh
+}
+
+// SearchStream performs a Spotlight search with the given query, sending
+// results in batches as mdfind finds them
+func (p *Provider) SearchStream(ctx context.Context, query string, out chan<- []search.SearchResult) error {
 	if query == "" {
-		return []search.SearchResult{}, nil
+		return nil
+	}
+
+	// Queries using filters or raw predicates search all files, not just applications
+	parsed, err := parseQuery(query, time.Now())
+	if err != nil {
+		sendBatch(ctx, out, []search.SearchResult{
+			{
+				Title:       fmt.Sprintf("Invalid filter: %s", err.Error()),
+				Description: "Filters: kind:, ext:, in:, author:, modified:, created:, size:",
+				Path:        "spotlight:invalid-filter",
+				Icon:        theme.ErrorIcon(),
+				Type:        search.TypeFile,
+			},
+		})
+		return nil
+	}
+
+	if parsed.hasFilters() {
+		return p.streamMdfind(ctx, parsed.args(), func(string) bool { return true }, out)
 	}
 
 	// Normalize query for case-insensitive matching
@@ -74,45 +138,41 @@ func (p *Provider) Search(query string) ([]search.SearchResult, error) {
 
 	// First check the cached applications and return any matches immediately
 	cachedResults := p.searchCachedApps(queryLower)
-
-	// If we have cached results, return them immediately
 	if len(cachedResults) > 0 {
-		// Return the cached results immediately
-		return cachedResults, nil
+		sendBatch(ctx, out, cachedResults)
+		return nil
 	}
 
-	// If no cached results, perform synchronous search
-	return p.searchSpotlight(queryLower)
+	// If no cached results, fall back to searching spotlight
+	return p.searchSpotlight(ctx, queryLower, out)
 }
 
-// searchCachedApps returns applications from the cache that match the query
-func (p *Provider) searchCachedApps(queryLower string) []search.SearchResult {
-	results := []search.SearchResult{}
+// sendBatch sends a batch of results unless the search has been cancelled.
+// It reports whether the batch was sent.
+func sendBatch(ctx context.Context, out chan<- []search.SearchResult, batch []search.SearchResult) bool {
+	select {
+	case out <- batch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
 
-	for key, cachedResults := range p.cachedApps {
-		if strings.HasPrefix(key, queryLower) || fuzzy.Match(queryLower, key) {
-			// If the key matches, add all cached results for this key
-			for _, result := range cachedResults {
-				// Limit results to maxResults
-				if len(results) >= p.maxResults {
-					break
-				}
-
-				// Create a copy of the result to avoid closure issues
-				resultCopy := result
-				resultCopy.Action = func() {
-					err := OpenFile(result.Path)
-					if err != nil {
-						slog.Error("Failed to open cached application", slog.String("path", result.Path), slog.Any("error", err))
-					}
-				}
-
-				results = append(results, resultCopy)
-			}
+// searchCachedApps returns applications from the cache that match the query, best match first
+func (p *Provider) searchCachedApps(queryLower string) []search.SearchResult {
+	index := p.cachedApps.Load()
+	if index == nil {
+		// Cache is still being built
+		return []search.SearchResult{}
+	}
 
-			// Stop if we've reached the max results limit
-			if len(results) >= p.maxResults {
-				break
+	results := matchApps(index.apps, queryLower, p.loadAliasTable(), p.maxResults)
+	for i := range results {
+		path := results[i].Path
+		results[i].Action = func() {
+			err := opener.Open(path)
+			if err != nil {
+				slog.Error("Failed to open cached application", slog.String("path", path), slog.Any("error", err))
 			}
 		}
 	}
@@ -121,51 +181,127 @@ func (p *Provider) searchCachedApps(queryLower string) []search.SearchResult {
 }
 
 // searchSpotlight performs the actual spotlight search
-func (p *Provider) searchSpotlight(query string) ([]search.SearchResult, error) {
+func (p *Provider) searchSpotlight(ctx context.Context, query string, out chan<- []search.SearchResult) error {
 	// Format the mdfind query
 	// We'll search for applications, files, folders that match the query
 	mdFindQuery := fmt.Sprintf("kind:app %s", query)
 
-	cmd := exec.Command("mdfind", mdFindQuery)
-	var out bytes.Buffer
-	cmd.Stdout = &out
+	// Skip if this is not an application (we're only looking for .app files)
+	return p.streamMdfind(ctx, []string{mdFindQuery}, func(path string) bool {
+		return strings.HasSuffix(path, ".app")
+	}, out)
+}
 
-	err := cmd.Run()
+const (
+	// mdfindBatchSize is how many results are collected before they are sent
+	mdfindBatchSize = 5
+	// mdfindFlushInterval is how long found results wait before a partial batch is sent
+	mdfindFlushInterval = 100 * time.Millisecond
+)
+
+// streamMdfind runs mdfind with the given arguments, sending results for the paths
+// accepted by the filter in batches as mdfind prints them. mdfind is killed once
+// maxResults have been found or ctx is cancelled.
+func (p *Provider) streamMdfind(ctx context.Context, args []string, accept func(path string) bool, out chan<- []search.SearchResult) error {
+	cmdCtx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(cmdCtx, "mdfind", args...)
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("spotlight search failed: %w", err)
+		return fmt.Errorf("spotlight search failed: %w", err)
 	}
 
-	// Process results
-	results := []search.SearchResult{}
-	paths := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("spotlight search failed: %w", err)
+	}
 
-	// Limit results to maxResults
-	count := 0
-	for _, path := range paths {
-		if path == "" {
-			continue
+	// Read paths as mdfind prints them
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			select {
+			case paths <- scanner.Text():
+			case <-cmdCtx.Done():
+				return
+			}
 		}
+	}()
 
-		if count >= p.maxResults {
-			break
-		}
+	ticker := time.NewTicker(mdfindFlushInterval)
+	defer ticker.Stop()
 
-		// Skip if this is not an application (we're only looking for .app files)
-		if !strings.HasSuffix(path, ".app") {
-			continue
+	batch := []search.SearchResult{}
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
 		}
+		sent := sendBatch(ctx, out, batch)
+		batch = []search.SearchResult{}
+		return sent
+	}
 
-		// Create a search result from the path
-		result, err := p.createSearchResultFromPath(path)
-		if err != nil {
-			continue
+	count := 0
+	stoppedEarly := false
+
+read:
+	for {
+		select {
+		case path, ok := <-paths:
+			if !ok {
+				break read
+			}
+
+			if path == "" || !accept(path) {
+				continue
+			}
+
+			// Create a search result from the path
+			result, err := p.createSearchResultFromPath(path)
+			if err != nil {
+				continue
+			}
+
+			batch = append(batch, result)
+			count++
+
+			if count >= p.maxResults {
+				stoppedEarly = true
+				break read
+			}
+
+			if len(batch) >= mdfindBatchSize && !flush() {
+				stoppedEarly = true
+				break read
+			}
+		case <-ticker.C:
+			if !flush() {
+				stoppedEarly = true
+				break read
+			}
+		case <-ctx.Done():
+			stoppedEarly = true
+			break read
 		}
+	}
+
+	flush()
+
+	// Stop mdfind if it's still running and wait for the reader to finish
+	// before reaping the process
+	kill()
+	for range paths {
+	}
 
-		results = append(results, result)
-		count++
+	err = cmd.Wait()
+	if err != nil && !stoppedEarly {
+		return fmt.Errorf("spotlight search failed: %w", err)
 	}
 
-	return results, nil
+	return nil
 }
 
 // createSearchResultFromPath creates a SearchResult from a file path
@@ -241,8 +377,11 @@ func (p *Provider) createSearchResultFromPath(path string) (search.SearchResult,
 		Path:        path,
 		Icon:        iconResource,
 		Type:        search.TypeFile,
+		Preview: func() (*search.Preview, error) {
+			return p.previewFile(pathCopy, kind, iconResource, mdlsInfo)
+		},
 		Action: func() {
-			err := OpenFile(pathCopy)
+			err := opener.Open(pathCopy)
 			if err != nil {
 				slog.Error("Failed to open file", slog.String("path", pathCopy), slog.Any("error", err))
 			}
@@ -250,6 +389,29 @@ func (p *Provider) createSearchResultFromPath(path string) (search.SearchResult,
 	}, nil
 }
 
+// previewFile builds the preview for a file result, adding the Spotlight
+// kind and details to the file's own preview
+func (p *Provider) previewFile(path, kind string, icon fyne.Resource, mdlsInfo MdlsMetadata) (*search.Preview, error) {
+	preview, err := search.NewFilePreview(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// App bundles are directories, so show their icon rather than a listing
+	if kind == "application" {
+		preview.Image = icon
+	}
+
+	preview.SetMetadata("Kind", mdlsInfo.KindDisplayName)
+	preview.AddMetadata("Version", mdlsInfo.Version)
+	preview.AddMetadata("Developer", mdlsInfo.Developer)
+	if !mdlsInfo.LastUsedDate.IsZero() {
+		preview.AddMetadata("Last opened", mdlsInfo.LastUsedDate.Local().Format("Jan 2, 2006 at 3:04 PM"))
+	}
+
+	return preview, nil
+}
+
 // extractNameFromPath extracts the file or folder name from a path
 func (p *Provider) extractNameFromPath(path string) string {
 	parts := strings.Split(path, "/")
@@ -312,7 +474,7 @@ type MdlsMetadata struct {
 	Version         string
 	ContentType     string
 	ContentTypeTree []string
-	LastUsedDate    string
+	LastUsedDate    time.Time
 	Developer       string
 	Description     string
 }
@@ -355,6 +517,67 @@ func (p *Provider) extractAppBundleInfo(appPath string) AppBundleInfo {
 	return info
 }
 
+// extractLocalizedNames returns the app's display names for the user's preferred
+// languages, read from the localized InfoPlist.strings files in the bundle
+func (p *Provider) extractLocalizedNames(appPath string) []string {
+	names := []string{}
+
+	for _, lang := range preferredLanguages() {
+		stringsPath := filepath.Join(appPath, "Contents", "Resources", lang+".lproj", "InfoPlist.strings")
+		if _, err := os.Stat(stringsPath); err != nil {
+			continue
+		}
+
+		// InfoPlist.strings may be text or binary, plutil handles both
+		cmd := exec.Command("plutil", "-convert", "json", "-o", "-", stringsPath)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+
+		if err := cmd.Run(); err != nil {
+			continue
+		}
+
+		var values map[string]any
+		if err := json.Unmarshal(out.Bytes(), &values); err != nil {
+			continue
+		}
+
+		for _, key := range []string{"CFBundleDisplayName", "CFBundleName"} {
+			if name, ok := values[key].(string); ok && name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// preferredLanguages returns the .lproj names to check for localized app names,
+// e.g. "de-DE" and "de" for a user whose preferred language is German
+var preferredLanguages = sync.OnceValue(func() []string {
+	cmd := exec.Command("defaults", "read", "-g", "AppleLanguages")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+
+	// Output is an old-style plist array, e.g. ( "en-US", "de-DE" )
+	langs := []string{}
+	for field := range strings.FieldsFuncSeq(out.String(), func(r rune) bool {
+		return strings.ContainsRune("()\",\n\t ", r)
+	}) {
+		for _, lang := range []string{field, strings.Split(field, "-")[0]} {
+			if !slices.Contains(langs, lang) {
+				langs = append(langs, lang)
+			}
+		}
+	}
+
+	return langs
+})
+
 // extractMdlsMetadata gets additional metadata using mdls command
 func (p *Provider) extractMdlsMetadata(path string) MdlsMetadata {
 	info := MdlsMetadata{}
@@ -398,6 +621,12 @@ func (p *Provider) extractMdlsMetadata(path string) MdlsMetadata {
 		info.Version = fmt.Sprintf("%v", version)
 	}
 
+	if lastUsed, ok := result["kMDItemLastUsedDate"]; ok && lastUsed != nil {
+		if date, ok := parseMdlsDate(fmt.Sprintf("%v", lastUsed)); ok {
+			info.LastUsedDate = date
+		}
+	}
+
 	if developer, ok := result["kMDItemDeveloper"]; ok && developer != nil {
 		info.Developer = fmt.Sprintf("%v", developer)
 	}
@@ -433,12 +662,6 @@ func (p *Provider) getPlistValue(plistPath, key string) string {
 	return value
 }
 
-// OpenFile opens a file or application using the 'open' command
-func OpenFile(path string) error {
-	cmd := exec.Command("open", path)
-	return cmd.Run()
-}
-
 var applicationDirs = []string{
 	"/Applications",
 	"/Applications/Utilities",
@@ -448,15 +671,31 @@ var applicationDirs = []string{
 
 // cacheApplications caches applications from /Applications and /System/Applications
 func (p *Provider) cacheApplications() {
-	for _, dir := range applicationDirs {
-		p.cacheAppsFromDirectory(dir)
+	p.publishAppIndex(p.buildAppIndex(applicationDirs))
+}
+
+// buildAppIndex scans the given directories and returns a new index of the applications found
+func (p *Provider) buildAppIndex(dirs []string) appIndex {
+	index := appIndex{}
+	for _, dir := range dirs {
+		p.cacheAppsFromDirectory(dir, &index)
+	}
+
+	return index
+}
+
+// publishAppIndex makes the index visible to searches and signals readiness.
+// Only the first call closes the ready channel; later calls replace the index.
+func (p *Provider) publishAppIndex(index appIndex) {
+	if p.cachedApps.Swap(&index) == nil {
+		close(p.cacheReady)
 	}
 
-	slog.Debug("Application cache initialized", slog.Int("numEntries", len(p.cachedApps)))
+	slog.Debug("Application cache initialized", slog.Int("numEntries", len(index.apps)))
 }
 
-// cacheAppsFromDirectory scans a directory for .app files and caches them
-func (p *Provider) cacheAppsFromDirectory(dirPath string) {
+// cacheAppsFromDirectory scans a directory for .app files and adds them to the index
+func (p *Provider) cacheAppsFromDirectory(dirPath string, index *appIndex) {
 	// Find all .app files in the directory
 	cmd := exec.Command("find", dirPath, "-name", "*.app", "-maxdepth", "1")
 	var out bytes.Buffer
@@ -481,24 +720,11 @@ func (p *Provider) cacheAppsFromDirectory(dirPath string) {
 			continue
 		}
 
-		// Create searchable index keys:
-		// - Full app name
-		// - Words in app name
-		keys := []string{
-			strings.ToLower(result.Title),
-		}
+		// Index the app by its display name, bundle file name and localized names
+		// so it can be found by any of them
+		bundleID := p.getPlistValue(filepath.Join(path, "Contents", "Info.plist"), "CFBundleIdentifier")
+		names := append([]string{strings.TrimSuffix(filepath.Base(path), ".app")}, p.extractLocalizedNames(path)...)
 
-		// Add individual words as keys
-		words := strings.Fields(strings.ToLower(result.Title))
-		for _, word := range words {
-			if len(word) > 2 && !slices.Contains(keys, word) { // Only add words longer than 2 chars
-				keys = append(keys, word)
-			}
-		}
-
-		// Add to cache using all keys
-		for _, key := range keys {
-			p.cachedApps[key] = append(p.cachedApps[key], result)
-		}
+		index.apps = append(index.apps, newAppEntry(result, bundleID, names...))
 	}
 }
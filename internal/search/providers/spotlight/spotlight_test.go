@@ -0,0 +1,99 @@
+package spotlight
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/MordFustang21/marvin-go/internal/search"
+)
+
+// testIndex builds an index of n applications named "App <generation>-<i>"
+func testIndex(generation, n int) appIndex {
+	index := appIndex{}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("App %d-%d", generation, i)
+		index.apps = append(index.apps, newAppEntry(search.SearchResult{
+			Title: name,
+			Path:  "/Applications/" + name + ".app",
+			Type:  search.TypeFile,
+		}, "com.example.app"))
+	}
+	return index
+}
+
+func TestSearchBeforeIndexPublished(t *testing.T) {
+	p := &Provider{maxResults: 20, cacheReady: make(chan struct{})}
+
+	if results := p.searchCachedApps("app"); len(results) != 0 {
+		t.Fatalf("searchCachedApps before publishing = %d results, want none", len(results))
+	}
+	select {
+	case <-p.Ready():
+		t.Fatal("Ready closed before the index was published")
+	default:
+	}
+}
+
+func TestPublishAppIndexDuringSearch(t *testing.T) {
+	p := &Provider{maxResults: 20, cacheReady: make(chan struct{})}
+
+	const (
+		searchers   = 8
+		generations = 50
+		apps        = 10
+	)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < searchers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				// Every result must come from a single, complete index
+				results := p.searchCachedApps("app")
+				if len(results) != 0 && len(results) != apps {
+					t.Errorf("searchCachedApps = %d results, want 0 or %d", len(results), apps)
+					return
+				}
+				var generation int
+				for j, r := range results {
+					var g, n int
+					if _, err := fmt.Sscanf(r.Title, "App %d-%d", &g, &n); err != nil {
+						t.Errorf("unexpected result %q", r.Title)
+						return
+					}
+					if j == 0 {
+						generation = g
+					} else if g != generation {
+						t.Errorf("results mix indexes %d and %d", generation, g)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	// Rebuild the index repeatedly while the searches run, as a rescan would
+	for g := 0; g < generations; g++ {
+		p.publishAppIndex(testIndex(g, apps))
+	}
+	close(stop)
+	wg.Wait()
+
+	select {
+	case <-p.Ready():
+	default:
+		t.Fatal("Ready not closed after the index was published")
+	}
+	if results := p.searchCachedApps("app 49-"); len(results) != apps {
+		t.Errorf("searchCachedApps after the last rebuild = %d results, want %d", len(results), apps)
+	}
+}
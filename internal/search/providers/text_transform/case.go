@@ -0,0 +1,124 @@
+package texttransform
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// splitWords splits an identifier or phrase into words at spaces,
+// punctuation and case changes, so "parseHTTPRequest", "parse_http_request"
+// and "Parse HTTP request" all give parse, HTTP and request
+func splitWords(text string) []string {
+	var words []string
+	runes := []rune(text)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// A new word starts at an uppercase letter after a lowercase one
+			// or digit, as in parseHttp, or at the last capital of an
+			// acronym followed by lowercase, as in HTTPRequest
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// capitalize uppercases the first letter of a word and lowercases the rest
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
+
+// perLine applies a conversion to each line of text separately
+func perLine(convert func(line string) string) func(string) string {
+	return func(text string) string {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = convert(line)
+		}
+		return strings.Join(lines, "\n")
+	}
+}
+
+// joinWords returns a conversion that joins a line's words with sep after
+// changing each word's case
+func joinWords(sep string, word func(i int, w string) string) func(string) string {
+	return perLine(func(line string) string {
+		words := splitWords(line)
+		for i, w := range words {
+			words[i] = word(i, w)
+		}
+		return strings.Join(words, sep)
+	})
+}
+
+var (
+	toCamel = joinWords("", func(i int, w string) string {
+		if i == 0 {
+			return strings.ToLower(w)
+		}
+		return capitalize(w)
+	})
+	toPascal   = joinWords("", func(_ int, w string) string { return capitalize(w) })
+	toSnake    = joinWords("_", func(_ int, w string) string { return strings.ToLower(w) })
+	toConstant = joinWords("_", func(_ int, w string) string { return strings.ToUpper(w) })
+	toKebab    = joinWords("-", func(_ int, w string) string { return strings.ToLower(w) })
+)
+
+// toTitle capitalizes each word. Lines with spaces keep their punctuation,
+// while identifiers such as parse_http_request are split into words first.
+var toTitle = perLine(func(line string) string {
+	if !strings.ContainsFunc(strings.TrimSpace(line), unicode.IsSpace) {
+		words := splitWords(line)
+		for i, w := range words {
+			words[i] = capitalize(w)
+		}
+		return strings.Join(words, " ")
+	}
+
+	fields := strings.SplitAfter(line, " ")
+	for i, field := range fields {
+		fields[i] = capitalize(field)
+	}
+	return strings.Join(fields, "")
+})
+
+// slugify makes text into a lowercase, dash separated slug for URLs, with
+// accents removed, so "Héllo, World!" becomes hello-world
+func slugify(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	// Lowercasing first keeps words such as iPhone whole
+	return toKebab(strings.ToLower(strings.Join(strings.Fields(b.String()), " ")))
+}
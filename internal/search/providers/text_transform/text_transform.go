@@ -0,0 +1,199 @@
+package texttransform
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+var _ search.Provider = (*Provider)(nil)
+
+// maxPreviewLength is the number of characters of output shown in results
+const maxPreviewLength = 60
+
+// Provider is a search provider for changing text: its case, whitespace,
+// the order of its lines, wrapping and escaping, and counting it. It works on
+// the text after the keyword, as in "snake parseHTTPRequest", or the
+// clipboard if there is none.
+type Provider struct {
+	priority int
+}
+
+// NewProvider creates a new text transform provider
+func NewProvider(priority int) *Provider {
+	return &Provider{
+		priority: priority,
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "Text Transforms"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeSystem
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// CanHandle returns whether the query starts with a transform's keyword or "transform"
+func (p *Provider) CanHandle(query string) bool {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	if strings.EqualFold(keyword, allKeyword) {
+		return true
+	}
+	_, ok := lookupCommand(keyword)
+	return ok
+}
+
+// Search transforms the text after the keyword or the clipboard
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	keyword, text, _ := strings.Cut(strings.TrimSpace(query), " ")
+	text = strings.TrimSpace(text)
+
+	var transforms []transform
+	width := defaultWidth
+	if strings.EqualFold(keyword, allKeyword) {
+		transforms = allTransforms()
+	} else {
+		c, ok := lookupCommand(keyword)
+		if !ok {
+			return nil, nil
+		}
+		transforms = c.transforms
+
+		if c.takesWidth {
+			first, rest, _ := strings.Cut(text, " ")
+			if n, err := strconv.Atoi(first); err == nil {
+				width, text = n, strings.TrimSpace(rest)
+			}
+		}
+	}
+
+	source := "query text"
+	if text == "" {
+		clipText, err := util.GetFromClipboard()
+		if err != nil {
+			return []search.SearchResult{errorResult("Can't read the clipboard", err.Error())}, nil
+		}
+		source = "clipboard"
+		text = strings.TrimRight(strings.ReplaceAll(clipText, "\r\n", "\n"), "\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return []search.SearchResult{errorResult(
+			"Nothing to transform",
+			"Type text after the keyword or copy some to the clipboard",
+		)}, nil
+	}
+
+	results := make([]search.SearchResult, 0, len(transforms))
+	for _, t := range transforms {
+		output, err := t.apply(text, width)
+		if err != nil {
+			results = append(results, errorResult(fmt.Sprintf("%s: can't transform %s", t.name, source), err.Error()))
+			continue
+		}
+		results = append(results, transformResult(t, text, output, source))
+	}
+
+	slog.Debug("Text transform results",
+		slog.String("keyword", keyword),
+		slog.String("source", source),
+		slog.Int("count", len(results)))
+	return results, nil
+}
+
+// transformResult creates a result that shows a transform's output and
+// copies it when chosen. The preview pane shows all of it.
+func transformResult(t transform, input, output, source string) search.SearchResult {
+	var description string
+	switch {
+	case t.count:
+		description = fmt.Sprintf("In %s. Press Enter to copy", source)
+	case output == input:
+		description = fmt.Sprintf("Same as %s. Press Enter to copy", source)
+	default:
+		description = fmt.Sprintf("%s from %s. Press Enter to copy", summary(output), source)
+	}
+
+	return search.SearchResult{
+		Title:       fmt.Sprintf("%s: %s", t.name, preview(output)),
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "texttransform:" + t.name,
+		Icon:        theme.DocumentIcon(),
+		Action: func() {
+			if err := util.CopyToClipboard(output); err != nil {
+				slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+			}
+		},
+		Preview: func() (*search.Preview, error) {
+			p := &search.Preview{Title: t.name, Text: output}
+			p.AddMetadata("From", source)
+			if !t.count {
+				p.AddMetadata("Size", summary(output))
+			}
+			return p, nil
+		},
+	}
+}
+
+// summary gives the size of text, e.g. "3 lines, 42 characters"
+func summary(text string) string {
+	return fmt.Sprintf("%s, %s",
+		plural(strings.Count(text, "\n")+1, "%d line"),
+		plural(utf8.RuneCountInString(text), "%d character"))
+}
+
+// plural formats a count into a phrase, adding an s unless it is one
+func plural(n int, phrase string) string {
+	text := fmt.Sprintf(phrase, n)
+	if n != 1 {
+		text += "s"
+	}
+	return text
+}
+
+// errorResult creates a result explaining why a transform can't be made
+func errorResult(title, description string) search.SearchResult {
+	return search.SearchResult{
+		Title:       title,
+		Description: description,
+		Type:        search.TypeSystem,
+		Path:        "texttransform:error:" + title,
+		Icon:        theme.ErrorIcon(),
+	}
+}
+
+// preview shortens text to fit on one line of a result
+func preview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > maxPreviewLength {
+		text = string([]rune(text)[:maxPreviewLength]) + "…"
+	}
+	return text
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeSystem {
+		return errors.New("not a text transform result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package texttransform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultWidth is the column wrap uses unless another is given
+const defaultWidth = 80
+
+// transform is a change to text the provider offers
+type transform struct {
+	// name is shown in results, e.g. "snake_case"
+	name string
+	// apply changes text. width is the column given after wrap.
+	apply func(text string, width int) (string, error)
+	// count is set for transforms whose output is a number about the text
+	// rather than changed text
+	count bool
+}
+
+// command is a keyword and the transforms it shows
+type command struct {
+	keywords []string
+	// usage shows the keyword with its arguments, e.g. "wrap [columns]"
+	usage      string
+	transforms []transform
+	// takesWidth is set for commands that read a column count before the text
+	takesWidth bool
+}
+
+// plain adapts a conversion that can't fail to a transform's apply
+func plain(convert func(string) string) func(string, int) (string, error) {
+	return func(s string, _ int) (string, error) {
+		return convert(s), nil
+	}
+}
+
+var (
+	caseTransforms = []transform{
+		{name: "camelCase", apply: plain(toCamel)},
+		{name: "PascalCase", apply: plain(toPascal)},
+		{name: "snake_case", apply: plain(toSnake)},
+		{name: "CONSTANT_CASE", apply: plain(toConstant)},
+		{name: "kebab-case", apply: plain(toKebab)},
+		{name: "Title Case", apply: plain(toTitle)},
+		{name: "UPPERCASE", apply: plain(strings.ToUpper)},
+		{name: "lowercase", apply: plain(strings.ToLower)},
+	}
+	sortTransforms = []transform{
+		{name: "Sorted", apply: plain(sortItems(strings.Compare))},
+		{name: "Sorted descending", apply: plain(sortItems(func(a, b string) int { return strings.Compare(b, a) }))},
+		{name: "Sorted ignoring case", apply: plain(sortItems(func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}))},
+		{name: "Sorted naturally", apply: plain(sortItems(naturalCompare))},
+	}
+	uniqueTransforms = []transform{
+		{name: "Unique", apply: plain(uniqueItems(false))},
+		{name: "Unique ignoring case", apply: plain(uniqueItems(true))},
+		{name: "Unique and sorted", apply: plain(func(s string) string { return sortItems(strings.Compare)(uniqueItems(false)(s)) })},
+	}
+	reverseTransforms = []transform{
+		{name: "Reversed order", apply: plain(reverseItems)},
+		{name: "Reversed characters", apply: plain(reverseRunes)},
+	}
+	whitespaceTransforms = []transform{
+		{name: "Trimmed", apply: plain(trimLines)},
+		{name: "Collapsed whitespace", apply: plain(collapseWhitespace)},
+	}
+	countTransforms = []transform{
+		{name: "Characters", apply: plain(func(s string) string { return strconv.Itoa(utf8.RuneCountInString(s)) }), count: true},
+		{name: "Characters without spaces", apply: plain(func(s string) string {
+			return strconv.Itoa(utf8.RuneCountInString(strings.Join(strings.Fields(s), "")))
+		}), count: true},
+		{name: "Words", apply: plain(func(s string) string { return strconv.Itoa(len(strings.Fields(s))) }), count: true},
+		{name: "Lines", apply: plain(func(s string) string { return strconv.Itoa(strings.Count(s, "\n") + 1) }), count: true},
+		{name: "Bytes", apply: plain(func(s string) string { return strconv.Itoa(len(s)) }), count: true},
+	}
+	escapeTransforms = []transform{
+		{name: "JSON string", apply: func(s string, _ int) (string, error) { return quoteJSON(s) }},
+		{name: "Shell argument", apply: plain(quoteShell)},
+		{name: "Regular expression", apply: plain(regexp.QuoteMeta)},
+	}
+)
+
+// commands are the keywords the provider handles. Add a command here to
+// make it available.
+var commands = []command{
+	{keywords: []string{"case"}, usage: "case", transforms: caseTransforms},
+	{keywords: []string{"camel", "camelcase"}, usage: "camel", transforms: caseTransforms[0:1]},
+	{keywords: []string{"pascal", "pascalcase"}, usage: "pascal", transforms: caseTransforms[1:2]},
+	{keywords: []string{"snake", "snakecase"}, usage: "snake", transforms: caseTransforms[2:3]},
+	{keywords: []string{"constant", "screaming"}, usage: "constant", transforms: caseTransforms[3:4]},
+	{keywords: []string{"kebab", "kebabcase"}, usage: "kebab", transforms: caseTransforms[4:5]},
+	{keywords: []string{"title", "titlecase"}, usage: "title", transforms: caseTransforms[5:6]},
+	{keywords: []string{"upper", "uppercase"}, usage: "upper", transforms: caseTransforms[6:7]},
+	{keywords: []string{"lower", "lowercase"}, usage: "lower", transforms: caseTransforms[7:8]},
+	{keywords: []string{"slug", "slugify"}, usage: "slug", transforms: []transform{{name: "Slug", apply: plain(slugify)}}},
+	{keywords: []string{"trim"}, usage: "trim", transforms: whitespaceTransforms[0:1]},
+	{keywords: []string{"collapse", "squeeze"}, usage: "collapse", transforms: whitespaceTransforms[1:2]},
+	{keywords: []string{"sort"}, usage: "sort", transforms: sortTransforms},
+	{keywords: []string{"unique", "uniq", "dedupe"}, usage: "unique", transforms: uniqueTransforms},
+	{keywords: []string{"reverse"}, usage: "reverse", transforms: reverseTransforms},
+	{keywords: []string{"count", "wc"}, usage: "count", transforms: countTransforms},
+	{keywords: []string{"wrap"}, usage: "wrap [columns]", transforms: []transform{{name: "Wrapped", apply: wrap}}, takesWidth: true},
+	{keywords: []string{"unwrap"}, usage: "unwrap", transforms: []transform{{name: "Unwrapped", apply: plain(unwrap)}}},
+	{keywords: []string{"escape", "quote"}, usage: "escape", transforms: escapeTransforms},
+	{keywords: []string{"unescape", "unquote"}, usage: "unescape", transforms: []transform{{name: "Unescaped", apply: func(s string, _ int) (string, error) { return unescape(s) }}}},
+}
+
+// allKeyword is the keyword that shows every transform
+const allKeyword = "transform"
+
+// lookupCommand finds the command a keyword names
+func lookupCommand(keyword string) (command, bool) {
+	keyword = strings.ToLower(keyword)
+	for _, c := range commands {
+		if slices.Contains(c.keywords, keyword) {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// allTransforms returns every transform once, in the order of commands
+func allTransforms() []transform {
+	var all []transform
+	seen := map[string]bool{}
+	for _, c := range commands {
+		for _, t := range c.transforms {
+			if !seen[t.name] {
+				seen[t.name] = true
+				all = append(all, t)
+			}
+		}
+	}
+	return all
+}
+
+// splitItems splits text into the items line transforms work on: its lines,
+// or for a single line such as query text, its comma or space separated
+// items. It returns the separator to join them again with.
+func splitItems(text string) ([]string, string) {
+	switch {
+	case strings.Contains(text, "\n"):
+		return strings.Split(text, "\n"), "\n"
+	case strings.Contains(text, ","):
+		items := strings.Split(text, ",")
+		for i, item := range items {
+			items[i] = strings.TrimSpace(item)
+		}
+		return items, ", "
+	default:
+		return strings.Fields(text), " "
+	}
+}
+
+// sortItems returns a conversion that sorts items with compare
+func sortItems(compare func(a, b string) int) func(string) string {
+	return func(s string) string {
+		items, sep := splitItems(s)
+		slices.SortStableFunc(items, compare)
+		return strings.Join(items, sep)
+	}
+}
+
+// naturalCompare compares strings with runs of digits compared as numbers,
+// so file2 sorts before file10
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if c := len(na) - len(nb); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		if c := int(unicode.ToLower(ra)) - int(unicode.ToLower(rb)); c != 0 {
+			return c
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	return len(a) - len(b)
+}
+
+// leadingDigits returns the ASCII digits at the start of s
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// uniqueItems returns a conversion that removes repeated items, keeping the
+// first of each in order
+func uniqueItems(ignoreCase bool) func(string) string {
+	return func(s string) string {
+		items, sep := splitItems(s)
+		seen := map[string]bool{}
+		unique := items[:0]
+		for _, item := range items {
+			key := item
+			if ignoreCase {
+				key = strings.ToLower(item)
+			}
+			if !seen[key] {
+				seen[key] = true
+				unique = append(unique, item)
+			}
+		}
+		return strings.Join(unique, sep)
+	}
+}
+
+// reverseItems reverses the order of items
+func reverseItems(s string) string {
+	items, sep := splitItems(s)
+	slices.Reverse(items)
+	return strings.Join(items, sep)
+}
+
+// reverseRunes reverses text character by character
+func reverseRunes(s string) string {
+	runes := []rune(s)
+	slices.Reverse(runes)
+	return string(runes)
+}
+
+// trimLines removes whitespace from the ends of each line and blank lines
+// from the ends of the text
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// collapseWhitespace turns each run of whitespace, including line breaks,
+// into a single space
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// blankLinePattern matches the blank lines between paragraphs
+var blankLinePattern = regexp.MustCompile(`\n[ \t]*\n`)
+
+// paragraphs splits text at blank lines
+func paragraphs(s string) []string {
+	var paras []string
+	for _, para := range blankLinePattern.Split(s, -1) {
+		if strings.TrimSpace(para) != "" {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
+
+// wrap rewraps each paragraph so lines are at most width characters, apart
+// from words longer than that
+func wrap(s string, width int) (string, error) {
+	if width < 1 {
+		return "", fmt.Errorf("the width must be at least 1")
+	}
+
+	paras := paragraphs(s)
+	for i, para := range paras {
+		var lines []string
+		line := ""
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		paras[i] = strings.Join(append(lines, line), "\n")
+	}
+	return strings.Join(paras, "\n\n"), nil
+}
+
+// unwrap joins the lines of each paragraph into one
+func unwrap(s string) string {
+	paras := paragraphs(s)
+	for i, para := range paras {
+		paras[i] = collapseWhitespace(para)
+	}
+	return strings.Join(paras, "\n\n")
+}
+
+// quoteJSON writes text as a JSON string, without escaping < > and & as
+// encoding/json does by default
+func quoteJSON(s string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// quoteShell quotes text as a single POSIX shell argument
+func quoteShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// unescape reads backslash escapes such as \n, \t, \" and \u00e9, removing
+// the quotes around a quoted string first. Single quoted text is read as a
+// shell argument.
+func unescape(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], `'\''`, "'"), nil
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		s = s[1 : len(s)-1]
+	}
+
+	var b strings.Builder
+	for s != "" {
+		// Quotes don't need escaping outside a quoted string, and \' isn't
+		// an escape in Go strings but is in others
+		switch {
+		case s[0] == '"':
+			b.WriteByte('"')
+			s = s[1:]
+			continue
+		case strings.HasPrefix(s, `\'`):
+			b.WriteByte('\'')
+			s = s[2:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(s, '"')
+		if err != nil {
+			return "", fmt.Errorf("invalid escape at %q", preview(s))
+		}
+		if multibyte {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(byte(r))
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
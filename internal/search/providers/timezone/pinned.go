@@ -0,0 +1,83 @@
+package timezone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// PinnedZone is a time zone always shown by the world clock, such as where a
+// teammate works
+type PinnedZone struct {
+	// Label is shown with the time, e.g. "Alice" or "Berlin office". The
+	// zone's name is used if empty.
+	Label string `json:"label,omitempty"`
+	// Zone is a city, abbreviation, IANA name or offset, e.g. "Berlin",
+	// "PST", "Asia/Kolkata" or "UTC+2"
+	Zone string `json:"zone"`
+}
+
+// SetPinnedZones replaces the pinned zones. Zones that can't be found are
+// logged and skipped.
+func (p *Provider) SetPinnedZones(pinned []PinnedZone) {
+	zones := make([]zone, 0, len(pinned))
+	for _, pin := range pinned {
+		z, ok := lookupZone(pin.Zone)
+		if !ok {
+			slog.Error("Unknown pinned time zone", slog.String("zone", pin.Zone))
+			continue
+		}
+		if pin.Label != "" {
+			z.label = pin.Label
+		}
+		zones = append(zones, z)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pinned = zones
+}
+
+// LoadPinnedZones reads pinned zones from a JSON file of the form
+// [{"label": "Alice", "zone": "Berlin"}, {"zone": "America/New_York"}].
+// If path is empty the default ~/.config/marvin/timezones.json is used.
+// A missing file is not an error.
+func (p *Provider) LoadPinnedZones(path string) error {
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		path = filepath.Join(homeDir, ".config", "marvin", "timezones.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read time zones file: %w", err)
+	}
+
+	var pinned []PinnedZone
+	if err := json.Unmarshal(data, &pinned); err != nil {
+		return fmt.Errorf("failed to parse time zones file: %w", err)
+	}
+
+	p.SetPinnedZones(pinned)
+	slog.Debug("Loaded pinned time zones", slog.String("path", path), slog.Int("numZones", len(pinned)))
+
+	return nil
+}
+
+// pinnedZones returns the current pinned zones
+func (p *Provider) pinnedZones() []zone {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pinned
+}
@@ -0,0 +1,287 @@
+package timezone
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util"
+)
+
+// Provider is a search provider for the time around the world. It shows the
+// current time in a place, as in "time in tokyo", converts times between
+// zones, as in "3pm PST in CET", and lists pinned zones for "time".
+type Provider struct {
+	priority int
+	location *time.Location
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+
+	// mu guards pinned
+	mu     sync.Mutex
+	pinned []zone
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithLocation sets the zone treated as local time. The default is the
+// system's local time zone.
+func WithLocation(location *time.Location) Option {
+	return func(p *Provider) {
+		p.location = location
+	}
+}
+
+// WithClock replaces the clock the current time is read from
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		p.now = now
+	}
+}
+
+// NewProvider creates a new time zone provider
+func NewProvider(priority int, opts ...Option) *Provider {
+	p := &Provider{
+		priority: priority,
+		location: time.Local,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return "World Clock"
+}
+
+// Type returns the provider type
+func (p *Provider) Type() search.ProviderType {
+	return search.TypeCalculator
+}
+
+// Priority returns the provider's priority
+func (p *Provider) Priority() int {
+	return p.priority
+}
+
+// conversion is a parsed query: a time in one zone to show in others
+type conversion struct {
+	at     time.Time
+	source zone
+	// targets are the zones asked for, empty to show the pinned zones
+	targets []zone
+	// explicit is set when the query names a time to convert rather than asking for now
+	explicit bool
+}
+
+// clockWords are queries that show the pinned zones
+var clockWords = map[string]bool{
+	"time":        true,
+	"clock":       true,
+	"world clock": true,
+	"worldclock":  true,
+	"time zones":  true,
+	"timezones":   true,
+}
+
+// timeOfDayPattern matches a time of day such as 3pm, 3:30 pm, 15:00 or noon,
+// optionally followed by a zone
+var timeOfDayPattern = regexp.MustCompile(`^(now|noon|midnight|(\d{1,2})(?::(\d{2}))?\s*(am|pm)|(\d{1,2}):(\d{2}))(?:\s+(.+))?$`)
+
+// targetSeparator matches the word between a time and the zone to convert it to
+var targetSeparator = regexp.MustCompile(`\s+(?:in|to|as)\s+`)
+
+// parse parses a query, returning false if it isn't about times or zones
+func (p *Provider) parse(query string) (*conversion, bool) {
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+	now := p.now()
+	local := zone{label: "Local time", location: p.location}
+
+	if clockWords[lower] {
+		return &conversion{at: now, source: local}, true
+	}
+
+	// "time in tokyo", "time tokyo" and "tokyo time"
+	for _, prefix := range []string{"time in ", "time at ", "time "} {
+		if rest, ok := strings.CutPrefix(lower, prefix); ok {
+			if z, ok := lookupZone(query[len(query)-len(rest):]); ok {
+				return &conversion{at: now, source: local, targets: []zone{z}}, true
+			}
+		}
+	}
+	if rest, ok := strings.CutSuffix(lower, " time"); ok {
+		if z, ok := lookupZone(query[:len(rest)]); ok {
+			return &conversion{at: now, source: local, targets: []zone{z}}, true
+		}
+	}
+
+	// "3pm PST in CET", where the target is optional
+	var targets []zone
+	from := query
+	if locs := targetSeparator.FindAllStringIndex(query, -1); locs != nil {
+		last := locs[len(locs)-1]
+		if z, ok := lookupZone(query[last[1]:]); ok {
+			targets = []zone{z}
+			from = query[:last[0]]
+		}
+	}
+
+	m := timeOfDayPattern.FindStringSubmatch(strings.ToLower(from))
+	if m == nil {
+		return nil, false
+	}
+
+	source := local
+	if m[7] != "" {
+		z, ok := lookupZone(from[len(from)-len(m[7]):])
+		if !ok {
+			return nil, false
+		}
+		source = z
+	}
+
+	conv := &conversion{at: now, source: source, targets: targets}
+	if m[1] != "now" {
+		hour, minute := 0, 0
+		switch {
+		case m[1] == "noon":
+			hour = 12
+		case m[1] == "midnight":
+		case m[4] != "":
+			hour, _ = strconv.Atoi(m[2])
+			minute, _ = strconv.Atoi(m[3])
+			if hour < 1 || hour > 12 {
+				return nil, false
+			}
+			hour %= 12
+			if m[4] == "pm" {
+				hour += 12
+			}
+		default:
+			hour, _ = strconv.Atoi(m[5])
+			minute, _ = strconv.Atoi(m[6])
+		}
+		if hour > 23 || minute > 59 {
+			return nil, false
+		}
+
+		// The time is on today's date where the source zone is
+		year, month, day := now.In(source.location).Date()
+		conv.at = time.Date(year, month, day, hour, minute, 0, 0, source.location)
+		conv.explicit = true
+	}
+
+	return conv, true
+}
+
+// CanHandle returns whether the query asks for a time or time zone conversion
+func (p *Provider) CanHandle(query string) bool {
+	_, ok := p.parse(query)
+	return ok
+}
+
+// Search returns the time in each zone asked for, or in the pinned zones
+func (p *Provider) Search(query string) ([]search.SearchResult, error) {
+	conv, ok := p.parse(query)
+	if !ok {
+		return nil, nil
+	}
+
+	targets := conv.targets
+	if len(targets) == 0 {
+		if conv.explicit && conv.source.location != p.location {
+			targets = append(targets, zone{label: "Local time", location: p.location})
+		}
+		if pinned := p.pinnedZones(); len(pinned) > 0 {
+			targets = append(targets, pinned...)
+		} else {
+			if len(targets) == 0 {
+				targets = append(targets, zone{label: "Local time", location: p.location})
+			}
+			targets = append(targets, zone{label: "UTC", location: time.UTC})
+		}
+	}
+
+	results := make([]search.SearchResult, 0, len(targets))
+	for i, target := range targets {
+		t := conv.at.In(target.location)
+		text := t.Format("15:04 MST, Mon 2 Jan")
+
+		title := fmt.Sprintf("%s: %s", target.label, text)
+		if i == 0 && len(conv.targets) > 0 {
+			title = fmt.Sprintf("%s = %s", strings.TrimSpace(query), text)
+		}
+
+		copyText := t.Format("15:04 MST")
+		results = append(results, search.SearchResult{
+			Title:       title,
+			Description: p.describe(t) + ". Press Enter to copy",
+			Path:        fmt.Sprintf("timezone:%d:%s", i, target.location),
+			Icon:        theme.HistoryIcon(),
+			Type:        search.TypeCalculator,
+			Action: func() {
+				if err := util.CopyToClipboard(copyText); err != nil {
+					slog.Error("Failed to copy to clipboard", slog.Any("error", err))
+				}
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// describe gives a time's zone, UTC offset and difference from local time,
+// e.g. "Asia/Tokyo, UTC+09:00, 7 hours ahead"
+func (p *Provider) describe(t time.Time) string {
+	parts := []string{t.Location().String(), "UTC" + t.Format("-07:00")}
+	if t.IsDST() {
+		parts = append(parts, "daylight saving time")
+	}
+
+	_, offset := t.Zone()
+	_, localOffset := t.In(p.location).Zone()
+	if diff := offset - localOffset; diff != 0 {
+		direction := "ahead"
+		if diff < 0 {
+			direction, diff = "behind", -diff
+		}
+		parts = append(parts, formatHours(diff)+" "+direction)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// formatHours formats an offset in seconds as hours, e.g. "1 hour" or "5.5 hours"
+func formatHours(seconds int) string {
+	hours := strconv.FormatFloat(float64(seconds)/3600, 'f', -1, 64)
+	if hours == "1" {
+		return "1 hour"
+	}
+	return hours + " hours"
+}
+
+// Execute triggers an action for the given result
+func (p *Provider) Execute(result search.SearchResult) error {
+	if result.Type != search.TypeCalculator {
+		return fmt.Errorf("not a time zone result")
+	}
+
+	if result.Action != nil {
+		result.Action()
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package timezone
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Bundle the time zone database so zones work offline and on systems
+	// without one
+	_ "time/tzdata"
+)
+
+// zone is a time zone with the name it was asked for by
+type zone struct {
+	label    string
+	location *time.Location
+}
+
+//go:embed cities.tsv
+var citiesTSV string
+
+// cities maps lowercase city and country names to their zone
+var cities = parseCities(citiesTSV)
+
+// parseCities reads the tab separated city table, skipping comments
+func parseCities(data string) map[string]zone {
+	table := map[string]zone{}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, zoneName, ok := strings.Cut(line, "\t")
+		if !ok {
+			panic(fmt.Sprintf("invalid city table line %q", line))
+		}
+
+		location, err := time.LoadLocation(zoneName)
+		if err != nil {
+			panic(fmt.Sprintf("invalid zone for %s: %v", name, err))
+		}
+		table[strings.ToLower(name)] = zone{label: name, location: location}
+	}
+
+	return table
+}
+
+// abbreviations maps time zone abbreviations to the zone they are used in.
+// Standard and daylight saving abbreviations both map to the zone, so "3pm
+// PST" in July is read as 3pm Pacific time, as people usually mean.
+var abbreviations = map[string]string{
+	"utc": "UTC", "gmt": "UTC", "z": "UTC",
+	"pst": "America/Los_Angeles", "pdt": "America/Los_Angeles", "pt": "America/Los_Angeles",
+	"mst": "America/Denver", "mdt": "America/Denver", "mt": "America/Denver",
+	"cst": "America/Chicago", "cdt": "America/Chicago", "ct": "America/Chicago",
+	"est": "America/New_York", "edt": "America/New_York", "et": "America/New_York",
+	"akst": "America/Anchorage", "akdt": "America/Anchorage",
+	"hst": "Pacific/Honolulu",
+	"bst": "Europe/London",
+	"wet": "Europe/Lisbon", "west": "Europe/Lisbon",
+	"cet": "Europe/Paris", "cest": "Europe/Paris",
+	"eet": "Europe/Athens", "eest": "Europe/Athens",
+	"msk":  "Europe/Moscow",
+	"ist":  "Asia/Kolkata",
+	"pkt":  "Asia/Karachi",
+	"ict":  "Asia/Bangkok",
+	"sgt":  "Asia/Singapore",
+	"hkt":  "Asia/Hong_Kong",
+	"jst":  "Asia/Tokyo",
+	"kst":  "Asia/Seoul",
+	"awst": "Australia/Perth",
+	"acst": "Australia/Adelaide", "acdt": "Australia/Adelaide",
+	"aest": "Australia/Sydney", "aedt": "Australia/Sydney",
+	"nzst": "Pacific/Auckland", "nzdt": "Pacific/Auckland",
+}
+
+// offsetPattern matches fixed offsets such as UTC+2, GMT-5:30 or +0900
+var offsetPattern = regexp.MustCompile(`^(?:utc|gmt)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// lookupZone finds a zone by city or country name, abbreviation, IANA name
+// such as Europe/Berlin, or fixed offset such as UTC+2
+func lookupZone(name string) (zone, bool) {
+	name = strings.TrimSpace(name)
+	lower := strings.ToLower(name)
+	if lower == "" {
+		return zone{}, false
+	}
+
+	if z, ok := cities[lower]; ok {
+		return z, true
+	}
+
+	if zoneName, ok := abbreviations[lower]; ok {
+		location, err := time.LoadLocation(zoneName)
+		if err == nil {
+			return zone{label: strings.ToUpper(name), location: location}, true
+		}
+	}
+
+	if m := offsetPattern.FindStringSubmatch(lower); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		if hours <= 14 && minutes < 60 {
+			offset := hours*3600 + minutes*60
+			if m[1] == "-" {
+				offset = -offset
+			}
+			label := "UTC" + m[1] + m[2]
+			if m[3] != "" {
+				label += ":" + m[3]
+			}
+			return zone{label: label, location: time.FixedZone(label, offset)}, true
+		}
+	}
+
+	// IANA names are case sensitive, so also try the usual capitalization of
+	// names typed in lowercase, e.g. america/new_york
+	if strings.Contains(name, "/") {
+		for _, candidate := range []string{name, capitalizeZone(name)} {
+			if location, err := time.LoadLocation(candidate); err == nil {
+				// Label zones by their city, e.g. New York for America/New_York
+				city := location.String()[strings.LastIndex(location.String(), "/")+1:]
+				return zone{label: strings.ReplaceAll(city, "_", " "), location: location}, true
+			}
+		}
+	}
+
+	return zone{}, false
+}
+
+// capitalizeZone capitalizes each word of an IANA zone name, with spaces
+// read as underscores, e.g. "america/new york" becomes "America/New_York"
+func capitalizeZone(name string) string {
+	parts := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '_'
+	})
+	for i, part := range parts {
+		segments := strings.Split(part, "/")
+		for j, segment := range segments {
+			if segment != "" {
+				segments[j] = strings.ToUpper(segment[:1]) + segment[1:]
+			}
+		}
+		parts[i] = strings.Join(segments, "/")
+	}
+	return strings.Join(parts, "_")
+}
@@ -3,12 +3,12 @@ package web
 import (
 	"fmt"
 	"net/url"
-	"os/exec"
 	"regexp"
 	"strings"
 
 	"fyne.io/fyne/v2/theme"
 	"github.com/MordFustang21/marvin-go/internal/search"
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
 )
 
 // Provider is a search provider that handles web searches and direct URL opening
@@ -112,8 +112,7 @@ func (p *Provider) Search(query string) ([]search.SearchResult, error) {
 
 // openURL opens the given URL in the default browser
 func (p *Provider) openURL(url string) error {
-	cmd := exec.Command("open", url)
-	return cmd.Run()
+	return opener.Open(url)
 }
 
 // Execute triggers an action for the given result
@@ -7,6 +7,12 @@ import (
 	"sync"
 )
 
+// priorityResult is a batch of results from a provider with the given priority
+type priorityResult struct {
+	priority int
+	results  []SearchResult
+}
+
 // Registry manages search providers and dispatches search requests
 type Registry struct {
 	providers []Provider
@@ -37,19 +43,8 @@ func (r *Registry) RegisterProvider(provider Provider) {
 
 // SearchAsync performs a search and sends results as they arrive, ordered by provider priority.
 // It accepts a context for cancellation to stop the search when needed.
+// An empty query returns the default results of providers that have them.
 func (r *Registry) SearchAsync(ctx context.Context, query string, resultsCh chan<- []SearchResult, errCh chan<- error, doneCh chan<- struct{}) {
-	if query == "" {
-		close(resultsCh)
-		if doneCh != nil {
-			doneCh <- struct{}{}
-			close(doneCh)
-		}
-		if errCh != nil {
-			close(errCh)
-		}
-		return
-	}
-
 	// Reset tracking maps
 	r.sentResults = make(map[string]bool)
 
@@ -57,11 +52,6 @@ func (r *Registry) SearchAsync(ctx context.Context, query string, resultsCh chan
 	var sentMutex sync.Mutex
 
 	// Create channels for collecting results from providers
-	type priorityResult struct {
-		priority int
-		results  []SearchResult
-	}
-	
 	// Channel for results from individual providers
 	resultCollector := make(chan priorityResult)
 	
@@ -73,7 +63,11 @@ func (r *Registry) SearchAsync(ctx context.Context, query string, resultsCh chan
 
 	// Start provider searches
 	for _, provider := range r.providers {
-		if !provider.CanHandle(query) {
+		if query == "" {
+			if _, ok := provider.(DefaultResultsProvider); !ok {
+				continue
+			}
+		} else if !provider.CanHandle(query) {
 			continue
 		}
 
@@ -92,7 +86,18 @@ func (r *Registry) SearchAsync(ctx context.Context, query string, resultsCh chan
 				// Continue with search
 			}
 			
-			results, err := p.Search(query)
+			var results []SearchResult
+			var err error
+
+			if dp, ok := p.(DefaultResultsProvider); ok && query == "" {
+				results, err = dp.DefaultResults()
+			} else if sp, ok := p.(StreamingProvider); ok {
+				// Streaming providers send batches as they find them
+				r.searchStream(ctx, sp, query, prio, resultCollector, errCh)
+				return
+			} else {
+				results, err = p.Search(query)
+			}
 			
 			// Check context again after search
 			select {
@@ -199,6 +204,39 @@ func (r *Registry) SearchAsync(ctx context.Context, query string, resultsCh chan
 	}()
 }
 
+// searchStream runs a streaming provider, forwarding each batch of results to the collector
+func (r *Registry) searchStream(ctx context.Context, p StreamingProvider, query string, prio int, collector chan<- priorityResult, errCh chan<- error) {
+	batches := make(chan []SearchResult)
+	streamErr := make(chan error, 1)
+
+	go func() {
+		streamErr <- p.SearchStream(ctx, query, batches)
+		close(batches)
+	}()
+
+	for batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+
+		select {
+		case collector <- priorityResult{priority: prio, results: batch}:
+		case <-ctx.Done():
+			// Drain remaining batches so the provider can return
+			for range batches {
+			}
+			return
+		}
+	}
+
+	if err := <-streamErr; err != nil && ctx.Err() == nil && errCh != nil {
+		select {
+		case errCh <- err:
+		case <-ctx.Done():
+		}
+	}
+}
+
 // ExecuteResult triggers the execution of a specific search result
 func (r *Registry) ExecuteResult(result SearchResult) error {
 	// Find the provider that can handle this result type
@@ -211,6 +249,45 @@ func (r *Registry) ExecuteResult(result SearchResult) error {
 	return fmt.Errorf("no provider found for result type %s", result.Type)
 }
 
+// ParentQuery returns the query one level above the given query, as defined by
+// the first provider that can handle the query and supports navigating up
+func (r *Registry) ParentQuery(query string) (string, bool) {
+	for _, provider := range r.providers {
+		pp, ok := provider.(ParentQueryProvider)
+		if !ok || !provider.CanHandle(query) {
+			continue
+		}
+
+		if parent, ok := pp.ParentQuery(query); ok {
+			return parent, true
+		}
+	}
+
+	return "", false
+}
+
+// Volatile returns whether any provider that can handle the query makes
+// different results each time it is searched
+func (r *Registry) Volatile(query string) bool {
+	for _, provider := range r.providers {
+		vp, ok := provider.(VolatileProvider)
+		if ok && provider.CanHandle(query) && vp.Volatile(query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RecordLaunch tells every provider that keeps a launch history that the result was launched
+func (r *Registry) RecordLaunch(result SearchResult) {
+	for _, provider := range r.providers {
+		if lr, ok := provider.(LaunchRecorder); ok {
+			lr.RecordLaunch(result)
+		}
+	}
+}
+
 // GetProviders returns all registered providers
 func (r *Registry) GetProviders() []Provider {
 	return r.providers
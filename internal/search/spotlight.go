@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/MordFustang21/marvin-go/internal/util/opener"
 )
 
 // SpotlightResult represents a single result from the Spotlight search
@@ -115,8 +117,7 @@ func GetFileMetadata(path string) (map[string]interface{}, error) {
 	return metadata, nil
 }
 
-// OpenFile opens a file or application using the 'open' command
+// OpenFile opens a file or application with the platform's default handler
 func OpenFile(path string) error {
-	cmd := exec.Command("open", path)
-	return cmd.Run()
+	return opener.Open(path)
 }
@@ -0,0 +1,308 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// languageSyntax describes the tokens to highlight for a language
+type languageSyntax struct {
+	keywords     []string
+	lineComments []string
+	blockComment [2]string // Start and end markers, empty if the language has none
+	quotes       string    // Characters that start a string
+}
+
+var (
+	cStyleComments = [2]string{"/*", "*/"}
+
+	syntaxes = map[string]languageSyntax{
+		"go": {
+			keywords: []string{"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+				"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+				"struct", "switch", "type", "var", "nil", "true", "false", "iota"},
+			lineComments: []string{"//"},
+			blockComment: cStyleComments,
+			quotes:       "\"'`",
+		},
+		"javascript": {
+			keywords: []string{"async", "await", "break", "case", "catch", "class", "const", "continue", "default",
+				"delete", "do", "else", "export", "extends", "false", "finally", "for", "from", "function", "if", "import",
+				"in", "instanceof", "let", "new", "null", "of", "return", "super", "switch", "this", "throw", "true", "try",
+				"typeof", "undefined", "var", "void", "while", "yield"},
+			lineComments: []string{"//"},
+			blockComment: cStyleComments,
+			quotes:       "\"'`",
+		},
+		"python": {
+			keywords: []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del",
+				"elif", "else", "except", "False", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda",
+				"None", "nonlocal", "not", "or", "pass", "raise", "return", "True", "try", "while", "with", "yield"},
+			lineComments: []string{"#"},
+			quotes:       "\"'",
+		},
+		"ruby": {
+			keywords: []string{"begin", "class", "def", "do", "else", "elsif", "end", "ensure", "false", "if", "in",
+				"module", "next", "nil", "not", "or", "and", "rescue", "return", "self", "then", "true", "unless", "until",
+				"when", "while", "yield", "require"},
+			lineComments: []string{"#"},
+			quotes:       "\"'",
+		},
+		"rust": {
+			keywords: []string{"as", "async", "await", "break", "const", "continue", "crate", "else", "enum", "extern",
+				"false", "fn", "for", "if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub", "ref",
+				"return", "self", "Self", "static", "struct", "super", "trait", "true", "type", "unsafe", "use", "where",
+				"while"},
+			lineComments: []string{"//"},
+			blockComment: cStyleComments,
+			quotes:       "\"",
+		},
+		"c": {
+			keywords: []string{"auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else",
+				"enum", "extern", "float", "for", "goto", "if", "int", "long", "return", "short", "signed", "sizeof",
+				"static", "struct", "switch", "typedef", "union", "unsigned", "void", "volatile", "while", "NULL",
+				"#include", "#define", "#import", "class", "namespace", "template", "public", "private", "protected",
+				"virtual", "new", "delete", "true", "false", "nullptr", "self", "nil", "YES", "NO"},
+			lineComments: []string{"//"},
+			blockComment: cStyleComments,
+			quotes:       "\"'",
+		},
+		"java": {
+			keywords: []string{"abstract", "break", "case", "catch", "class", "const", "continue", "default", "do",
+				"else", "enum", "extends", "false", "final", "finally", "for", "fun", "func", "guard", "if", "implements",
+				"import", "in", "interface", "let", "new", "null", "nil", "object", "override", "package", "private",
+				"protected", "public", "return", "static", "struct", "super", "switch", "this", "self", "throw", "throws",
+				"true", "try", "val", "var", "void", "when", "while"},
+			lineComments: []string{"//"},
+			blockComment: cStyleComments,
+			quotes:       "\"'",
+		},
+		"shell": {
+			keywords: []string{"if", "then", "else", "elif", "fi", "for", "while", "until", "do", "done", "case",
+				"esac", "in", "function", "return", "export", "local", "echo", "exit", "set", "unset", "source"},
+			lineComments: []string{"#"},
+			quotes:       "\"'",
+		},
+		"sql": {
+			keywords: []string{"select", "from", "where", "insert", "into", "values", "update", "set", "delete",
+				"create", "table", "drop", "alter", "join", "left", "right", "inner", "outer", "on", "group", "by", "order",
+				"having", "limit", "and", "or", "not", "null", "as", "distinct", "union", "index", "primary", "key"},
+			lineComments: []string{"--"},
+			blockComment: cStyleComments,
+			quotes:       "'\"",
+		},
+		"json": {
+			keywords: []string{"true", "false", "null"},
+			quotes:   "\"",
+		},
+		"yaml": {
+			keywords:     []string{"true", "false", "null", "yes", "no"},
+			lineComments: []string{"#"},
+			quotes:       "\"'",
+		},
+		"css": {
+			keywords:     []string{"important", "inherit", "initial", "none", "auto"},
+			blockComment: cStyleComments,
+			quotes:       "\"'",
+		},
+		"html": {
+			blockComment: [2]string{"<!--", "-->"},
+			quotes:       "\"'",
+		},
+	}
+
+	// languageAliases maps languages to one with compatible syntax
+	languageAliases = map[string]string{
+		"typescript": "javascript",
+		"cpp":        "c",
+		"objc":       "c",
+		"kotlin":     "java",
+		"swift":      "java",
+		"toml":       "yaml",
+		"xml":        "html",
+	}
+)
+
+// tokenKind is the kind of a highlighted token
+type tokenKind int
+
+const (
+	tokenPlain tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenComment
+	tokenNumber
+)
+
+// tokenColors maps token kinds to theme colors
+var tokenColors = map[tokenKind]fyne.ThemeColorName{
+	tokenPlain:   theme.ColorNameForeground,
+	tokenKeyword: theme.ColorNamePrimary,
+	tokenString:  theme.ColorNameSuccess,
+	tokenComment: theme.ColorNamePlaceHolder,
+	tokenNumber:  theme.ColorNameWarning,
+}
+
+// highlightSegments splits source text into monospaced rich text segments
+// colored by token kind. Unknown languages are returned as plain text.
+func highlightSegments(text, language string) []widget.RichTextSegment {
+	if alias, ok := languageAliases[language]; ok {
+		language = alias
+	}
+
+	syntax, ok := syntaxes[language]
+	if !ok {
+		return []widget.RichTextSegment{newCodeSegment(text, tokenPlain)}
+	}
+
+	keywords := make(map[string]bool, len(syntax.keywords))
+	for _, keyword := range syntax.keywords {
+		keywords[keyword] = true
+		if language == "sql" {
+			keywords[strings.ToUpper(keyword)] = true
+		}
+	}
+
+	segments := []widget.RichTextSegment{}
+	var current strings.Builder
+	currentKind := tokenPlain
+
+	emit := func(token string, kind tokenKind) {
+		if kind != currentKind && current.Len() > 0 {
+			segments = append(segments, newCodeSegment(current.String(), currentKind))
+			current.Reset()
+		}
+		currentKind = kind
+		current.WriteString(token)
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:min(i+8, len(runes))])
+		r := runes[i]
+
+		if marker := matchPrefix(rest, syntax.lineComments); marker != "" {
+			end := indexRune(runes, '\n', i)
+			emit(string(runes[i:end]), tokenComment)
+			i = end
+			continue
+		}
+
+		if start := syntax.blockComment[0]; start != "" && strings.HasPrefix(rest, start) {
+			end := indexString(runes, syntax.blockComment[1], i+len([]rune(start)))
+			emit(string(runes[i:end]), tokenComment)
+			i = end
+			continue
+		}
+
+		if strings.ContainsRune(syntax.quotes, r) {
+			end := stringEnd(runes, i)
+			emit(string(runes[i:end]), tokenString)
+			i = end
+			continue
+		}
+
+		if unicode.IsDigit(r) {
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || unicode.IsLetter(runes[end]) || runes[end] == '.' || runes[end] == '_') {
+				end++
+			}
+			emit(string(runes[i:end]), tokenNumber)
+			i = end
+			continue
+		}
+
+		if unicode.IsLetter(r) || r == '_' || r == '#' {
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+
+			word := string(runes[i:end])
+			if keywords[word] {
+				emit(word, tokenKeyword)
+			} else {
+				emit(word, tokenPlain)
+			}
+			i = end
+			continue
+		}
+
+		emit(string(r), tokenPlain)
+		i++
+	}
+
+	if current.Len() > 0 {
+		segments = append(segments, newCodeSegment(current.String(), currentKind))
+	}
+
+	return segments
+}
+
+// newCodeSegment creates a monospaced text segment colored for the token kind
+func newCodeSegment(text string, kind tokenKind) *widget.TextSegment {
+	return &widget.TextSegment{
+		Text: text,
+		Style: widget.RichTextStyle{
+			ColorName: tokenColors[kind],
+			Inline:    true,
+			TextStyle: fyne.TextStyle{Monospace: true},
+		},
+	}
+}
+
+// matchPrefix returns the first of the prefixes that s starts with, or ""
+func matchPrefix(s string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// indexRune returns the index of the next r at or after start, or len(runes)
+func indexRune(runes []rune, r rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// indexString returns the index just past the next occurrence of s at or after start, or len(runes)
+func indexString(runes []rune, s string, start int) int {
+	if start >= len(runes) {
+		return len(runes)
+	}
+
+	idx := strings.Index(string(runes[start:]), s)
+	if idx < 0 {
+		return len(runes)
+	}
+
+	return start + len([]rune(string(runes[start:])[:idx])) + len([]rune(s))
+}
+
+// stringEnd returns the index just past the string literal starting at start.
+// Strings end at the matching unescaped quote, or the end of the line for
+// quotes other than backticks.
+func stringEnd(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && quote != '`':
+			i++
+		case runes[i] == quote:
+			return i + 1
+		case runes[i] == '\n' && quote != '`':
+			return i
+		}
+	}
+	return len(runes)
+}
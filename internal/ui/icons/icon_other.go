@@ -0,0 +1,19 @@
+//go:build !darwin
+
+package icons
+
+import (
+	"errors"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+var (
+	iconCache = sync.Map{}
+)
+
+// getIconUsingCocoa is only available on macOS, where .app bundles are found
+func getIconUsingCocoa(path string) (fyne.Resource, error) {
+	return nil, errors.New("cocoa icons are only available on macOS")
+}
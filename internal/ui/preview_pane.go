@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/MordFustang21/marvin-go/internal/search"
+)
+
+const (
+	previewWidth       = 420
+	previewImageHeight = 260
+	previewMaxLines    = 200 // Longer text is cut off to keep rendering fast
+)
+
+// PreviewPane shows a Quick Look style preview of the selected search result
+type PreviewPane struct {
+	widget.BaseWidget
+	content *fyne.Container
+	scroll  *container.Scroll
+	// generation is incremented for each preview request so stale previews
+	// that finish loading late are dropped
+	generation int
+}
+
+// NewPreviewPane creates a new, empty preview pane
+func NewPreviewPane() *PreviewPane {
+	content := container.NewVBox()
+	pane := &PreviewPane{
+		content: content,
+		scroll:  container.NewVScroll(content),
+	}
+	pane.ExtendBaseWidget(pane)
+	return pane
+}
+
+// CreateRenderer creates a renderer for the preview pane
+func (pp *PreviewPane) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(pp.scroll)
+}
+
+// MinSize keeps the pane at a fixed width beside the results
+func (pp *PreviewPane) MinSize() fyne.Size {
+	return fyne.NewSize(previewWidth, 0)
+}
+
+// ShowResult loads and displays the preview for a result.
+// Must be called on the main Fyne goroutine.
+func (pp *PreviewPane) ShowResult(result search.SearchResult) {
+	pp.generation++
+	generation := pp.generation
+
+	if result.Preview == nil {
+		pp.setContent(pp.basicPreview(result))
+		return
+	}
+
+	pp.setContent(widget.NewLabel("Loading preview…"))
+
+	// Building a preview may read files or run commands, so keep it off the UI goroutine
+	go func() {
+		preview, err := result.Preview()
+		if err != nil {
+			slog.Debug("Failed to build preview", slog.String("path", result.Path), slog.Any("error", err))
+		}
+
+		fyne.Do(func() {
+			if generation != pp.generation {
+				return
+			}
+
+			if preview == nil {
+				pp.setContent(pp.basicPreview(result))
+				return
+			}
+
+			pp.setContent(pp.renderPreview(preview))
+		})
+	}()
+}
+
+// Clear removes any preview content
+func (pp *PreviewPane) Clear() {
+	pp.generation++
+	pp.setContent()
+}
+
+// setContent replaces the pane's content and scrolls back to the top
+func (pp *PreviewPane) setContent(objects ...fyne.CanvasObject) {
+	pp.content.Objects = objects
+	pp.content.Refresh()
+	pp.scroll.ScrollToTop()
+}
+
+// basicPreview shows the result's own title and description when it has no preview
+func (pp *PreviewPane) basicPreview(result search.SearchResult) fyne.CanvasObject {
+	return pp.renderPreview(&search.Preview{
+		Title: result.Title,
+		Text:  result.Description,
+	})
+}
+
+// renderPreview creates the widgets for a preview
+func (pp *PreviewPane) renderPreview(preview *search.Preview) fyne.CanvasObject {
+	objects := []fyne.CanvasObject{}
+
+	if preview.Title != "" {
+		title := widget.NewLabel(preview.Title)
+		title.TextStyle = fyne.TextStyle{Bold: true}
+		title.Wrapping = fyne.TextWrapBreak
+		objects = append(objects, title)
+	}
+
+	if preview.Image != nil {
+		image := canvas.NewImageFromResource(preview.Image)
+		image.FillMode = canvas.ImageFillContain
+		image.SetMinSize(fyne.NewSize(previewWidth-2*theme.Padding(), previewImageHeight))
+		objects = append(objects, image)
+	}
+
+	switch {
+	case preview.Markdown != "":
+		markdown := widget.NewRichTextFromMarkdown(truncateLines(preview.Markdown, previewMaxLines))
+		markdown.Wrapping = fyne.TextWrapWord
+		objects = append(objects, markdown)
+	case preview.Text != "":
+		text := widget.NewRichText(highlightSegments(truncateLines(preview.Text, previewMaxLines), preview.Language)...)
+		text.Wrapping = fyne.TextWrapBreak
+		objects = append(objects, text)
+	}
+
+	if len(preview.Metadata) > 0 {
+		objects = append(objects, widget.NewSeparator())
+
+		form := container.New(layout.NewFormLayout())
+		for _, field := range preview.Metadata {
+			label := widget.NewLabel(field.Label)
+			label.TextStyle = fyne.TextStyle{Bold: true}
+
+			value := widget.NewLabel(field.Value)
+			value.Wrapping = fyne.TextWrapBreak
+
+			form.Add(label)
+			form.Add(value)
+		}
+		objects = append(objects, form)
+	}
+
+	return container.NewVBox(objects...)
+}
+
+// truncateLines cuts text down to at most n lines
+func truncateLines(text string, n int) string {
+	lines := strings.SplitN(text, "\n", n+1)
+	if len(lines) <= n {
+		return text
+	}
+
+	return strings.Join(lines[:n], "\n") + "\n…"
+}
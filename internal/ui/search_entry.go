@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -10,6 +11,13 @@ import (
 type SearchEntry struct {
 	widget.Entry
 	OnSpecialKey func(key *fyne.KeyEvent)
+	// OnBackspace is called before a backspace is applied and returns true if it
+	// handled the key, e.g. by navigating up a level
+	OnBackspace func() bool
+	// OnShiftSpace is called instead of typing a space while Shift is held
+	OnShiftSpace func()
+	// shiftDown tracks whether a Shift key is currently held
+	shiftDown bool
 }
 
 // NewSearchEntry creates a new SearchEntry widget.
@@ -22,15 +30,62 @@ func NewSearchEntry() *SearchEntry {
 // TypedKey intercepts key events and calls OnSpecialKey for navigation/escape/return keys.
 func (e *SearchEntry) TypedKey(key *fyne.KeyEvent) {
 	switch key.Name {
-	case fyne.KeyEscape, fyne.KeyDown, fyne.KeyUp, fyne.KeyReturn:
+	case fyne.KeyEscape, fyne.KeyDown, fyne.KeyUp, fyne.KeyReturn, fyne.KeyTab:
 		if e.OnSpecialKey != nil {
 			e.OnSpecialKey(key)
 			return // Don't pass to default handler
 		}
+	case fyne.KeyBackspace:
+		if e.OnBackspace != nil && e.OnBackspace() {
+			return
+		}
 	}
 	e.Entry.TypedKey(key) // Default behavior for other keys
 }
 
+// KeyDown tracks the Shift key so Shift+Space can be told apart from a space.
+func (e *SearchEntry) KeyDown(key *fyne.KeyEvent) {
+	if key.Name == desktop.KeyShiftLeft || key.Name == desktop.KeyShiftRight {
+		e.shiftDown = true
+	}
+	e.Entry.KeyDown(key)
+}
+
+// KeyUp tracks the Shift key so Shift+Space can be told apart from a space.
+func (e *SearchEntry) KeyUp(key *fyne.KeyEvent) {
+	if key.Name == desktop.KeyShiftLeft || key.Name == desktop.KeyShiftRight {
+		e.shiftDown = false
+	}
+	e.Entry.KeyUp(key)
+}
+
+// TypedRune intercepts Shift+Space and calls OnShiftSpace instead of typing it.
+func (e *SearchEntry) TypedRune(r rune) {
+	if r == ' ' && e.shiftDown && e.OnShiftSpace != nil {
+		e.OnShiftSpace()
+		return
+	}
+	e.Entry.TypedRune(r)
+}
+
+// AcceptsTab lets Tab reach TypedKey so it can complete the selected result
+// instead of moving focus.
+func (e *SearchEntry) AcceptsTab() bool {
+	return true
+}
+
+// SetTextAndMoveCursor replaces the text and moves the cursor to the end of it.
+func (e *SearchEntry) SetTextAndMoveCursor(text string) {
+	e.SetText(text)
+	e.CursorColumn = len([]rune(text))
+	e.Refresh()
+}
+
+// cursorAtEnd reports whether the cursor is at the end of the text with nothing selected.
+func (e *SearchEntry) cursorAtEnd() bool {
+	return e.SelectedText() == "" && e.CursorRow == 0 && e.CursorColumn == len([]rune(e.Text))
+}
+
 func (e *SearchEntry) SelectAll() {
 	// This is to programatically select all text in the entry.
 	e.TypedShortcut(&fyne.ShortcutSelectAll{})
@@ -47,6 +47,8 @@ type SearchWindow struct {
 	searchTimeout    time.Duration
 	// Track results by provider priority for proper ordering
 	resultsByPriority map[int][]int
+	// previewPane shows the selected result beside the list when toggled on
+	previewPane *PreviewPane
 }
 
 // NewSearchWindow creates a new search window
@@ -79,6 +81,10 @@ func NewSearchWindow(app fyne.App, registry *search.Registry) *SearchWindow {
 	resultsList := container.NewVBox()
 	resultsScroll := container.NewScroll(resultsList)
 
+	// The preview pane starts hidden and is toggled with Shift+Space
+	previewPane := NewPreviewPane()
+	previewPane.Hide()
+
 	// Create initial no-op context
 	ctx, cancel := context.WithCancel(context.Background())
 	
@@ -94,6 +100,7 @@ func NewSearchWindow(app fyne.App, registry *search.Registry) *SearchWindow {
 		cancelCurrentCtx: cancel,
 		searchTimeout:    500 * time.Millisecond, // Default timeout for considering search complete
 		resultsByPriority: make(map[int][]int),   // Track results by provider priority
+		previewPane:      previewPane,
 	}
 
 	// Create trigger for search on input submission.
@@ -118,9 +125,31 @@ func NewSearchWindow(app fyne.App, registry *search.Registry) *SearchWindow {
 			searchWindow.selectPreviousResult()
 		case fyne.KeyReturn:
 			searchWindow.launchSelectedResult()
+		case fyne.KeyTab:
+			searchWindow.completeSelectedResult()
 		}
 	}
 
+	// Toggle the preview pane for the selected result
+	searchInput.OnShiftSpace = func() {
+		searchWindow.togglePreview()
+	}
+
+	// Let providers with hierarchical queries step back up a level, e.g. to a parent directory
+	searchInput.OnBackspace = func() bool {
+		if !searchInput.cursorAtEnd() {
+			return false
+		}
+
+		parent, ok := registry.ParentQuery(searchInput.Text)
+		if !ok {
+			return false
+		}
+
+		searchInput.SetTextAndMoveCursor(parent)
+		return true
+	}
+
 	// Set up the search delay timer
 	searchWindow.timer = time.NewTimer(searchDelay)
 	go func() {
@@ -148,7 +177,7 @@ func NewSearchWindow(app fyne.App, registry *search.Registry) *SearchWindow {
 
 	mainContainer := container.NewBorder(
 		searchBox,
-		nil, nil, nil,
+		nil, nil, previewPane,
 		resultsScroll,
 	)
 
@@ -185,6 +214,10 @@ func (sw *SearchWindow) selectResult(index int) {
 	sw.resultItems[index].IsSelected = true
 	sw.resultItems[index].Refresh()
 
+	if sw.previewPane.Visible() {
+		sw.previewPane.ShowResult(sw.resultItems[index].searchResult)
+	}
+
 	// Refresh the results list container to ensure UI updates
 	fyne.Do(func() {
 		sw.resultsList.Refresh()
@@ -228,6 +261,37 @@ func (sw *SearchWindow) launchSelectedResult() {
 	}
 }
 
+// completeSelectedResult replaces the search text with the selected result's completion, if it has one
+func (sw *SearchWindow) completeSelectedResult() {
+	if sw.selectedIndex < 0 || sw.selectedIndex >= len(sw.resultItems) {
+		return
+	}
+
+	completion := sw.resultItems[sw.selectedIndex].searchResult.Completion
+	if completion == "" {
+		return
+	}
+
+	sw.searchInput.SetTextAndMoveCursor(completion)
+}
+
+// togglePreview shows or hides the preview pane, widening the window to make room for it
+func (sw *SearchWindow) togglePreview() {
+	if sw.previewPane.Visible() {
+		sw.previewPane.Hide()
+		sw.previewPane.Clear()
+		sw.window.Resize(fyne.NewSize(defaultWidth, defaultHeight))
+		return
+	}
+
+	sw.previewPane.Show()
+	sw.window.Resize(fyne.NewSize(defaultWidth+previewWidth, defaultHeight))
+
+	if sw.selectedIndex >= 0 && sw.selectedIndex < len(sw.resultItems) {
+		sw.previewPane.ShowResult(sw.resultItems[sw.selectedIndex].searchResult)
+	}
+}
+
 // Close closes the search window and cleans up resources
 func (sw *SearchWindow) Close() {
 	// Cancel any ongoing searches
@@ -273,12 +337,9 @@ func (sw *SearchWindow) performSearch(query string) {
 	// Clear the UI right away
 	fyne.Do(func() {
 		sw.resultsList.RemoveAll()
+		sw.previewPane.Clear()
 	})
 
-	if query == "" {
-		return
-	}
-
 	resultsCh := make(chan []search.SearchResult)
 	errCh := make(chan error)
 	doneCh := make(chan struct{})
@@ -340,6 +401,7 @@ func (sw *SearchWindow) performSearch(query string) {
 								if originalAction != nil {
 									originalAction()
 								}
+								sw.registry.RecordLaunch(result)
 								sw.Hide() // Hide the window after selection
 							}
 
@@ -398,8 +460,9 @@ func (sw *SearchWindow) performSearch(query string) {
 				
 			case <-doneCh:
 				fyne.Do(func() {
-					// If no results were shown, show "No results found"
-					if !anyResults {
+					// If no results were shown, show "No results found".
+					// An empty query without default results just leaves the list blank.
+					if !anyResults && query != "" {
 						sw.resultsList.RemoveAll()
 						sw.resultsList.Add(widget.NewLabel("No results found"))
 						sw.resultsList.Refresh()
@@ -438,6 +501,12 @@ func (sw *SearchWindow) ShowWithKeyboardFocus() {
 	sw.show = true
 	sw.Show()
 
+	// Refresh the default results, such as recent items, for an empty search,
+	// and values such as UUIDs that should be fresh each time
+	if sw.searchInput.Text == "" || sw.registry.Volatile(sw.searchInput.Text) {
+		sw.timer.Reset(searchDelay)
+	}
+
 	// First focus the input field
 	sw.window.Canvas().Focus(sw.searchInput)
 	sw.searchInput.SelectAll()
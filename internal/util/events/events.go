@@ -1 +1,62 @@
+// Package events registers global hotkeys and monitors keyboard events.
+// Only macOS is supported; elsewhere hotkeys are not registered.
 package events
+
+import "sync"
+
+// Key codes for common keys (macOS virtual key codes)
+const (
+	KeySpace   = 49
+	KeyReturn  = 36
+	KeyEscape  = 53
+	KeyCommand = 0x37
+	KeyOption  = 0x3A
+	KeyControl = 0x3B
+	KeyShift   = 0x38
+	KeyTab     = 48
+	KeyDelete  = 51
+	KeyLeft    = 123
+	KeyRight   = 124
+	KeyUp      = 126
+	KeyDown    = 125
+)
+
+// Modifier keys
+const (
+	ModCommand = 1 << 8  // Command key
+	ModShift   = 1 << 9  // Shift key
+	ModOption  = 1 << 11 // Option key
+	ModControl = 1 << 12 // Control key
+)
+
+// Singleton event handler
+var (
+	handler     *EventHandler
+	handlerOnce sync.Once
+)
+
+// KeyCallback represents a function that will be called when a key combination is pressed
+type KeyCallback func()
+
+// EventHandler manages macOS keyboard events
+type EventHandler struct {
+	isMonitoring    bool
+	registeredKeys  map[string]KeyCallback
+	globalHotkeySet bool
+	mu              sync.RWMutex
+}
+
+// GetEventHandler returns the singleton EventHandler instance
+func GetEventHandler() *EventHandler {
+	handlerOnce.Do(func() {
+		handler = &EventHandler{
+			registeredKeys: make(map[string]KeyCallback),
+		}
+	})
+	return handler
+}
+
+// Helper function to create a string key for the key combination
+func keyComboString(keyCode int, modifiers int) string {
+	return string(rune(keyCode)) + "-" + string(rune(modifiers))
+}
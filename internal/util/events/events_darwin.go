@@ -16,58 +16,6 @@ import (
 	"sync"
 )
 
-// Key codes for common keys (macOS virtual key codes)
-const (
-	KeySpace   = 49
-	KeyReturn  = 36
-	KeyEscape  = 53
-	KeyCommand = 0x37
-	KeyOption  = 0x3A
-	KeyControl = 0x3B
-	KeyShift   = 0x38
-	KeyTab     = 48
-	KeyDelete  = 51
-	KeyLeft    = 123
-	KeyRight   = 124
-	KeyUp      = 126
-	KeyDown    = 125
-)
-
-// Modifier keys
-const (
-	ModCommand = 1 << 8  // Command key
-	ModShift   = 1 << 9  // Shift key
-	ModOption  = 1 << 11 // Option key
-	ModControl = 1 << 12 // Control key
-)
-
-// Singleton event handler
-var (
-	handler     *EventHandler
-	handlerOnce sync.Once
-)
-
-// KeyCallback represents a function that will be called when a key combination is pressed
-type KeyCallback func()
-
-// EventHandler manages macOS keyboard events
-type EventHandler struct {
-	isMonitoring    bool
-	registeredKeys  map[string]KeyCallback
-	globalHotkeySet bool
-	mu              sync.RWMutex
-}
-
-// GetEventHandler returns the singleton EventHandler instance
-func GetEventHandler() *EventHandler {
-	handlerOnce.Do(func() {
-		handler = &EventHandler{
-			registeredKeys: make(map[string]KeyCallback),
-		}
-	})
-	return handler
-}
-
 // RegisterGlobalHotkey registers a system-wide hotkey
 func (h *EventHandler) RegisterGlobalHotkey(keyCode int, modifiers int, callback KeyCallback) bool {
 	h.mu.Lock()
@@ -183,11 +131,6 @@ func (h *EventHandler) IsKeyPressed(keyCode int) bool {
 	return bool(C.isKeyPressed(C.int(keyCode)))
 }
 
-// Helper function to create a string key for the key combination
-func keyComboString(keyCode int, modifiers int) string {
-	return string(rune(keyCode)) + "-" + string(rune(modifiers))
-}
-
 // Store the global hotkey callback
 var (
 	globalHotkeyCallback KeyCallback
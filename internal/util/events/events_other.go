@@ -0,0 +1,58 @@
+//go:build !darwin
+
+package events
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// RegisterGlobalHotkey registers a system-wide hotkey. Global hotkeys are only
+// supported on macOS, so it always fails here.
+func (h *EventHandler) RegisterGlobalHotkey(keyCode int, modifiers int, callback KeyCallback) bool {
+	slog.Warn("Global hotkeys are not supported", slog.String("os", runtime.GOOS))
+	return false
+}
+
+// UnregisterGlobalHotkey removes the global hotkey
+func (h *EventHandler) UnregisterGlobalHotkey() bool {
+	return true
+}
+
+// StartMonitoring begins monitoring keyboard events
+func (h *EventHandler) StartMonitoring() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.isMonitoring = true
+}
+
+// StopMonitoring stops monitoring keyboard events
+func (h *EventHandler) StopMonitoring() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.isMonitoring = false
+}
+
+// RegisterKeyCombo registers a callback for a specific key combination.
+// Key events aren't monitored here, so it is never called.
+func (h *EventHandler) RegisterKeyCombo(keyCode int, modifiers int, callback KeyCallback) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.registeredKeys[keyComboString(keyCode, modifiers)] = callback
+}
+
+// UnregisterKeyCombo removes a callback for a specific key combination
+func (h *EventHandler) UnregisterKeyCombo(keyCode int, modifiers int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.registeredKeys, keyComboString(keyCode, modifiers))
+}
+
+// IsKeyPressed checks if a key is currently pressed. It is always false here.
+func (h *EventHandler) IsKeyPressed(keyCode int) bool {
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package macos
+
+// HideDockIcon hides the application from the dock. There is no dock outside macOS.
+func HideDockIcon() {}
+
+// ShowDockIcon shows the application in the dock. There is no dock outside macOS.
+func ShowDockIcon() {}
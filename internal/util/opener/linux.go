@@ -0,0 +1,93 @@
+package opener
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Linux opens targets with xdg-open, or gio when xdg-open is not installed.
+// Revealing a file asks the file manager over D-Bus to select it.
+type Linux struct{}
+
+// Open opens the target with the desktop's default handler or the given app
+func (l Linux) Open(target string, opts Options) error {
+	if opts.Reveal {
+		return l.reveal(target)
+	}
+
+	// There's no portable way to keep the app in the background, so Background
+	// only means Marvin doesn't wait for it. xdg-open may run the handler
+	// itself and not return until it exits.
+	return start(openCommand(target, opts))
+}
+
+// openCommand returns the command that opens the target with the app in the options
+func openCommand(target string, opts Options) []string {
+	switch {
+	case strings.HasSuffix(opts.App, ".desktop"):
+		return []string{"gtk-launch", strings.TrimSuffix(opts.App, ".desktop"), target}
+	case opts.App != "":
+		return []string{opts.App, target}
+	default:
+		return defaultOpenCommand(target)
+	}
+}
+
+// FileManagerName returns a generic name as there's no standard file manager
+func (Linux) FileManagerName() string {
+	return "File Manager"
+}
+
+// reveal selects the target in the file manager through the
+// org.freedesktop.FileManager1 interface, falling back to opening its folder
+func (l Linux) reveal(target string) error {
+	path, err := filepath.Abs(target)
+	if err != nil {
+		return fmt.Errorf("failed to reveal %s: %w", target, err)
+	}
+
+	args := revealCommand(path)
+	if err := exec.Command(args[0], args[1:]...).Run(); err == nil {
+		return nil
+	}
+
+	return start(defaultOpenCommand(filepath.Dir(path)))
+}
+
+// revealCommand returns the gdbus command that asks the file manager to
+// select the file at the absolute path
+func revealCommand(path string) []string {
+	uri := (&url.URL{Scheme: "file", Path: path}).String()
+	return []string{"gdbus", "call", "--session",
+		"--dest", "org.freedesktop.FileManager1",
+		"--object-path", "/org/freedesktop/FileManager1",
+		"--method", "org.freedesktop.FileManager1.ShowItems",
+		fmt.Sprintf("['%s']", uri), ""}
+}
+
+// lookPath finds installed programs, replaceable in tests
+var lookPath = exec.LookPath
+
+// defaultOpenCommand returns the command that opens the target with its default handler
+func defaultOpenCommand(target string) []string {
+	if _, err := lookPath("xdg-open"); err == nil {
+		return []string{"xdg-open", target}
+	}
+	return []string{"gio", "open", target}
+}
+
+// start runs the command without waiting for it to exit
+func start(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	// Reap the process when it exits so it doesn't linger as a zombie
+	go cmd.Wait()
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package opener
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// MacOS opens targets with the macOS open command
+type MacOS struct{}
+
+// Open opens the target using 'open', passing -a, -R and -g for the options
+func (m MacOS) Open(target string, opts Options) error {
+	if out, err := exec.Command("open", m.args(target, opts)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open %s: %w: %s", target, err, out)
+	}
+
+	return nil
+}
+
+// args returns the arguments to pass to open for the target and options
+func (MacOS) args(target string, opts Options) []string {
+	args := []string{}
+	if opts.Background {
+		args = append(args, "-g")
+	}
+	if opts.Reveal {
+		args = append(args, "-R")
+	} else if opts.App != "" {
+		args = append(args, "-a", opts.App)
+	}
+	return append(args, target)
+}
+
+// FileManagerName returns Finder
+func (MacOS) FileManagerName() string {
+	return "Finder"
+}
@@ -0,0 +1,117 @@
+// Package opener opens files, applications and URLs with the platform's
+// default handler, such as open on macOS or xdg-open on Linux.
+package opener
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Options control how a target is opened
+type Options struct {
+	// App opens the target with this application instead of the default one.
+	// On macOS this is an application name or path, on Linux a desktop file
+	// ID such as org.gnome.TextEditor.desktop or a program name.
+	App string
+	// Reveal selects the target in the file manager instead of opening it
+	Reveal bool
+	// Background opens the target without bringing the application to the front
+	Background bool
+}
+
+// Option configures Options
+type Option func(*Options)
+
+// WithApp opens the target with the given application
+func WithApp(app string) Option {
+	return func(o *Options) {
+		o.App = app
+	}
+}
+
+// Reveal selects the target in the file manager instead of opening it
+func Reveal() Option {
+	return func(o *Options) {
+		o.Reveal = true
+	}
+}
+
+// InBackground opens the target without bringing the application to the front
+func InBackground() Option {
+	return func(o *Options) {
+		o.Background = true
+	}
+}
+
+// Backend opens targets for a platform
+type Backend interface {
+	// Open opens a file, application or URL
+	Open(target string, opts Options) error
+	// FileManagerName returns the name of the file manager, e.g. Finder
+	FileManagerName() string
+}
+
+var (
+	mu      sync.RWMutex
+	backend = defaultBackend()
+)
+
+// defaultBackend returns the backend for the current platform
+func defaultBackend() Backend {
+	switch runtime.GOOS {
+	case "darwin":
+		return MacOS{}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return Linux{}
+	default:
+		return unsupported{}
+	}
+}
+
+// SetBackend replaces the backend used by Open, e.g. with a Recorder in tests.
+// It returns the previous backend so it can be restored.
+func SetBackend(b Backend) Backend {
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous := backend
+	backend = b
+	return previous
+}
+
+// current returns the backend in use
+func current() Backend {
+	mu.RLock()
+	defer mu.RUnlock()
+	return backend
+}
+
+// Open opens a file, application or URL with the current backend
+func Open(target string, opts ...Option) error {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return current().Open(target, options)
+}
+
+// FileManagerName returns the name of the platform's file manager, for
+// labels such as "Reveal in Finder"
+func FileManagerName() string {
+	return current().FileManagerName()
+}
+
+// unsupported is the backend for platforms without an opener
+type unsupported struct{}
+
+// Open always fails
+func (unsupported) Open(target string, opts Options) error {
+	return fmt.Errorf("opening files is not supported on %s", runtime.GOOS)
+}
+
+// FileManagerName returns a generic name
+func (unsupported) FileManagerName() string {
+	return "File Manager"
+}
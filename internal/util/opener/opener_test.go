@@ -0,0 +1,122 @@
+package opener
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOpenOptions(t *testing.T) {
+	recorder := &Recorder{}
+	previous := SetBackend(recorder)
+	defer SetBackend(previous)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want Options
+	}{
+		{"default", nil, Options{}},
+		{"with app", []Option{WithApp("TextEdit")}, Options{App: "TextEdit"}},
+		{"reveal", []Option{Reveal()}, Options{Reveal: true}},
+		{"background", []Option{InBackground(), WithApp("Mail")}, Options{App: "Mail", Background: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Reset()
+			if err := Open("/tmp/file.txt", tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []Call{{Target: "/tmp/file.txt", Options: tt.want}}
+			if got := recorder.Calls(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Open() calls = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetBackend(t *testing.T) {
+	failing := &Recorder{Err: errors.New("no handler")}
+	previous := SetBackend(failing)
+	defer SetBackend(previous)
+
+	if err := Open("https://example.com"); !errors.Is(err, failing.Err) {
+		t.Errorf("Open() error = %v, want %v", err, failing.Err)
+	}
+	if got := FileManagerName(); got != "File Manager" {
+		t.Errorf("FileManagerName() = %q, want the recorder's", got)
+	}
+
+	if restored := SetBackend(previous); restored != failing {
+		t.Errorf("SetBackend() returned %v, want the recorder", restored)
+	}
+}
+
+func TestMacOSArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"default", Options{}, []string{"/Applications/Safari.app"}},
+		{"with app", Options{App: "TextEdit"}, []string{"-a", "TextEdit", "/Applications/Safari.app"}},
+		{"reveal", Options{Reveal: true}, []string{"-R", "/Applications/Safari.app"}},
+		{"reveal ignores app", Options{Reveal: true, App: "TextEdit"}, []string{"-R", "/Applications/Safari.app"}},
+		{"background", Options{Background: true, App: "Mail"}, []string{"-g", "-a", "Mail", "/Applications/Safari.app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (MacOS{}).args("/Applications/Safari.app", tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinuxOpenCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		xdgOpen bool
+		want    []string
+	}{
+		{"default", Options{}, true, []string{"xdg-open", "/tmp/file.txt"}},
+		{"without xdg-open", Options{}, false, []string{"gio", "open", "/tmp/file.txt"}},
+		{"desktop file", Options{App: "org.gnome.TextEditor.desktop"}, true, []string{"gtk-launch", "org.gnome.TextEditor", "/tmp/file.txt"}},
+		{"program", Options{App: "gedit"}, true, []string{"gedit", "/tmp/file.txt"}},
+		{"background", Options{Background: true}, true, []string{"xdg-open", "/tmp/file.txt"}},
+	}
+
+	previous := lookPath
+	defer func() { lookPath = previous }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath = func(file string) (string, error) {
+				if tt.xdgOpen && file == "xdg-open" {
+					return "/usr/bin/xdg-open", nil
+				}
+				return "", errors.New("not found")
+			}
+
+			if got := openCommand("/tmp/file.txt", tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("openCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinuxRevealCommand(t *testing.T) {
+	want := []string{"gdbus", "call", "--session",
+		"--dest", "org.freedesktop.FileManager1",
+		"--object-path", "/org/freedesktop/FileManager1",
+		"--method", "org.freedesktop.FileManager1.ShowItems",
+		"['file:///home/user/it%27s%20a%20file.txt']", ""}
+
+	if got := revealCommand("/home/user/it's a file.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("revealCommand() = %q, want %q", got, want)
+	}
+}
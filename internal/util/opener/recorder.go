@@ -0,0 +1,49 @@
+package opener
+
+import "sync"
+
+// Call is a single request made to a Recorder
+type Call struct {
+	Target  string
+	Options Options
+}
+
+// Recorder is a fake backend that records what would have been opened
+// instead of opening it. Install it with SetBackend.
+type Recorder struct {
+	// Err is returned from every Open call
+	Err error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Open records the call
+func (r *Recorder) Open(target string, opts Options) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Target: target, Options: opts})
+	return r.Err
+}
+
+// FileManagerName returns a fixed name
+func (r *Recorder) FileManagerName() string {
+	return "File Manager"
+}
+
+// Calls returns a copy of the recorded calls, oldest first
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// Reset forgets the recorded calls
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
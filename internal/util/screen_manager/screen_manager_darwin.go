@@ -6,7 +6,7 @@ package screenmanager
 #cgo CFLAGS: -x objective-c
 #cgo LDFLAGS: -framework Cocoa
 
-// These are the C function signatures as defined in window_mover.m
+// These are the C function signatures as defined in window_mover_darwin.m
 // The C compiler needs to know what 'uintptr_t' is from <stdint.h>
 void MoveToMainScreen(uintptr_t nsWindowPtr);
 void MoveToScreenWithMouse(uintptr_t nsWindowPtr);
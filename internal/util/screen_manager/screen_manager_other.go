@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package screenmanager
+
+// GoMoveToMainScreen moves the given window to the main screen.
+// Windows are only moved between screens on macOS.
+func GoMoveToMainScreen(nsWindowPtr uintptr) {}
+
+// GoMoveToScreenWithMouse moves the given window to the screen containing the mouse cursor.
+// Windows are only moved between screens on macOS.
+func GoMoveToScreenWithMouse(nsWindowPtr uintptr) {}
@@ -1,4 +1,4 @@
-// window_mover.m
+// window_mover_darwin.m
 #import <Cocoa/Cocoa.h>
 #include <stdio.h> // For NSLog alternative if needed, but NSLog is fine.
 #include <stdint.h> // For uintptr_t